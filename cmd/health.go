@@ -6,20 +6,21 @@ import (
 
 	"github.com/naughtygopher/proberesponder"
 	"github.com/naughtygopher/proberesponder/extensions/depprober"
-	"go.mongodb.org/mongo-driver/mongo"
 
 	"github.com/prashantkr001/template-go/internal/pkg/kafka"
 )
 
 const (
-	dependencyIDKafka = "kafka"
-	dependencyIDMongo = "mongodb"
+	dependencyIDKafka    = "kafka"
+	dependencyIDMongo    = "mongodb"
+	dependencyIDPostgres = "postgres"
+	dependencyIDRedis    = "redis"
 )
 
 func healthStatus( //nolint:ireturn // returning interface because that's what's exposed by the package
 	delay time.Duration,
 	pstatus *proberesponder.ProbeResponder,
-	mongoCli *mongo.Client,
+	stores *storeClients,
 	kafkaCli *kafka.Kafka,
 ) depprober.Stopper {
 	/*
@@ -30,19 +31,45 @@ func healthStatus( //nolint:ireturn // returning interface because that's what's
 	*/
 	probes := []depprober.Prober{
 		&depprober.Probe{
-			ID:               dependencyIDMongo,
+			ID:               dependencyIDKafka,
 			AffectedStatuses: []proberesponder.Statuskey{proberesponder.StatusReady},
 			Checker: depprober.CheckerFunc(func(ctx context.Context) error {
-				return mongoCli.Ping(ctx, nil)
+				return kafkaCli.Ping(ctx)
 			}),
 		},
-		&depprober.Probe{
-			ID:               dependencyIDKafka,
+	}
+
+	// store clients are available only if they're the configured store
+	if stores.mongo != nil {
+		probes = append(probes, &depprober.Probe{
+			ID:               dependencyIDMongo,
 			AffectedStatuses: []proberesponder.Statuskey{proberesponder.StatusReady},
 			Checker: depprober.CheckerFunc(func(ctx context.Context) error {
-				return kafkaCli.Ping(ctx)
+				return stores.mongo.Ping(ctx, nil)
 			}),
-		},
+		})
+	}
+	if stores.postgres != nil {
+		probes = append(probes, &depprober.Probe{
+			ID:               dependencyIDPostgres,
+			AffectedStatuses: []proberesponder.Statuskey{proberesponder.StatusReady},
+			Checker: depprober.CheckerFunc(func(ctx context.Context) error {
+				return stores.postgres.PingContext(ctx)
+			}),
+		})
+	}
+
+	if stores.redis != nil {
+		// the app keeps serving from the store if the cache is unavailable, and the requests are served
+		// without idempotency (logged as a warning) if Redis keeps the idempotency keys. Hence no status
+		// is affected
+		probes = append(probes, &depprober.Probe{
+			ID:               dependencyIDRedis,
+			AffectedStatuses: []proberesponder.Statuskey{},
+			Checker: depprober.CheckerFunc(func(ctx context.Context) error {
+				return stores.redis.Ping(ctx).Err()
+			}),
+		})
 	}
 
 	return depprober.Start(delay, pstatus, probes...)
@@ -2,19 +2,27 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"slices"
 	"time"
 
 	mongoprom "github.com/globocom/mongo-go-prometheus"
+	_ "github.com/jackc/pgx/v5/stdlib" // registers the "pgx" database/sql driver
 	"github.com/naughtygopher/errors"
+	"github.com/redis/go-redis/v9"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
+	"github.com/prashantkr001/template-go/internal/api"
 	"github.com/prashantkr001/template-go/internal/config"
+	"github.com/prashantkr001/template-go/internal/item"
 	"github.com/prashantkr001/template-go/internal/pkg/apm"
+	"github.com/prashantkr001/template-go/internal/pkg/auth"
+	"github.com/prashantkr001/template-go/internal/pkg/idempotency"
 	"github.com/prashantkr001/template-go/internal/pkg/kafka"
 	"github.com/prashantkr001/template-go/internal/pkg/logger"
 )
@@ -33,13 +41,17 @@ func initLogger(cfg *config.Config) {
 	}
 
 	logger.SetContextFieldsSetter(func(ctx context.Context) []zap.Field {
-		fields := make([]zap.Field, 0, 1)
+		fields := make([]zap.Field, 0, len(ctxKeys)+3)
 		for _, key := range ctxKeys {
 			fields = append(
 				fields,
 				zap.Any(fmt.Sprintf("%v", key), ctx.Value(key)),
 			)
 		}
+		fields = append(fields, zap.String("tenant", item.Tenant(ctx)))
+		if principal := auth.PrincipalOf(ctx); principal != nil {
+			fields = append(fields, zap.String("principal", principal.Subject))
+		}
 
 		traceID := trace.SpanContextFromContext(ctx).TraceID()
 		if traceID.IsValid() {
@@ -108,6 +120,266 @@ func initializeMongoDB(ctx context.Context, cfg *config.Config) (*mongo.Client,
 	return mongoClient, mongoClient.Database(cfg.MongoDB.Database), nil
 }
 
+func initializePostgres(ctx context.Context, cfg *config.Config) (*sql.DB, error) {
+	db, err := sql.Open("pgx", cfg.Postgres.DSN)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to PostgreSQL")
+	}
+	db.SetMaxOpenConns(cfg.Postgres.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.Postgres.MaxIdleConns)
+	db.SetConnMaxIdleTime(cfg.Postgres.ConnMaxIdleTime)
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Postgres.PingTimeout)
+	defer cancel()
+
+	err = db.PingContext(ctx)
+	if err != nil {
+		_ = db.Close()
+		return nil, errors.Wrap(err, "failed to ping PostgreSQL")
+	}
+
+	return db, nil
+}
+
+// storeClients are the clients of the configured store, only one of them is non-nil,
+// or none if the store is in memory. redis is non-nil only if it's the configured cache.
+type storeClients struct {
+	mongo    *mongo.Client
+	postgres *sql.DB
+	redis    *redis.Client
+}
+
+// initItemStore initializes the store based on the configuration
+func initItemStore(ctx context.Context, cfg *config.Config) (*storeClients, item.Store, error) { //nolint:ireturn // the store is chosen by configuration
+	switch cfg.Store {
+	case config.StoreMemory:
+		istore, err := item.NewMemoryPersistentStore()
+		if err != nil {
+			return nil, nil, err
+		}
+		return &storeClients{}, istore, nil
+	case config.StoreMongoDB:
+		mongoClient, mongoDB, err := initializeMongoDB(ctx, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		istore, err := item.NewMongoPersistentStore(mongoDB)
+		if err != nil {
+			return nil, nil, err
+		}
+		err = istore.EnsureIndexes(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &storeClients{mongo: mongoClient}, istore, nil
+	case config.StorePostgres:
+		db, err := initializePostgres(ctx, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		istore, err := item.NewPostgresPersistentStore(db)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &storeClients{postgres: db}, istore, nil
+	}
+
+	return nil, nil, errors.Errorf("unsupported store %q", cfg.Store)
+}
+
+func initializeRedis(ctx context.Context, cfg *config.Config) (*redis.Client, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:       cfg.Redis.Addr,
+		Username:   cfg.Redis.Username,
+		Password:   cfg.Redis.Password,
+		DB:         cfg.Redis.DB,
+		ClientName: cfg.AppFullname(),
+	})
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Redis.PingTimeout)
+	defer cancel()
+
+	err := client.Ping(ctx).Err()
+	if err != nil {
+		_ = client.Close()
+		return nil, errors.Wrap(err, "failed to ping Redis")
+	}
+
+	return client, nil
+}
+
+// initItemCache initializes the cache based on the configuration, the cache is nil if disabled
+func initItemCache(ctx context.Context, cfg *config.Config) (*redis.Client, item.Cache, error) { //nolint:ireturn // the cache is chosen by configuration
+	if cfg.Cache.Type == config.CacheNone {
+		return nil, nil, nil
+	}
+	// deleted items are cached as tombstones, which should expire before the items are purged. Else
+	// an item re-created with the ID of a purged one would be hidden by the tombstone until it expires.
+	if cfg.Purger.Enabled && cfg.Cache.TTL >= cfg.Purger.Retention {
+		return nil, nil, errors.Validationf(
+			"cache TTL (%s) should be shorter than the purger retention (%s)",
+			cfg.Cache.TTL,
+			cfg.Purger.Retention,
+		)
+	}
+
+	switch cfg.Cache.Type {
+	case config.CacheMemory:
+		icache, err := item.NewLRUCache(cfg.Cache.Size, cfg.Cache.TTL)
+		if err != nil {
+			return nil, nil, err
+		}
+		logger.WarnCtx(
+			ctx,
+			fmt.Sprintf(
+				"item cache is in memory, it is not invalidated by other instances and may be stale for up to %s. Use Redis if there are multiple instances",
+				cfg.Cache.TTL,
+			),
+		)
+		return nil, icache, nil
+	case config.CacheRedis:
+		client, err := initializeRedis(ctx, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		icache, err := item.NewRedisCache(client, cfg.Redis.KeyPrefix, cfg.Cache.TTL)
+		if err != nil {
+			return nil, nil, err
+		}
+		return client, icache, nil
+	}
+
+	return nil, nil, errors.Errorf("unsupported cache %q", cfg.Cache.Type)
+}
+
+// itemIdempotency is the Mongo collection or the Postgres table of the idempotency records, the table is
+// created by the item schema migrations
+const itemIdempotency = "item_idempotency"
+
+// idempotencySweepInterval is the interval of removing the expired idempotency records from Postgres,
+// Redis & Mongo expire them by themselves
+const idempotencySweepInterval = time.Hour
+
+// initIdempotency keeps the idempotency records in Redis if it's configured, or in the store otherwise.
+// Since the records should be shared by all the instances of the app, they're kept in memory only if
+// the store is in memory as well.
+func initIdempotency(ctx context.Context, cfg *config.Config, stores *storeClients) (*idempotency.Idempotency, error) {
+	var store idempotency.Store
+	switch {
+	case stores.redis != nil:
+		store = idempotency.NewRedisStore(stores.redis, cfg.Redis.KeyPrefix+"idempotency:")
+	case stores.mongo != nil:
+		mstore := idempotency.NewMongoStore(stores.mongo.Database(cfg.MongoDB.Database).Collection(itemIdempotency))
+		err := mstore.EnsureIndexes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		store = mstore
+	case stores.postgres != nil:
+		pstore := idempotency.NewPostgresStore(stores.postgres, itemIdempotency)
+		go pstore.Sweep(ctx, idempotencySweepInterval)
+		store = pstore
+	default:
+		store = idempotency.NewMemoryStore()
+	}
+
+	return idempotency.New(store, &idempotency.Config{
+		TTL:     cfg.Idempotency.TTL,
+		LockTTL: cfg.Idempotency.LockTTL,
+	})
+}
+
+// initItemRules reads the validation rules of items from the configuration
+func initItemRules(cfg *config.Config) (item.Rules, error) {
+	rules := item.Rules{
+		NameMinLength: cfg.Item.NameMinLength,
+		NameMaxLength: cfg.Item.NameMaxLength,
+		NamePattern:   cfg.Item.NamePattern,
+		ReservedNames: cfg.Item.ReservedNames,
+	}
+	if cfg.Item.AttributeRules == "" {
+		return rules, nil
+	}
+
+	err := json.Unmarshal([]byte(cfg.Item.AttributeRules), &rules.Attributes)
+	if err != nil {
+		return rules, errors.Wrap(err, "invalid attribute rules")
+	}
+
+	return rules, nil
+}
+
+// initAuth returns the authenticator of the HTTP & gRPC requests, it is nil if authentication is disabled
+func initAuth(ctx context.Context, cfg *config.Config) (auth.Authenticator, error) { //nolint:ireturn // it is nil if disabled
+	if !cfg.Auth.Enabled {
+		return nil, nil //nolint:nilnil // nil authenticator means authentication is disabled
+	}
+
+	apiKeys := []auth.APIKey{}
+	if cfg.Auth.APIKeys != "" {
+		err := json.Unmarshal([]byte(cfg.Auth.APIKeys), &apiKeys)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid API keys")
+		}
+	}
+
+	authn, err := auth.New(ctx, &auth.Config{
+		JWKSURL:             cfg.Auth.JWKSURL,
+		JWKSFile:            cfg.Auth.JWKSFile,
+		JWKSRefreshInterval: cfg.Auth.JWKSRefreshInterval,
+		Issuer:              cfg.Auth.Issuer,
+		Audience:            cfg.Auth.Audience,
+		RolesClaim:          cfg.Auth.RolesClaim,
+		TenantsClaim:        cfg.Auth.TenantsClaim,
+		Leeway:              cfg.Auth.Leeway,
+		APIKeys:             apiKeys,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return authn, nil
+}
+
+// initAuthorizer returns the authorizer of the API calls, it is nil if there's no policy file
+func initAuthorizer(cfg *config.Config) (*api.Authorizer, error) {
+	if cfg.Auth.PolicyFile == "" {
+		return nil, nil //nolint:nilnil // nil authorizer means all the calls are allowed
+	}
+
+	policy, err := api.LoadPolicy(cfg.Auth.PolicyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return api.NewAuthorizer(policy, itemLimits(cfg))
+}
+
+// itemLimits are the limits of the tags, labels & attributes of items
+func itemLimits(cfg *config.Config) item.Limits {
+	return item.Limits{
+		MaxTags:        cfg.Item.MaxTags,
+		MaxLabels:      cfg.Item.MaxLabels,
+		MaxAttributes:  cfg.Item.MaxAttributes,
+		MaxValueLength: cfg.Item.MaxValueLength,
+		KeyPattern:     cfg.Item.KeyPattern,
+	}
+}
+
+// subscriberPrincipal returns the principal of the Kafka subscriber, it is nil if there's no subject
+func subscriberPrincipal(cfg *config.Config) *auth.Principal {
+	if cfg.Subscriber.Subject == "" {
+		return nil
+	}
+
+	return &auth.Principal{
+		Subject: cfg.Subscriber.Subject,
+		Method:  auth.MethodService,
+		Roles:   cfg.Subscriber.Roles,
+		Tenants: cfg.Subscriber.Tenants,
+	}
+}
+
 func initKafka(
 	ctx context.Context,
 	cfg *config.Config,
@@ -67,6 +67,11 @@ func main() {
 		probestatus = proberesponder.New()
 	)
 
+	if len(os.Args) > 1 && os.Args[1] == subcommandMigrate {
+		errExit = migrate(ctx)
+		return
+	}
+
 	healthResponder, err := startHealthResponder(ctx, probestatus, fatalErr)
 	if err != nil {
 		panic(err)
@@ -85,13 +90,13 @@ func main() {
 
 	initLogger(cfg)
 
-	mongoClient, kafkaClient, hserver, gserver, ksub := start(ctx, cfg, probestatus, fatalErr)
+	stores, kafkaClient, hserver, gserver, ksub, itemService, outboxRelay, purger := start(ctx, cfg, probestatus, fatalErr)
 
 	const probeInterval = time.Second * 30
 	var depProbeStopper = healthStatus(
 		probeInterval,
 		probestatus,
-		mongoClient,
+		stores,
 		kafkaClient,
 	)
 
@@ -133,7 +138,11 @@ func main() {
 			hserver,
 			gserver,
 			ksub,
-			mongoClient,
+			itemService,
+			outboxRelay,
+			purger,
+			kafkaClient,
+			stores,
 			apm.Global(),
 		)
 	}()
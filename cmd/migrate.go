@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/naughtygopher/errors"
+
+	"github.com/prashantkr001/template-go/internal/config"
+	"github.com/prashantkr001/template-go/internal/item"
+	"github.com/prashantkr001/template-go/internal/pkg/logger"
+)
+
+const subcommandMigrate = "migrate"
+
+// migrate applies the pending schema migrations of the configured store and exits, it is meant
+// to be run before deploying a new version of the app. e.g. `go run ./cmd migrate`
+func migrate(ctx context.Context) error {
+	cfg, err := config.Load("", "")
+	if err != nil {
+		return err
+	}
+	initLogger(cfg)
+
+	if cfg.Store != config.StorePostgres {
+		return errors.Errorf("store %q does not have schema migrations", cfg.Store)
+	}
+
+	db, err := initializePostgres(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	versions, err := item.MigratePostgres(ctx, db)
+	for _, version := range versions {
+		logger.InfoCtx(ctx, fmt.Sprintf("[migrate] applied item schema version %d", version))
+	}
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		logger.InfoCtx(ctx, "[migrate] item schema is up to date")
+	}
+
+	return nil
+}
@@ -5,10 +5,13 @@ package grpc
 import (
 	"fmt"
 	"net"
+	"strings"
 	"time"
 
 	"github.com/naughtygopher/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
@@ -16,7 +19,9 @@ import (
 
 	"github.com/prashantkr001/template-go/cmd/server/grpc/proto/v1/pbitems"
 	"github.com/prashantkr001/template-go/internal/api"
+	"github.com/prashantkr001/template-go/internal/item"
 	"github.com/prashantkr001/template-go/internal/pkg/apm"
+	"github.com/prashantkr001/template-go/internal/pkg/auth"
 )
 
 type Config struct {
@@ -24,13 +29,17 @@ type Config struct {
 	Port            int
 	ConnTimeout     time.Duration
 	EnableAccesslog bool
+	// MaxStreamedItems is the maximum number of items created in a single CreateItems call, since the
+	// results of all of them are kept in memory and sent as a single message. Default is DefaultMaxStreamedItems.
+	MaxStreamedItems int
 }
 type GRPC struct {
-	hostaddress string
-	grpcServer  *grpc.Server
-	port        int
-	apis        *api.API
-	startedAt   time.Time
+	hostaddress      string
+	grpcServer       *grpc.Server
+	port             int
+	apis             *api.API
+	startedAt        time.Time
+	maxStreamedItems int
 	pbitems.ItemsServiceServer
 }
 
@@ -67,9 +76,19 @@ func (grp *GRPC) Shutdown() {
 	grp.grpcServer.GracefulStop()
 }
 
-// New makes new grpc server.
-func New(apis *api.API, cfg *Config) *GRPC {
+// New makes new grpc server. Calls are authenticated using authn, unless it is nil.
+func New(apis *api.API, authn auth.Authenticator, cfg *Config) *GRPC {
 	const graceShutdownTime = time.Second * 5
+
+	unary := []grpc.UnaryServerInterceptor{MwErrWrapper, MwTenant}
+	stream := []grpc.StreamServerInterceptor{MwStreamErrWrapper, MwStreamTenant}
+	if authn != nil {
+		unary = append(unary, MwAuth(authn))
+		stream = append(stream, MwStreamAuth(authn))
+	}
+	unary = append(unary, MwActor, MwIdempotencyKey)
+	stream = append(stream, MwStreamActor)
+
 	opts := []grpc.ServerOption{
 		grpc.KeepaliveParams(keepalive.ServerParameters{
 			MaxConnectionIdle: time.Minute,
@@ -85,7 +104,8 @@ func New(apis *api.API, cfg *Config) *GRPC {
 		grpc.Creds(insecure.NewCredentials()),
 		grpc.ConnectionTimeout(cfg.ConnTimeout),
 		grpc.StatsHandler(apm.OtelGRPCNewServerHandler()),
-		grpc.ChainUnaryInterceptor(MwErrWrapper),
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
 	}
 
 	if cfg.EnableAccesslog {
@@ -95,10 +115,14 @@ func New(apis *api.API, cfg *Config) *GRPC {
 	grpcServer := grpc.NewServer(opts...)
 
 	grp := &GRPC{
-		hostaddress: fmt.Sprintf("%d", cfg.Port), //nolint:perfsprint // this is more readable and there's no performance penalty
-		grpcServer:  grpcServer,
-		apis:        apis,
-		port:        cfg.Port,
+		hostaddress:      fmt.Sprintf("%d", cfg.Port), //nolint:perfsprint // this is more readable and there's no performance penalty
+		grpcServer:       grpcServer,
+		apis:             apis,
+		port:             cfg.Port,
+		maxStreamedItems: cfg.MaxStreamedItems,
+	}
+	if grp.maxStreamedItems <= 0 {
+		grp.maxStreamedItems = DefaultMaxStreamedItems
 	}
 	pbitems.RegisterItemsServiceServer(grp.grpcServer, grp)
 
@@ -115,6 +139,66 @@ func New(apis *api.API, cfg *Config) *GRPC {
 }
 
 func responseError(err error) error {
-	code, message, _ := errors.GRPCStatusCodeMessage(err)
-	return status.Error(code, message) //nolint:wrapcheck // raw unwrapped error is expected
+	cerr := new(createdError)
+	created := errors.As(err, &cerr)
+	if created {
+		err = cerr.err
+	}
+
+	_, message, _ := errors.GRPCStatusCodeMessage(err)
+	stat := status.New(statusCode(err), message)
+
+	verr := new(item.ValidationError)
+	if errors.As(err, &verr) {
+		stat = withFieldViolations(stat, verr)
+	}
+	if created {
+		stat = withCreateResults(stat, cerr.results)
+	}
+
+	return stat.Err() //nolint:wrapcheck // raw unwrapped error is expected
+}
+
+// withCreateResults adds the results of the items created before a CreateItems call failed, as a
+// CreateItemsResponse in the details
+func withCreateResults(stat *status.Status, results []*pbitems.CreateItemResult) *status.Status {
+	detailed, err := stat.WithDetails(&pbitems.CreateItemsResponse{Results: results})
+	if err != nil {
+		// the status is still usable without the details
+		return stat
+	}
+	return detailed
+}
+
+// withFieldViolations adds all the field errors of the validation as google.rpc.BadRequest details
+func withFieldViolations(stat *status.Status, verr *item.ValidationError) *status.Status {
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(verr.Violations))
+	for _, violation := range verr.Violations {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       violation.Field,
+			Description: violation.Message,
+			Reason:      strings.ToUpper(violation.Code),
+		})
+	}
+
+	detailed, err := stat.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		// the status is still usable without the details
+		return stat
+	}
+	return detailed
+}
+
+// statusCode returns the gRPC status code for the error. It overrides the default code for
+// errors which need a more specific status than the one inferred from its error type.
+func statusCode(err error) codes.Code {
+	cerr := new(createdError)
+	if errors.As(err, &cerr) {
+		err = cerr.err
+	}
+	if errors.Is(err, item.ErrVersionConflict) {
+		return codes.Aborted
+	}
+	code, _ := errors.GRPCStatusCode(err)
+	return code
 }
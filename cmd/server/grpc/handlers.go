@@ -2,38 +2,345 @@ package grpc
 
 import (
 	"context"
+	"io"
+	"maps"
+
+	"github.com/naughtygopher/errors"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/prashantkr001/template-go/cmd/server/grpc/proto/v1/pbitems"
 	"github.com/prashantkr001/template-go/internal/item"
 )
 
 func (grp *GRPC) CreateItem(ctx context.Context, req *pbitems.CreateItemRequest) (*pbitems.Item, error) {
-	createdItem, err := grp.apis.ItemCreateIfNotExists(ctx, item.Item{
-		ID:   int(req.GetId()),
-		Name: req.GetName(),
-	})
+	createdItem, err := grp.apis.ItemCreateIfNotExists(ctx, itemFromCreateRequest(req))
 
 	if err != nil {
 		return nil, err
 	}
 
-	return &pbitems.Item{
-		Id:   int64(createdItem.ID),
-		Name: createdItem.Name,
-	}, nil
+	return pbItem(createdItem), nil
+}
+
+// DefaultMaxStreamedItems is the default maximum number of items which can be created in a single
+// CreateItems call
+const DefaultMaxStreamedItems = 5 * item.MaxCreateBatch
+
+var ErrTooManyStreamedItems = errors.InputBody("too many items are streamed")
+
+// createdError is the failure of a CreateItems call, after some of the streamed items were created
+type createdError struct {
+	err     error
+	results []*pbitems.CreateItemResult
+}
+
+func (ce *createdError) Error() string {
+	return ce.err.Error()
+}
+
+func (ce *createdError) Unwrap() error {
+	return ce.err
+}
+
+// CreateItems creates the streamed items in batches of item.MaxCreateBatch, at most Config.MaxStreamedItems
+// per stream. If it fails midway, the results of the batches already created are in the status details.
+func (grp *GRPC) CreateItems(stream pbitems.ItemsService_CreateItemsServer) error {
+	ctx := stream.Context()
+	results := make([]*pbitems.CreateItemResult, 0, item.MaxCreateBatch)
+	batch := make([]item.Item, 0, item.MaxCreateBatch)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		created, err := grp.apis.ItemCreateMany(ctx, batch)
+		if err != nil {
+			return err
+		}
+		for i := range created {
+			results = append(results, pbCreateResult(&created[i]))
+		}
+		batch = batch[:0]
+		return nil
+	}
+	failed := func(err error) error {
+		if len(results) == 0 {
+			return err
+		}
+		return &createdError{err: err, results: results}
+	}
+
+	for received := 1; ; received++ {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to receive item")
+		}
+		if received > grp.maxStreamedItems {
+			// the items received until the limit are created, so that the results are of all of them
+			err = flush()
+			if err != nil {
+				return failed(err)
+			}
+			return failed(errors.Wrapf(ErrTooManyStreamedItems, ", at most %d items can be created in a stream", grp.maxStreamedItems))
+		}
+
+		batch = append(batch, itemFromCreateRequest(req))
+		if len(batch) == item.MaxCreateBatch {
+			err = flush()
+			if err != nil {
+				return failed(err)
+			}
+		}
+	}
+
+	err := flush()
+	if err != nil {
+		return failed(err)
+	}
+
+	return stream.SendAndClose(&pbitems.CreateItemsResponse{Results: results}) //nolint:wrapcheck // raw error is expected by the gRPC server
 }
 
 func (grp *GRPC) ListItems(ctx context.Context, req *pbitems.ItemListRequest) (*pbitems.ItemListResponse, error) {
-	list, err := grp.apis.ItemList(ctx, int(req.GetLimit()))
+	sortFields := map[pbitems.SortBy]item.SortField{
+		pbitems.SortBy_SORT_BY_ID:         item.SortByID,
+		pbitems.SortBy_SORT_BY_NAME:       item.SortByName,
+		pbitems.SortBy_SORT_BY_CREATED_AT: item.SortByCreatedAt,
+	}
+
+	result, err := grp.apis.ItemList(ctx, item.ListQuery{
+		Limit:          int(req.GetLimit()),
+		Cursor:         req.GetPageToken(),
+		SortBy:         sortFields[req.GetSortBy()],
+		Descending:     req.GetDescending(),
+		NamePrefix:     req.GetNamePrefix(),
+		NameContains:   req.GetNameContains(),
+		IDFrom:         int(req.GetIdFrom()),
+		IDTo:           int(req.GetIdTo()),
+		IncludeDeleted: req.GetIncludeDeleted(),
+		Tags:           req.GetTags(),
+		Labels:         req.GetLabels(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ilist := make([]*pbitems.Item, 0, len(result.Items))
+	for i := range result.Items {
+		ilist = append(ilist, pbItem(&result.Items[i]))
+	}
+
+	return &pbitems.ItemListResponse{Items: ilist, NextPageToken: result.NextCursor}, nil
+}
+
+func (grp *GRPC) GetItem(ctx context.Context, req *pbitems.GetItemRequest) (*pbitems.Item, error) {
+	it, err := grp.apis.ItemGet(ctx, int(req.GetId()), req.GetIncludeDeleted())
+	if err != nil {
+		return nil, err
+	}
+
+	return pbItem(it), nil
+}
+
+func (grp *GRPC) UpdateItem(ctx context.Context, req *pbitems.UpdateItemRequest) (*pbitems.Item, error) {
+	updatedItem, err := grp.apis.ItemUpdate(ctx, item.Item{
+		ID:         int(req.GetId()),
+		Name:       req.GetName(),
+		Version:    req.GetVersion(),
+		Tags:       req.GetTags(),
+		Labels:     req.GetLabels(),
+		Attributes: itemAttributes(req.GetAttributes()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pbItem(updatedItem), nil
+}
+
+func (grp *GRPC) PatchItem(ctx context.Context, req *pbitems.PatchItemRequest) (*pbitems.Item, error) {
+	patch := item.Patch{Version: req.GetVersion()}
+	if req.Name != nil {
+		name := req.GetName()
+		patch.Name = &name
+	}
+	// the wrappers are set to patch the respective fields, even if they're empty
+	if req.Tags != nil {
+		patch.Tags = append([]string{}, req.GetTags().GetValues()...)
+	}
+	if req.Labels != nil {
+		patch.Labels = make(map[string]string, len(req.GetLabels().GetValues()))
+		maps.Copy(patch.Labels, req.GetLabels().GetValues())
+	}
+	if req.Attributes != nil {
+		patch.Attributes = itemAttributes(req.GetAttributes().GetValues())
+		if patch.Attributes == nil {
+			patch.Attributes = map[string]item.Attribute{}
+		}
+	}
+
+	patchedItem, err := grp.apis.ItemPatch(ctx, int(req.GetId()), patch)
 	if err != nil {
 		return nil, err
 	}
 
-	ilist := make([]*pbitems.Item, 0, len(list))
-	for i := range list {
-		pbi := pbitems.Item{Id: int64(list[i].ID), Name: list[i].Name}
-		ilist = append(ilist, &pbi)
+	return pbItem(patchedItem), nil
+}
+
+func (grp *GRPC) DeleteItem(ctx context.Context, req *pbitems.DeleteItemRequest) (*pbitems.DeleteItemResponse, error) {
+	err := grp.apis.ItemDelete(ctx, int(req.GetId()), req.GetVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pbitems.DeleteItemResponse{}, nil
+}
+
+func (grp *GRPC) RestoreItem(ctx context.Context, req *pbitems.RestoreItemRequest) (*pbitems.Item, error) {
+	restoredItem, err := grp.apis.ItemRestore(ctx, int(req.GetId()), req.GetVersion())
+	if err != nil {
+		return nil, err
+	}
+
+	return pbItem(restoredItem), nil
+}
+
+func (grp *GRPC) ListItemHistory(
+	ctx context.Context,
+	req *pbitems.ListItemHistoryRequest,
+) (*pbitems.ListItemHistoryResponse, error) {
+	result, err := grp.apis.ItemHistory(ctx, int(req.GetId()), item.HistoryQuery{
+		Limit:  int(req.GetLimit()),
+		Cursor: req.GetPageToken(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*pbitems.HistoryEntry, 0, len(result.Entries))
+	for i := range result.Entries {
+		entries = append(entries, pbHistoryEntry(&result.Entries[i]))
+	}
+
+	return &pbitems.ListItemHistoryResponse{Entries: entries, NextPageToken: result.NextCursor}, nil
+}
+
+func pbHistoryEntry(entry *item.HistoryEntry) *pbitems.HistoryEntry {
+	actions := map[item.HistoryAction]pbitems.HistoryAction{
+		item.HistoryCreated:  pbitems.HistoryAction_HISTORY_ACTION_CREATED,
+		item.HistoryUpdated:  pbitems.HistoryAction_HISTORY_ACTION_UPDATED,
+		item.HistoryDeleted:  pbitems.HistoryAction_HISTORY_ACTION_DELETED,
+		item.HistoryRestored: pbitems.HistoryAction_HISTORY_ACTION_RESTORED,
+	}
+
+	changes := make([]*pbitems.FieldChange, 0, len(entry.Changes))
+	for _, change := range entry.Changes {
+		changes = append(changes, &pbitems.FieldChange{Field: change.Field, From: change.From, To: change.To})
+	}
+
+	return &pbitems.HistoryEntry{
+		Id:      entry.ID,
+		ItemId:  int64(entry.ItemID),
+		Action:  actions[entry.Action],
+		Version: entry.Version,
+		Actor:   entry.Actor,
+		At:      timestamppb.New(entry.At),
+		Changes: changes,
+	}
+}
+
+func pbCreateResult(result *item.CreateResult) *pbitems.CreateItemResult {
+	statuses := map[item.CreateStatus]pbitems.CreateStatus{
+		item.CreateStatusCreated:   pbitems.CreateStatus_CREATE_STATUS_CREATED,
+		item.CreateStatusDuplicate: pbitems.CreateStatus_CREATE_STATUS_DUPLICATE,
+		item.CreateStatusInvalid:   pbitems.CreateStatus_CREATE_STATUS_INVALID,
+		item.CreateStatusFailed:    pbitems.CreateStatus_CREATE_STATUS_FAILED,
+	}
+
+	pbResult := &pbitems.CreateItemResult{
+		Id:     int64(result.ID),
+		Status: statuses[result.Status],
+		Error:  result.Error,
+	}
+	if result.Item != nil {
+		pbResult.Item = pbItem(result.Item)
+	}
+
+	return pbResult
+}
+
+func itemFromCreateRequest(req *pbitems.CreateItemRequest) item.Item {
+	return item.Item{
+		ID:         int(req.GetId()),
+		Name:       req.GetName(),
+		Tags:       req.GetTags(),
+		Labels:     req.GetLabels(),
+		Attributes: itemAttributes(req.GetAttributes()),
+	}
+}
+
+// itemAttributes converts the attributes, values without any kind set are converted to an attribute
+// without a type, which fails the validation.
+func itemAttributes(pbAttributes map[string]*pbitems.AttributeValue) map[string]item.Attribute {
+	if len(pbAttributes) == 0 {
+		return nil
+	}
+
+	attributes := make(map[string]item.Attribute, len(pbAttributes))
+	for key, value := range pbAttributes {
+		switch kind := value.GetKind().(type) {
+		case *pbitems.AttributeValue_StringValue:
+			attributes[key] = item.StringAttribute(kind.StringValue)
+		case *pbitems.AttributeValue_NumberValue:
+			attributes[key] = item.NumberAttribute(kind.NumberValue)
+		case *pbitems.AttributeValue_BoolValue:
+			attributes[key] = item.BoolAttribute(kind.BoolValue)
+		default:
+			attributes[key] = item.Attribute{}
+		}
+	}
+
+	return attributes
+}
+
+func pbAttributes(attributes map[string]item.Attribute) map[string]*pbitems.AttributeValue {
+	if len(attributes) == 0 {
+		return nil
 	}
 
-	return &pbitems.ItemListResponse{Items: ilist}, nil
+	pbAttrs := make(map[string]*pbitems.AttributeValue, len(attributes))
+	for key, attr := range attributes {
+		switch attr.Type {
+		case item.AttributeString:
+			pbAttrs[key] = &pbitems.AttributeValue{Kind: &pbitems.AttributeValue_StringValue{StringValue: attr.StringValue}}
+		case item.AttributeNumber:
+			pbAttrs[key] = &pbitems.AttributeValue{Kind: &pbitems.AttributeValue_NumberValue{NumberValue: attr.NumberValue}}
+		case item.AttributeBool:
+			pbAttrs[key] = &pbitems.AttributeValue{Kind: &pbitems.AttributeValue_BoolValue{BoolValue: attr.BoolValue}}
+		}
+	}
+
+	return pbAttrs
+}
+
+func pbItem(it *item.Item) *pbitems.Item {
+	pbIt := &pbitems.Item{
+		Id:         int64(it.ID),
+		Name:       it.Name,
+		Version:    it.Version,
+		CreatedAt:  timestamppb.New(it.CreatedAt),
+		UpdatedAt:  timestamppb.New(it.UpdatedAt),
+		DeletedBy:  it.DeletedBy,
+		Tags:       it.Tags,
+		Labels:     it.Labels,
+		Attributes: pbAttributes(it.Attributes),
+	}
+	if it.DeletedAt != nil {
+		pbIt.DeletedAt = timestamppb.New(*it.DeletedAt)
+	}
+	return pbIt
 }
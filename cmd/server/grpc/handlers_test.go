@@ -0,0 +1,84 @@
+package grpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/prashantkr001/template-go/cmd/server/grpc/proto/v1/pbitems"
+	"github.com/prashantkr001/template-go/internal/api"
+)
+
+// createdResults returns the results of the items created, from the details of a failed CreateItems call
+func createdResults(err error) []*pbitems.CreateItemResult {
+	for _, detail := range status.Convert(err).Details() {
+		if resp, ok := detail.(*pbitems.CreateItemsResponse); ok {
+			return resp.GetResults()
+		}
+	}
+	return nil
+}
+
+func TestCreateItems(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+
+	const maxStreamed = 3
+	client := pbitems.NewItemsServiceClient(newTestConnWithConfig(
+		t,
+		api.NewService(newTestService(t), nil, nil),
+		nil,
+		&Config{MaxStreamedItems: maxStreamed},
+	))
+
+	t.Run("the streamed items are created", func(_ *testing.T) {
+		stream, err := client.CreateItems(t.Context())
+		requirer.NoError(err)
+		for id := range int64(maxStreamed) {
+			requirer.NoError(stream.Send(&pbitems.CreateItemRequest{Id: id + 1, Name: "Box"}))
+		}
+		resp, err := stream.CloseAndRecv()
+		requirer.NoError(err)
+		requirer.Len(resp.GetResults(), maxStreamed)
+		asserter.Equal(int64(maxStreamed), resp.GetResults()[maxStreamed-1].GetId())
+		asserter.Equal(pbitems.CreateStatus_CREATE_STATUS_CREATED, resp.GetResults()[maxStreamed-1].GetStatus())
+	})
+
+	t.Run("the results of the items created are in the details, if too many are streamed", func(_ *testing.T) {
+		stream, err := client.CreateItems(t.Context())
+		requirer.NoError(err)
+		for id := range int64(maxStreamed + 2) {
+			serr := stream.Send(&pbitems.CreateItemRequest{Id: 10 + id, Name: "Jar"})
+			if serr != nil {
+				// the server stopped receiving
+				break
+			}
+		}
+		_, err = stream.CloseAndRecv()
+		requirer.Equal(codes.InvalidArgument, status.Code(err))
+		asserter.Contains(status.Convert(err).Message(), "at most 3 items can be created in a stream")
+
+		results := createdResults(err)
+		requirer.Len(results, maxStreamed)
+		asserter.Equal(int64(10), results[0].GetId())
+		asserter.Equal(pbitems.CreateStatus_CREATE_STATUS_CREATED, results[maxStreamed-1].GetStatus())
+
+		_, gerr := client.GetItem(t.Context(), &pbitems.GetItemRequest{Id: 10 + maxStreamed})
+		asserter.Equal(codes.NotFound, status.Code(gerr))
+	})
+
+	t.Run("the status of failures midway has the results", func(_ *testing.T) {
+		results := []*pbitems.CreateItemResult{{Id: 1, Status: pbitems.CreateStatus_CREATE_STATUS_CREATED}}
+		err := responseError(&createdError{err: api.ErrPermissionDenied, results: results})
+		asserter.Equal(codes.PermissionDenied, status.Code(err))
+		asserter.Equal("permission denied", status.Convert(err).Message())
+		requirer.Len(createdResults(err), 1)
+		asserter.Equal(int64(1), createdResults(err)[0].GetId())
+
+		// there are no details if nothing was created
+		asserter.Nil(createdResults(responseError(api.ErrPermissionDenied)))
+	})
+}
@@ -3,12 +3,16 @@ package grpc
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
-	"github.com/naughtygopher/errors"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 
+	"github.com/prashantkr001/template-go/internal/item"
+	"github.com/prashantkr001/template-go/internal/pkg/auth"
+	"github.com/prashantkr001/template-go/internal/pkg/idempotency"
 	"github.com/prashantkr001/template-go/internal/pkg/logger"
 )
 
@@ -24,7 +28,7 @@ func MwAccessLog( //nolint:nonamedreturns //nolint:nolintlint
 	msg = fmt.Sprintf("%s %s", msg, time.Since(start))
 	status := logger.Green("[grpc]::✔")
 	if err != nil {
-		code, _ := errors.GRPCStatusCode(err)
+		code := statusCode(err)
 		status = logger.Red(fmt.Sprintf("[grpc]::%s", code))
 	}
 
@@ -46,13 +50,196 @@ func MwErrWrapper( //nolint:nonamedreturns //nolint:nolintlint
 	return nil, responseErrWithLogs(ctx, err)
 }
 
+func MwStreamErrWrapper(
+	srv any,
+	stream grpc.ServerStream,
+	_ *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	err := handler(srv, stream)
+	if err == nil {
+		return nil
+	}
+
+	return responseErrWithLogs(stream.Context(), err)
+}
+
+// MetadataActor identifies the user or service making the call, it is recorded in the history of
+// the items changed by the call.
+const MetadataActor = "x-actor"
+
+// MwActor sets the actor of the call in its context, calls without an actor are recorded as anonymous.
+// The actor of authenticated calls is always their principal, i.e. the metadata is ignored.
+func MwActor(
+	ctx context.Context,
+	req any,
+	_ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	return handler(withActor(ctx), req)
+}
+
+func MwStreamActor(
+	srv any,
+	stream grpc.ServerStream,
+	_ *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	return handler(srv, &contextStream{ServerStream: stream, ctx: withActor(stream.Context())})
+}
+
+func withActor(ctx context.Context) context.Context {
+	actor := ""
+	if values := metadata.ValueFromIncomingContext(ctx, MetadataActor); len(values) > 0 {
+		actor = strings.TrimSpace(values[0])
+	}
+	if principal := auth.PrincipalOf(ctx); principal != nil {
+		actor = principal.Subject
+	}
+	if actor == "" {
+		actor = item.ActorAnonymous
+	}
+	return item.WithActor(ctx, actor)
+}
+
+// MetadataAuthorization has the bearer token of the call ("Bearer <token>"), and MetadataAPIKey the
+// API key. Only one of them is expected.
+const (
+	MetadataAuthorization = "authorization"
+	MetadataAPIKey        = "x-api-key"
+)
+
+// MwAuth authenticates the calls, and sets the principal in their context. The server reflection &
+// health checks are not authenticated.
+func MwAuth(authn auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req any,
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (any, error) {
+		if authExempted(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		ctx, err := authenticate(ctx, authn)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func MwStreamAuth(authn auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(
+		srv any,
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		if authExempted(info.FullMethod) {
+			return handler(srv, stream)
+		}
+
+		ctx, err := authenticate(stream.Context(), authn)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+func authExempted(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/grpc.reflection.") || strings.HasPrefix(fullMethod, "/grpc.health.")
+}
+
+func authenticate(ctx context.Context, authn auth.Authenticator) (context.Context, error) {
+	creds := auth.Credentials{}
+	if values := metadata.ValueFromIncomingContext(ctx, MetadataAuthorization); len(values) > 0 {
+		creds.BearerToken = auth.BearerToken(values[0])
+	}
+	if values := metadata.ValueFromIncomingContext(ctx, MetadataAPIKey); len(values) > 0 {
+		creds.APIKey = strings.TrimSpace(values[0])
+	}
+
+	principal, err := authn.Authenticate(ctx, creds)
+	if err != nil {
+		return ctx, err
+	}
+	return auth.WithPrincipal(ctx, principal), nil
+}
+
+// MetadataTenant is the tenant of the call, all the items accessed by the call are of the tenant. Calls
+// without a tenant are of item.DefaultTenant.
+const MetadataTenant = "x-tenant-id"
+
+// MwTenant sets the tenant of the call in its context, it is validated by the item service
+func MwTenant(
+	ctx context.Context,
+	req any,
+	_ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	return handler(withTenant(ctx), req)
+}
+
+func MwStreamTenant(
+	srv any,
+	stream grpc.ServerStream,
+	_ *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	return handler(srv, &contextStream{ServerStream: stream, ctx: withTenant(stream.Context())})
+}
+
+func withTenant(ctx context.Context) context.Context {
+	if values := metadata.ValueFromIncomingContext(ctx, MetadataTenant); len(values) > 0 {
+		if tenant := strings.TrimSpace(values[0]); tenant != "" {
+			return item.WithTenant(ctx, tenant)
+		}
+	}
+	return ctx
+}
+
+// MetadataIdempotencyKey makes the item creation idempotent, i.e. the retries with the same key get
+// the response of the original call.
+const MetadataIdempotencyKey = "idempotency-key"
+
+// MwIdempotencyKey sets the idempotency key of the call (if any) in its context
+func MwIdempotencyKey(
+	ctx context.Context,
+	req any,
+	_ *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (any, error) {
+	if values := metadata.ValueFromIncomingContext(ctx, MetadataIdempotencyKey); len(values) > 0 {
+		if key := strings.TrimSpace(values[0]); key != "" {
+			ctx = idempotency.WithKey(ctx, key)
+		}
+	}
+	return handler(ctx, req)
+}
+
+// contextStream overrides the context of the server stream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context //nolint:containedctx // the stream's context is replaced
+}
+
+func (cs *contextStream) Context() context.Context {
+	return cs.ctx
+}
+
 func responseErrWithLogs(ctx context.Context, err error) error {
-	code, _ := errors.GRPCStatusCode(err)
+	code := statusCode(err)
 	emsg := fmt.Sprintf("%+v", err)
 	switch code {
 	case codes.InvalidArgument,
 		codes.AlreadyExists,
-		codes.NotFound:
+		codes.Aborted,
+		codes.NotFound,
+		codes.Unauthenticated,
+		codes.PermissionDenied:
 		logger.WarnCtx(ctx, emsg)
 	default:
 		logger.ErrorCtx(ctx, emsg)
@@ -0,0 +1,256 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/prashantkr001/template-go/cmd/server/grpc/proto/v1/pbitems"
+	"github.com/prashantkr001/template-go/internal/api"
+	"github.com/prashantkr001/template-go/internal/item"
+	"github.com/prashantkr001/template-go/internal/pkg/auth"
+)
+
+// newTestConn serves the APIs over an in-memory connection, with the health service registered as well
+func newTestConn(t *testing.T, apis *api.API, authn auth.Authenticator) *grpc.ClientConn {
+	t.Helper()
+	return newTestConnWithConfig(t, apis, authn, &Config{})
+}
+
+func newTestConnWithConfig(t *testing.T, apis *api.API, authn auth.Authenticator, cfg *Config) *grpc.ClientConn {
+	t.Helper()
+
+	const bufSize = 1 << 20
+	lis := bufconn.Listen(bufSize)
+	grp := New(apis, authn, cfg)
+	grpc_health_v1.RegisterHealthServer(grp.Implementor(), health.NewServer())
+	go func() {
+		_ = grp.Implementor().Serve(lis)
+	}()
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+		grp.Implementor().Stop()
+	})
+
+	return conn
+}
+
+func newTestService(t *testing.T) *item.Service {
+	t.Helper()
+
+	store, err := item.NewMemoryPersistentStore()
+	require.NoError(t, err)
+	svc, err := item.NewService(store, nil, nil, nil, &item.Config{UseOutbox: true})
+	require.NoError(t, err)
+
+	return svc
+}
+
+func withMetadata(ctx context.Context, kv ...string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
+
+func TestMwAuth(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+
+	authn, err := auth.New(t.Context(), &auth.Config{APIKeys: []auth.APIKey{
+		{Key: "billing-key", Subject: "billing"},
+	}})
+	requirer.NoError(err)
+	conn := newTestConn(t, api.NewService(newTestService(t), nil, nil), authn)
+	client := pbitems.NewItemsServiceClient(conn)
+
+	t.Run("calls without valid credentials", func(_ *testing.T) {
+		for name, md := range map[string][]string{
+			"no credentials":  nil,
+			"invalid API key": {MetadataAPIKey, "other-key"},
+			"bearer token":    {MetadataAuthorization, "Bearer a.b.c"},
+		} {
+			ctx := withMetadata(t.Context(), md...)
+			_, cerr := client.GetItem(ctx, &pbitems.GetItemRequest{Id: 1})
+			asserter.Equal(codes.Unauthenticated, status.Code(cerr), name)
+
+			stream, cerr := client.CreateItems(ctx)
+			requirer.NoError(cerr, name)
+			_, cerr = stream.CloseAndRecv()
+			asserter.Equal(codes.Unauthenticated, status.Code(cerr), name)
+		}
+	})
+
+	t.Run("the actor is the principal", func(_ *testing.T) {
+		ctx := withMetadata(t.Context(), MetadataAPIKey, "billing-key", MetadataActor, "mallory")
+		_, cerr := client.CreateItem(ctx, &pbitems.CreateItemRequest{Id: 1, Name: "Box"})
+		requirer.NoError(cerr)
+
+		stream, cerr := client.CreateItems(ctx)
+		requirer.NoError(cerr)
+		requirer.NoError(stream.Send(&pbitems.CreateItemRequest{Id: 2, Name: "Jar"}))
+		resp, cerr := stream.CloseAndRecv()
+		requirer.NoError(cerr)
+		requirer.Len(resp.GetResults(), 1)
+
+		for _, id := range []int64{1, 2} {
+			history, herr := client.ListItemHistory(ctx, &pbitems.ListItemHistoryRequest{Id: id})
+			requirer.NoError(herr)
+			requirer.Len(history.GetEntries(), 1)
+			asserter.Equal("billing", history.GetEntries()[0].GetActor())
+		}
+	})
+
+	t.Run("reflection & health checks are not authenticated", func(_ *testing.T) {
+		hresp, cerr := grpc_health_v1.NewHealthClient(conn).Check(
+			t.Context(),
+			&grpc_health_v1.HealthCheckRequest{},
+		)
+		requirer.NoError(cerr)
+		asserter.Equal(grpc_health_v1.HealthCheckResponse_SERVING, hresp.GetStatus())
+
+		stream, cerr := grpc_reflection_v1.NewServerReflectionClient(conn).ServerReflectionInfo(t.Context())
+		requirer.NoError(cerr)
+		requirer.NoError(stream.Send(&grpc_reflection_v1.ServerReflectionRequest{
+			MessageRequest: &grpc_reflection_v1.ServerReflectionRequest_ListServices{},
+		}))
+		rresp, cerr := stream.Recv()
+		requirer.NoError(cerr)
+		services := []string{}
+		for _, svc := range rresp.GetListServicesResponse().GetService() {
+			services = append(services, svc.GetName())
+		}
+		asserter.Contains(services, pbitems.ItemsService_ServiceDesc.ServiceName)
+		requirer.NoError(stream.CloseSend())
+	})
+
+	t.Run("denied permissions", func(_ *testing.T) {
+		authz, aerr := api.NewAuthorizer(&api.Policy{
+			Roles: map[string]api.Grant{"viewer": {Permissions: []string{api.PermissionItemRead}}},
+		}, item.Limits{})
+		requirer.NoError(aerr)
+		viewerAuthn, aerr := auth.New(t.Context(), &auth.Config{APIKeys: []auth.APIKey{
+			{Key: "viewer-key", Subject: "jane", Roles: []string{"viewer"}},
+		}})
+		requirer.NoError(aerr)
+		viewerClient := pbitems.NewItemsServiceClient(
+			newTestConn(t, api.NewService(newTestService(t), nil, authz), viewerAuthn),
+		)
+
+		ctx := withMetadata(t.Context(), MetadataAPIKey, "viewer-key")
+		_, cerr := viewerClient.DeleteItem(ctx, &pbitems.DeleteItemRequest{Id: 1})
+		asserter.Equal(codes.PermissionDenied, status.Code(cerr))
+		asserter.Equal("permission 'items:delete' is required: permission denied", status.Convert(cerr).Message())
+
+		stream, cerr := viewerClient.CreateItems(ctx)
+		requirer.NoError(cerr)
+		requirer.NoError(stream.Send(&pbitems.CreateItemRequest{Id: 1, Name: "Box"}))
+		_, cerr = stream.CloseAndRecv()
+		asserter.Equal(codes.PermissionDenied, status.Code(cerr))
+	})
+}
+
+func TestMwTenant(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+
+	client := pbitems.NewItemsServiceClient(newTestConn(t, api.NewService(newTestService(t), nil, nil), nil))
+	acme := withMetadata(t.Context(), MetadataTenant, "acme")
+
+	created, err := client.CreateItem(acme, &pbitems.CreateItemRequest{Id: 1, Name: "Anvil"})
+	requirer.NoError(err)
+
+	t.Run("items are of the tenant of the call", func(_ *testing.T) {
+		got, gerr := client.GetItem(acme, &pbitems.GetItemRequest{Id: 1})
+		requirer.NoError(gerr)
+		asserter.Equal(created.GetName(), got.GetName())
+
+		_, gerr = client.GetItem(t.Context(), &pbitems.GetItemRequest{Id: 1})
+		asserter.Equal(codes.NotFound, status.Code(gerr))
+		_, gerr = client.GetItem(withMetadata(t.Context(), MetadataTenant, "globex"), &pbitems.GetItemRequest{Id: 1})
+		asserter.Equal(codes.NotFound, status.Code(gerr))
+	})
+
+	t.Run("streamed items are of the tenant of the call", func(_ *testing.T) {
+		stream, serr := client.CreateItems(acme)
+		requirer.NoError(serr)
+		requirer.NoError(stream.Send(&pbitems.CreateItemRequest{Id: 2, Name: "Rocket"}))
+		_, serr = stream.CloseAndRecv()
+		requirer.NoError(serr)
+
+		_, gerr := client.GetItem(acme, &pbitems.GetItemRequest{Id: 2})
+		asserter.NoError(gerr)
+		_, gerr = client.GetItem(t.Context(), &pbitems.GetItemRequest{Id: 2})
+		asserter.Equal(codes.NotFound, status.Code(gerr))
+	})
+
+	t.Run("invalid tenant", func(_ *testing.T) {
+		invalid := withMetadata(t.Context(), MetadataTenant, "Not A Tenant")
+		_, gerr := client.GetItem(invalid, &pbitems.GetItemRequest{Id: 1})
+		asserter.Equal(codes.InvalidArgument, status.Code(gerr))
+	})
+}
+
+func TestStatusCode(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+
+	client := pbitems.NewItemsServiceClient(newTestConn(t, api.NewService(newTestService(t), nil, nil), nil))
+	created, err := client.CreateItem(t.Context(), &pbitems.CreateItemRequest{Id: 1, Name: "Box"})
+	requirer.NoError(err)
+
+	t.Run("version conflicts are aborted", func(_ *testing.T) {
+		_, uerr := client.UpdateItem(t.Context(), &pbitems.UpdateItemRequest{
+			Id:      1,
+			Name:    "Jar",
+			Version: created.GetVersion() + 1,
+		})
+		asserter.Equal(codes.Aborted, status.Code(uerr))
+
+		_, uerr = client.DeleteItem(t.Context(), &pbitems.DeleteItemRequest{Id: 1, Version: created.GetVersion() + 1})
+		asserter.Equal(codes.Aborted, status.Code(uerr))
+	})
+
+	t.Run("duplicates already exist", func(_ *testing.T) {
+		_, cerr := client.CreateItem(t.Context(), &pbitems.CreateItemRequest{Id: 1, Name: "Box"})
+		asserter.Equal(codes.AlreadyExists, status.Code(cerr))
+	})
+
+	t.Run("validation errors have the field violations", func(_ *testing.T) {
+		// the name is too long only after the naming suffix is added to it
+		name := strings.Repeat("a", item.DefaultNameMaxLength)
+		_, cerr := client.CreateItem(t.Context(), &pbitems.CreateItemRequest{Id: 2, Name: name})
+		requirer.Equal(codes.InvalidArgument, status.Code(cerr))
+
+		violations := []*errdetails.BadRequest_FieldViolation{}
+		for _, detail := range status.Convert(cerr).Details() {
+			if badReq, ok := detail.(*errdetails.BadRequest); ok {
+				violations = append(violations, badReq.GetFieldViolations()...)
+			}
+		}
+		requirer.Len(violations, 1)
+		asserter.Equal("name", violations[0].GetField())
+		asserter.Equal(strings.ToUpper(item.CodeTooLong), violations[0].GetReason())
+		asserter.Contains(violations[0].GetDescription(), "characters are added to it")
+	})
+}
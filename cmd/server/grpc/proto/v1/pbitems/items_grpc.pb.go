@@ -23,7 +23,18 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ItemsServiceClient interface {
 	CreateItem(ctx context.Context, in *CreateItemRequest, opts ...grpc.CallOption) (*Item, error)
+	// CreateItems creates the streamed items in bulk, failure to create an item does not affect the others.
+	// At most 5000 items (by default) can be streamed per call. If it fails midway, the results of the items
+	// created are in a CreateItemsResponse of the status details.
+	CreateItems(ctx context.Context, opts ...grpc.CallOption) (ItemsService_CreateItemsClient, error)
 	ListItems(ctx context.Context, in *ItemListRequest, opts ...grpc.CallOption) (*ItemListResponse, error)
+	GetItem(ctx context.Context, in *GetItemRequest, opts ...grpc.CallOption) (*Item, error)
+	UpdateItem(ctx context.Context, in *UpdateItemRequest, opts ...grpc.CallOption) (*Item, error)
+	PatchItem(ctx context.Context, in *PatchItemRequest, opts ...grpc.CallOption) (*Item, error)
+	DeleteItem(ctx context.Context, in *DeleteItemRequest, opts ...grpc.CallOption) (*DeleteItemResponse, error)
+	RestoreItem(ctx context.Context, in *RestoreItemRequest, opts ...grpc.CallOption) (*Item, error)
+	// ListItemHistory is available even after the item is purged
+	ListItemHistory(ctx context.Context, in *ListItemHistoryRequest, opts ...grpc.CallOption) (*ListItemHistoryResponse, error)
 }
 
 type itemsServiceClient struct {
@@ -43,6 +54,40 @@ func (c *itemsServiceClient) CreateItem(ctx context.Context, in *CreateItemReque
 	return out, nil
 }
 
+func (c *itemsServiceClient) CreateItems(ctx context.Context, opts ...grpc.CallOption) (ItemsService_CreateItemsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ItemsService_ServiceDesc.Streams[0], "/items.v1.ItemsService/CreateItems", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &itemsServiceCreateItemsClient{stream}
+	return x, nil
+}
+
+type ItemsService_CreateItemsClient interface {
+	Send(*CreateItemRequest) error
+	CloseAndRecv() (*CreateItemsResponse, error)
+	grpc.ClientStream
+}
+
+type itemsServiceCreateItemsClient struct {
+	grpc.ClientStream
+}
+
+func (x *itemsServiceCreateItemsClient) Send(m *CreateItemRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *itemsServiceCreateItemsClient) CloseAndRecv() (*CreateItemsResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(CreateItemsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *itemsServiceClient) ListItems(ctx context.Context, in *ItemListRequest, opts ...grpc.CallOption) (*ItemListResponse, error) {
 	out := new(ItemListResponse)
 	err := c.cc.Invoke(ctx, "/items.v1.ItemsService/ListItems", in, out, opts...)
@@ -52,12 +97,77 @@ func (c *itemsServiceClient) ListItems(ctx context.Context, in *ItemListRequest,
 	return out, nil
 }
 
+func (c *itemsServiceClient) GetItem(ctx context.Context, in *GetItemRequest, opts ...grpc.CallOption) (*Item, error) {
+	out := new(Item)
+	err := c.cc.Invoke(ctx, "/items.v1.ItemsService/GetItem", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *itemsServiceClient) UpdateItem(ctx context.Context, in *UpdateItemRequest, opts ...grpc.CallOption) (*Item, error) {
+	out := new(Item)
+	err := c.cc.Invoke(ctx, "/items.v1.ItemsService/UpdateItem", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *itemsServiceClient) PatchItem(ctx context.Context, in *PatchItemRequest, opts ...grpc.CallOption) (*Item, error) {
+	out := new(Item)
+	err := c.cc.Invoke(ctx, "/items.v1.ItemsService/PatchItem", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *itemsServiceClient) DeleteItem(ctx context.Context, in *DeleteItemRequest, opts ...grpc.CallOption) (*DeleteItemResponse, error) {
+	out := new(DeleteItemResponse)
+	err := c.cc.Invoke(ctx, "/items.v1.ItemsService/DeleteItem", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *itemsServiceClient) RestoreItem(ctx context.Context, in *RestoreItemRequest, opts ...grpc.CallOption) (*Item, error) {
+	out := new(Item)
+	err := c.cc.Invoke(ctx, "/items.v1.ItemsService/RestoreItem", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *itemsServiceClient) ListItemHistory(ctx context.Context, in *ListItemHistoryRequest, opts ...grpc.CallOption) (*ListItemHistoryResponse, error) {
+	out := new(ListItemHistoryResponse)
+	err := c.cc.Invoke(ctx, "/items.v1.ItemsService/ListItemHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ItemsServiceServer is the server API for ItemsService service.
 // All implementations must embed UnimplementedItemsServiceServer
 // for forward compatibility
 type ItemsServiceServer interface {
 	CreateItem(context.Context, *CreateItemRequest) (*Item, error)
+	// CreateItems creates the streamed items in bulk, failure to create an item does not affect the others.
+	// At most 5000 items (by default) can be streamed per call. If it fails midway, the results of the items
+	// created are in a CreateItemsResponse of the status details.
+	CreateItems(ItemsService_CreateItemsServer) error
 	ListItems(context.Context, *ItemListRequest) (*ItemListResponse, error)
+	GetItem(context.Context, *GetItemRequest) (*Item, error)
+	UpdateItem(context.Context, *UpdateItemRequest) (*Item, error)
+	PatchItem(context.Context, *PatchItemRequest) (*Item, error)
+	DeleteItem(context.Context, *DeleteItemRequest) (*DeleteItemResponse, error)
+	RestoreItem(context.Context, *RestoreItemRequest) (*Item, error)
+	// ListItemHistory is available even after the item is purged
+	ListItemHistory(context.Context, *ListItemHistoryRequest) (*ListItemHistoryResponse, error)
 	mustEmbedUnimplementedItemsServiceServer()
 }
 
@@ -68,9 +178,30 @@ type UnimplementedItemsServiceServer struct {
 func (UnimplementedItemsServiceServer) CreateItem(context.Context, *CreateItemRequest) (*Item, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateItem not implemented")
 }
+func (UnimplementedItemsServiceServer) CreateItems(ItemsService_CreateItemsServer) error {
+	return status.Errorf(codes.Unimplemented, "method CreateItems not implemented")
+}
 func (UnimplementedItemsServiceServer) ListItems(context.Context, *ItemListRequest) (*ItemListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListItems not implemented")
 }
+func (UnimplementedItemsServiceServer) GetItem(context.Context, *GetItemRequest) (*Item, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetItem not implemented")
+}
+func (UnimplementedItemsServiceServer) UpdateItem(context.Context, *UpdateItemRequest) (*Item, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateItem not implemented")
+}
+func (UnimplementedItemsServiceServer) PatchItem(context.Context, *PatchItemRequest) (*Item, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PatchItem not implemented")
+}
+func (UnimplementedItemsServiceServer) DeleteItem(context.Context, *DeleteItemRequest) (*DeleteItemResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteItem not implemented")
+}
+func (UnimplementedItemsServiceServer) RestoreItem(context.Context, *RestoreItemRequest) (*Item, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreItem not implemented")
+}
+func (UnimplementedItemsServiceServer) ListItemHistory(context.Context, *ListItemHistoryRequest) (*ListItemHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListItemHistory not implemented")
+}
 func (UnimplementedItemsServiceServer) mustEmbedUnimplementedItemsServiceServer() {}
 
 // UnsafeItemsServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -102,6 +233,32 @@ func _ItemsService_CreateItem_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ItemsService_CreateItems_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ItemsServiceServer).CreateItems(&itemsServiceCreateItemsServer{stream})
+}
+
+type ItemsService_CreateItemsServer interface {
+	SendAndClose(*CreateItemsResponse) error
+	Recv() (*CreateItemRequest, error)
+	grpc.ServerStream
+}
+
+type itemsServiceCreateItemsServer struct {
+	grpc.ServerStream
+}
+
+func (x *itemsServiceCreateItemsServer) SendAndClose(m *CreateItemsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *itemsServiceCreateItemsServer) Recv() (*CreateItemRequest, error) {
+	m := new(CreateItemRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _ItemsService_ListItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ItemListRequest)
 	if err := dec(in); err != nil {
@@ -120,6 +277,114 @@ func _ItemsService_ListItems_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ItemsService_GetItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ItemsServiceServer).GetItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/items.v1.ItemsService/GetItem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ItemsServiceServer).GetItem(ctx, req.(*GetItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ItemsService_UpdateItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ItemsServiceServer).UpdateItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/items.v1.ItemsService/UpdateItem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ItemsServiceServer).UpdateItem(ctx, req.(*UpdateItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ItemsService_PatchItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PatchItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ItemsServiceServer).PatchItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/items.v1.ItemsService/PatchItem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ItemsServiceServer).PatchItem(ctx, req.(*PatchItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ItemsService_DeleteItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ItemsServiceServer).DeleteItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/items.v1.ItemsService/DeleteItem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ItemsServiceServer).DeleteItem(ctx, req.(*DeleteItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ItemsService_RestoreItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ItemsServiceServer).RestoreItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/items.v1.ItemsService/RestoreItem",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ItemsServiceServer).RestoreItem(ctx, req.(*RestoreItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ItemsService_ListItemHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListItemHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ItemsServiceServer).ListItemHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/items.v1.ItemsService/ListItemHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ItemsServiceServer).ListItemHistory(ctx, req.(*ListItemHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ItemsService_ServiceDesc is the grpc.ServiceDesc for ItemsService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -135,7 +400,37 @@ var ItemsService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListItems",
 			Handler:    _ItemsService_ListItems_Handler,
 		},
+		{
+			MethodName: "GetItem",
+			Handler:    _ItemsService_GetItem_Handler,
+		},
+		{
+			MethodName: "UpdateItem",
+			Handler:    _ItemsService_UpdateItem_Handler,
+		},
+		{
+			MethodName: "PatchItem",
+			Handler:    _ItemsService_PatchItem_Handler,
+		},
+		{
+			MethodName: "DeleteItem",
+			Handler:    _ItemsService_DeleteItem_Handler,
+		},
+		{
+			MethodName: "RestoreItem",
+			Handler:    _ItemsService_RestoreItem_Handler,
+		},
+		{
+			MethodName: "ListItemHistory",
+			Handler:    _ItemsService_ListItemHistory_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "CreateItems",
+			Handler:       _ItemsService_CreateItems_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "items.proto",
 }
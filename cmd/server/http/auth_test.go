@@ -0,0 +1,103 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prashantkr001/template-go/internal/api"
+	"github.com/prashantkr001/template-go/internal/item"
+	"github.com/prashantkr001/template-go/internal/pkg/auth"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+
+	store, err := item.NewMemoryPersistentStore()
+	requirer.NoError(err)
+	svc, err := item.NewService(store, nil, nil, nil, &item.Config{UseOutbox: true})
+	requirer.NoError(err)
+	authn, err := auth.New(t.Context(), &auth.Config{APIKeys: []auth.APIKey{
+		{Key: "billing-key", Subject: "billing"},
+	}})
+	requirer.NoError(err)
+	ht, err := New(api.NewService(svc, nil, nil), authn, &Config{})
+	requirer.NoError(err)
+
+	serve := func(method, target, body string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		rec := httptest.NewRecorder()
+		ht.server.Handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("requests without valid credentials", func(_ *testing.T) {
+		for name, headers := range map[string]map[string]string{
+			"no credentials":  nil,
+			"invalid API key": {HeaderAPIKey: "other-key"},
+			"basic auth":      {"Authorization": "Basic YmlsbGluZzprZXk="},
+			"bearer token":    {"Authorization": "Bearer a.b.c"},
+		} {
+			rec := serve(http.MethodGet, "/items/1", "", headers)
+			asserter.Equal(http.StatusUnauthorized, rec.Code, name)
+			asserter.Equal("Bearer", rec.Header().Get("WWW-Authenticate"), name)
+
+			prob := problem{}
+			requirer.NoError(json.Unmarshal(rec.Body.Bytes(), &prob), name)
+			asserter.Equal(CodeUnauthenticated, prob.Code, name)
+		}
+	})
+
+	t.Run("the actor is the principal", func(_ *testing.T) {
+		rec := serve(http.MethodPost, "/items", `{"id":1,"name":"Box"}`, map[string]string{
+			HeaderAPIKey: "billing-key",
+			HeaderActor:  "mallory",
+		})
+		requirer.Equal(http.StatusOK, rec.Code, rec.Body.String())
+
+		rec = serve(http.MethodGet, "/items/1/history", "", map[string]string{HeaderAPIKey: "billing-key"})
+		requirer.Equal(http.StatusOK, rec.Code, rec.Body.String())
+		history := []item.HistoryEntry{}
+		requirer.NoError(json.Unmarshal(rec.Body.Bytes(), &history))
+		requirer.Len(history, 1)
+		asserter.Equal("billing", history[0].Actor)
+	})
+
+	t.Run("denied permissions", func(_ *testing.T) {
+		authz, aerr := api.NewAuthorizer(&api.Policy{
+			Roles: map[string]api.Grant{"viewer": {Permissions: []string{api.PermissionItemRead}}},
+		}, item.Limits{})
+		requirer.NoError(aerr)
+		viewerAuthn, aerr := auth.New(t.Context(), &auth.Config{APIKeys: []auth.APIKey{
+			{Key: "viewer-key", Subject: "jane", Roles: []string{"viewer"}},
+		}})
+		requirer.NoError(aerr)
+		viewerHT, aerr := New(api.NewService(svc, nil, authz), viewerAuthn, &Config{})
+		requirer.NoError(aerr)
+
+		req := httptest.NewRequest(http.MethodDelete, "/items/1", nil)
+		req.Header.Set(HeaderAPIKey, "viewer-key")
+		rec := httptest.NewRecorder()
+		viewerHT.server.Handler.ServeHTTP(rec, req)
+		asserter.Equal(http.StatusForbidden, rec.Code)
+
+		prob := problem{}
+		requirer.NoError(json.Unmarshal(rec.Body.Bytes(), &prob))
+		asserter.Equal(CodeUnauthorized, prob.Code)
+		asserter.Equal("permission 'items:delete' is required: permission denied", prob.Detail)
+	})
+
+	t.Run("docs are not authenticated", func(_ *testing.T) {
+		asserter.Equal(http.StatusOK, serve(http.MethodGet, PathOpenAPI, "", nil).Code)
+		asserter.Equal(http.StatusOK, serve(http.MethodGet, PathDocs, "", nil).Code)
+	})
+}
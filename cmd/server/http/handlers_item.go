@@ -2,8 +2,11 @@ package http
 
 import (
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/naughtygopher/errors"
@@ -12,15 +15,27 @@ import (
 )
 
 func (ht *HTTP) itemRoutes(router chi.Router) {
-	router.Post("/items", ht.ErrorHandler(ht.CreateItem))
-	router.Get("/items", ht.ErrorHandler(ht.ListItems))
+	router.Group(func(router chi.Router) {
+		// the requests are validated after routing, against the OpenAPI operation of the route
+		router.Use(ht.validationMiddleware)
+
+		router.Post("/items", ht.ErrorHandler(ht.CreateItem))
+		router.Post("/items:batch", ht.ErrorHandler(ht.CreateItems))
+		router.Get("/items", ht.ErrorHandler(ht.ListItems))
+		router.Get("/items/{id}", ht.ErrorHandler(ht.GetItem))
+		router.Put("/items/{id}", ht.ErrorHandler(ht.UpdateItem))
+		router.Patch("/items/{id}", ht.ErrorHandler(ht.PatchItem))
+		router.Delete("/items/{id}", ht.ErrorHandler(ht.DeleteItem))
+		router.Post("/items/{id}:restore", ht.ErrorHandler(ht.RestoreItem))
+		router.Get("/items/{id}/history", ht.ErrorHandler(ht.ListItemHistory))
+	})
 }
 
 func (ht *HTTP) CreateItem(w http.ResponseWriter, req *http.Request) error {
 	payload := item.Item{}
-	err := json.NewDecoder(req.Body).Decode(&payload)
+	err := decodeJSON(req, &payload)
 	if err != nil {
-		return errors.Wrap(err, "failed to decode request body")
+		return err
 	}
 
 	createdItem, err := ht.apis.ItemCreateIfNotExists(req.Context(), payload)
@@ -28,39 +43,355 @@ func (ht *HTTP) CreateItem(w http.ResponseWriter, req *http.Request) error {
 		return err
 	}
 
-	jResp, err := json.Marshal(createdItem)
+	setETag(w, createdItem.Version)
+	return writeJSON(w, http.StatusOK, createdItem)
+}
+
+// CreateItems accepts a JSON array of items, and responds with the result of every item in the same
+// order. The response status is 200 even if some of the items were not created.
+func (ht *HTTP) CreateItems(w http.ResponseWriter, req *http.Request) error {
+	payload := []item.Item{}
+	err := decodeJSON(req, &payload)
 	if err != nil {
-		return errors.Wrap(err, "failed to marshal response")
+		return err
 	}
 
-	_, err = w.Write(jResp)
+	results, err := ht.apis.ItemCreateMany(req.Context(), payload)
 	if err != nil {
-		return errors.Wrap(err, "failed to write response")
+		return err
 	}
 
-	return nil
+	return writeJSON(w, http.StatusOK, results)
 }
 
+// ListItems responds with a page of items. If there are more items, the URL to the next page
+// is set in the "Link" header with rel="next", and its cursor is available as the "cursor" query param.
 func (ht *HTTP) ListItems(w http.ResponseWriter, req *http.Request) error {
-	str := req.URL.Query().Get("limit")
-	limit, err := strconv.ParseInt(str, 10, 32)
+	query, err := listQueryFromRequest(req)
+	if err != nil {
+		return err
+	}
+
+	output, err := ht.apis.ItemList(req.Context(), query)
+	if err != nil {
+		return err
+	}
+
+	setNextLink(w, req, output.NextCursor)
+	return writeJSON(w, http.StatusOK, output.Items)
+}
+
+// GetItem responds with the item, deleted items are included only if the "include_deleted" query param is true
+func (ht *HTTP) GetItem(w http.ResponseWriter, req *http.Request) error {
+	id, err := itemIDFromPath(req)
+	if err != nil {
+		return err
+	}
+
+	includeDeleted, err := boolParam(req, "include_deleted")
+	if err != nil {
+		return err
+	}
+
+	it, err := ht.apis.ItemGet(req.Context(), id, includeDeleted)
+	if err != nil {
+		return err
+	}
+
+	setETag(w, it.Version)
+	if etagMatches(req.Header.Get("If-None-Match"), it.Version) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	return writeJSON(w, http.StatusOK, it)
+}
+
+func (ht *HTTP) UpdateItem(w http.ResponseWriter, req *http.Request) error {
+	id, err := itemIDFromPath(req)
+	if err != nil {
+		return err
+	}
+
+	payload := item.Item{}
+	err = decodeJSON(req, &payload)
+	if err != nil {
+		return err
+	}
+	// ID in the path is the source of truth, the one in the body (if any) is ignored
+	payload.ID = id
+	err = applyIfMatch(req, &payload.Version)
+	if err != nil {
+		return err
+	}
+
+	updatedItem, err := ht.apis.ItemUpdate(req.Context(), payload)
+	if err != nil {
+		return err
+	}
+
+	setETag(w, updatedItem.Version)
+	return writeJSON(w, http.StatusOK, updatedItem)
+}
+
+func (ht *HTTP) PatchItem(w http.ResponseWriter, req *http.Request) error {
+	id, err := itemIDFromPath(req)
+	if err != nil {
+		return err
+	}
+
+	patch := item.Patch{}
+	err = decodeJSON(req, &patch)
+	if err != nil {
+		return err
+	}
+	err = applyIfMatch(req, &patch.Version)
+	if err != nil {
+		return err
+	}
+
+	patchedItem, err := ht.apis.ItemPatch(req.Context(), id, patch)
+	if err != nil {
+		return err
+	}
+
+	setETag(w, patchedItem.Version)
+	return writeJSON(w, http.StatusOK, patchedItem)
+}
+
+func (ht *HTTP) DeleteItem(w http.ResponseWriter, req *http.Request) error {
+	id, err := itemIDFromPath(req)
+	if err != nil {
+		return err
+	}
+
+	var version int64
+	err = applyIfMatch(req, &version)
+	if err != nil {
+		return err
+	}
+
+	err = ht.apis.ItemDelete(req.Context(), id, version)
+	if err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// RestoreItem undoes the deletion of an item, which is not purged yet
+func (ht *HTTP) RestoreItem(w http.ResponseWriter, req *http.Request) error {
+	id, err := itemIDFromPath(req)
+	if err != nil {
+		return err
+	}
+
+	var version int64
+	err = applyIfMatch(req, &version)
+	if err != nil {
+		return err
+	}
+
+	restoredItem, err := ht.apis.ItemRestore(req.Context(), id, version)
 	if err != nil {
-		return errors.InputBodyf("invalid limit provided: %s", str)
+		return err
 	}
 
-	output, err := ht.apis.ItemList(req.Context(), int(limit))
+	setETag(w, restoredItem.Version)
+	return writeJSON(w, http.StatusOK, restoredItem)
+}
+
+// ListItemHistory responds with a page of the changes of an item, newest first. Pagination is
+// the same as ListItems, using the "limit" & "cursor" query params.
+func (ht *HTTP) ListItemHistory(w http.ResponseWriter, req *http.Request) error {
+	id, err := itemIDFromPath(req)
 	if err != nil {
 		return err
 	}
 
-	jResp, err := json.Marshal(output)
+	query := item.HistoryQuery{Cursor: req.URL.Query().Get("cursor")}
+	if str := req.URL.Query().Get("limit"); str != "" {
+		limit, perr := strconv.ParseInt(str, 10, 32)
+		if perr != nil {
+			return errors.InputBodyf("limit should be an integer, got '%s'", str)
+		}
+		query.Limit = int(limit)
+	}
+
+	output, err := ht.apis.ItemHistory(req.Context(), id, query)
+	if err != nil {
+		return err
+	}
+
+	setNextLink(w, req, output.NextCursor)
+	return writeJSON(w, http.StatusOK, output.Entries)
+}
+
+// setNextLink sets the URL of the next page in the "Link" header, if there's a next page
+func setNextLink(w http.ResponseWriter, req *http.Request, cursor string) {
+	if cursor == "" {
+		return
+	}
+
+	next := *req.URL
+	params := next.Query()
+	params.Set("cursor", cursor)
+	next.RawQuery = params.Encode()
+	w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", next.RequestURI()))
+}
+
+func listQueryFromRequest(req *http.Request) (item.ListQuery, error) {
+	params := req.URL.Query()
+	query := item.ListQuery{
+		Cursor:       params.Get("cursor"),
+		NamePrefix:   params.Get("name_prefix"),
+		NameContains: params.Get("name_contains"),
+	}
+
+	intParams := map[string]*int{
+		"limit":   &query.Limit,
+		"id_from": &query.IDFrom,
+		"id_to":   &query.IDTo,
+	}
+	for key, value := range intParams {
+		str := params.Get(key)
+		if str == "" {
+			continue
+		}
+		parsed, err := strconv.ParseInt(str, 10, 32)
+		if err != nil {
+			return query, errors.InputBodyf("%s should be an integer, got '%s'", key, str)
+		}
+		*value = int(parsed)
+	}
+
+	// sort is the field name, optionally prefixed with '-' for descending order. e.g. sort=-createdAt
+	sortBy := params.Get("sort")
+	if strings.HasPrefix(sortBy, "-") {
+		query.Descending = true
+		sortBy = sortBy[1:]
+	}
+	query.SortBy = item.SortField(sortBy)
+
+	includeDeleted, err := boolParam(req, "include_deleted")
+	if err != nil {
+		return query, err
+	}
+	query.IncludeDeleted = includeDeleted
+
+	// tags & labels can be repeated, e.g. tag=fragile&tag=bulk&label=color:red
+	query.Tags = params["tag"]
+	for _, label := range params["label"] {
+		key, value, ok := strings.Cut(label, ":")
+		if !ok {
+			return query, errors.InputBodyf("invalid label provided, should be key:value: %s", label)
+		}
+		if query.Labels == nil {
+			query.Labels = make(map[string]string, len(params["label"]))
+		}
+		query.Labels[key] = value
+	}
+
+	return query, nil
+}
+
+func boolParam(req *http.Request, key string) (bool, error) {
+	str := req.URL.Query().Get(key)
+	if str == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(str)
+	if err != nil {
+		return false, errors.InputBodyf("invalid %s provided: %s", key, str)
+	}
+	return value, nil
+}
+
+func itemIDFromPath(req *http.Request) (int, error) {
+	str := chi.URLParam(req, "id")
+	id, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, errors.InputBodyf("invalid item ID provided: %s", str)
+	}
+	return id, nil
+}
+
+func setETag(w http.ResponseWriter, version int64) {
+	w.Header().Set("ETag", strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// etagMatches checks if any of the comma separated entity tags, in an If-None-Match header value,
+// matches the version. Weak comparison is used, i.e. the "W/" prefix is ignored.
+func etagMatches(header string, version int64) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		tag = strings.Trim(strings.TrimPrefix(tag, "W/"), `"`)
+		if tag != "" && tag == strconv.FormatInt(version, 10) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyIfMatch sets the expected version based on the If-Match header. The header takes precedence
+// over the version (if any) provided in the request body. "*" is treated as any version. Weak ETags
+// are rejected, since If-Match requires strong comparison (RFC 9110 §13.1.1).
+func applyIfMatch(req *http.Request, version *int64) error {
+	header := strings.TrimSpace(req.Header.Get("If-Match"))
+	if header == "" {
+		return nil
+	}
+	if header == "*" {
+		*version = 0
+		return nil
+	}
+	if strings.HasPrefix(header, "W/") {
+		return errors.InputBodyf("weak ETags can not be used with If-Match: %s", header)
+	}
+
+	tag := strings.Trim(header, `"`)
+	ver, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || ver <= 0 {
+		return errors.InputBodyf("invalid If-Match provided, only a single item ETag is supported: %s", header)
+	}
+	*version = ver
+	return nil
+}
+
+// decodeJSON decodes the request body into payload. Unknown fields & any data after the JSON value
+// are rejected.
+func decodeJSON(req *http.Request, payload any) error {
+	dec := json.NewDecoder(req.Body)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(payload)
+	if err != nil {
+		return errors.InputBodyErr(err, "failed to decode request body")
+	}
+
+	_, err = dec.Token()
+	if !errors.Is(err, io.EOF) {
+		return ErrInvalidJSON
+	}
+
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, payload any) error {
+	jResp, err := json.Marshal(payload)
 	if err != nil {
 		return errors.Wrap(err, "failed to marshal response")
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
 	_, err = w.Write(jResp)
 	if err != nil {
 		return errors.Wrap(err, "failed to write response")
 	}
+
 	return nil
 }
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prashantkr001/template-go/internal/api"
+	"github.com/prashantkr001/template-go/internal/item"
+)
+
+func TestItemPreconditions(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+
+	store, err := item.NewMemoryPersistentStore()
+	requirer.NoError(err)
+	svc, err := item.NewService(store, nil, nil, nil, &item.Config{UseOutbox: true})
+	requirer.NoError(err)
+	ht, err := New(api.NewService(svc, nil, nil), nil, &Config{})
+	requirer.NoError(err)
+
+	serve := func(method, target, body string, headers map[string]string) (*httptest.ResponseRecorder, problem) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		rec := httptest.NewRecorder()
+		ht.server.Handler.ServeHTTP(rec, req)
+
+		prob := problem{}
+		if rec.Header().Get("Content-Type") == contentTypeProblem {
+			requirer.NoError(json.Unmarshal(rec.Body.Bytes(), &prob))
+		}
+		return rec, prob
+	}
+
+	rec, _ := serve(http.MethodPost, "/items", `{"id":1,"name":"Box"}`, nil)
+	requirer.Equal(http.StatusOK, rec.Code, rec.Body.String())
+	asserter.Equal(`"1"`, rec.Header().Get("ETag"))
+
+	t.Run("items matching If-None-Match are not modified", func(_ *testing.T) {
+		for _, tag := range []string{`"1"`, `W/"1"`, `"7", "1"`, "*"} {
+			rec, _ := serve(http.MethodGet, "/items/1", "", map[string]string{"If-None-Match": tag})
+			asserter.Equal(http.StatusNotModified, rec.Code, tag)
+			asserter.Empty(rec.Body.String(), tag)
+			asserter.Equal(`"1"`, rec.Header().Get("ETag"), tag)
+		}
+
+		rec, _ := serve(http.MethodGet, "/items/1", "", map[string]string{"If-None-Match": `"2"`})
+		asserter.Equal(http.StatusOK, rec.Code)
+		asserter.Equal(`"1"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("changes with a stale If-Match are conflicts", func(_ *testing.T) {
+		stale := map[string]string{"If-Match": `"2"`}
+		for method, body := range map[string]string{
+			http.MethodPut:    `{"name":"Jar"}`,
+			http.MethodPatch:  `{"name":"Jar"}`,
+			http.MethodDelete: "",
+		} {
+			rec, prob := serve(method, "/items/1", body, stale)
+			asserter.Equal(http.StatusConflict, rec.Code, method)
+			asserter.Equal(http.StatusConflict, prob.Status, method)
+		}
+
+		// the header takes precedence over the version in the body
+		rec, _ := serve(http.MethodPut, "/items/1", `{"name":"Jar","version":1}`, stale)
+		asserter.Equal(http.StatusConflict, rec.Code)
+	})
+
+	t.Run("changes with a matching If-Match are applied", func(_ *testing.T) {
+		rec, _ := serve(http.MethodPut, "/items/1", `{"name":"Jar"}`, map[string]string{"If-Match": `"1"`})
+		requirer.Equal(http.StatusOK, rec.Code, rec.Body.String())
+		asserter.Equal(`"2"`, rec.Header().Get("ETag"))
+
+		rec, _ = serve(http.MethodPatch, "/items/1", `{"name":"Vase"}`, map[string]string{"If-Match": `"2"`})
+		requirer.Equal(http.StatusOK, rec.Code, rec.Body.String())
+		asserter.Equal(`"3"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("weak or malformed If-Match are rejected", func(_ *testing.T) {
+		for _, tag := range []string{`W/"3"`, `"abc"`, `"3", "4"`, `"0"`} {
+			for method, body := range map[string]string{
+				http.MethodPut:    `{"name":"Jar"}`,
+				http.MethodPatch:  `{"name":"Jar"}`,
+				http.MethodDelete: "",
+			} {
+				rec, prob := serve(method, "/items/1", body, map[string]string{"If-Match": tag})
+				asserter.Equal(http.StatusBadRequest, rec.Code, method+" "+tag)
+				asserter.Contains(prob.Detail, "If-Match", method+" "+tag)
+			}
+		}
+
+		rec, _ := serve(http.MethodGet, "/items/1", "", nil)
+		asserter.Equal(`"3"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("deletes with a matching If-Match are applied", func(_ *testing.T) {
+		rec, _ := serve(http.MethodDelete, "/items/1", "", map[string]string{"If-Match": `"3"`})
+		asserter.Equal(http.StatusNoContent, rec.Code, rec.Body.String())
+	})
+}
@@ -4,8 +4,8 @@ package http
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"strings"
 	"sync"
@@ -17,9 +17,15 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel/attribute"
 	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
 	"github.com/prashantkr001/template-go/internal/api"
+	"github.com/prashantkr001/template-go/internal/item"
 	"github.com/prashantkr001/template-go/internal/pkg/apm"
+	"github.com/prashantkr001/template-go/internal/pkg/auth"
+	"github.com/prashantkr001/template-go/internal/pkg/idempotency"
+	"github.com/prashantkr001/template-go/internal/pkg/logger"
 )
 
 type Config struct {
@@ -29,7 +35,13 @@ type Config struct {
 	ReadTimeout       time.Duration
 	WriteTimeout      time.Duration
 	IdleTimeout       time.Duration
-	EnableAccesslog   bool
+	// ProblemTypeBaseURI is prefixed to the error code, for the type URI of problem+json responses
+	ProblemTypeBaseURI string
+	// ClientErrorLogLevel is the level at which 4xx responses are logged, 5xx are always logged as errors
+	ClientErrorLogLevel string
+	// MaxBodyBytes is the maximum size of request bodies, defaults to 1 MiB
+	MaxBodyBytes    int64
+	EnableAccesslog bool
 }
 
 type HTTP struct {
@@ -39,6 +51,15 @@ type HTTP struct {
 	apis              *api.API
 	shutdownInitiated bool
 	serverStartTime   time.Time
+	// problemTypeBaseURI & clientErrLevel are used by the ErrorHandler
+	problemTypeBaseURI string
+	clientErrLevel     zapcore.Level
+	// openAPIJSON is the OpenAPI document served at PathOpenAPI
+	openAPIJSON []byte
+	// validator validates the requests against the OpenAPI document
+	validator *requestValidator
+	// authn authenticates the requests, they're all anonymous if it is nil
+	authn auth.Authenticator
 }
 
 func (ht *HTTP) Start() error {
@@ -70,6 +91,9 @@ func (ht *HTTP) StartedAt() time.Time {
 
 type HandlerFuncErr func(w http.ResponseWriter, req *http.Request) error
 
+// ErrorHandler responds with the error as an RFC 7807 problem (application/problem+json), or as plain
+// text if the client prefers it (Accept header). 5xx errors are logged with the stacktrace, and 4xx
+// errors at the configured level.
 func (ht *HTTP) ErrorHandler(fn HandlerFuncErr) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		err := fn(w, r)
@@ -77,21 +101,149 @@ func (ht *HTTP) ErrorHandler(fn HandlerFuncErr) http.HandlerFunc {
 			return
 		}
 
-		status, message, _ := errors.HTTPStatusCodeMessage(err)
-		w.WriteHeader(status)
-		_, _ = w.Write([]byte(message))
+		prob := ht.newProblem(r, err)
+		if prefersPlainText(r.Header.Get("Accept")) || writeProblem(w, prob) != nil {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.WriteHeader(prob.Status)
+			_, _ = w.Write([]byte(prob.Detail))
+		}
+
+		ht.logError(r, prob, err)
+	}
+}
+
+func (ht *HTTP) logError(r *http.Request, prob *problem, err error) {
+	fields := []zap.Field{
+		zap.Int("status", prob.Status),
+		zap.String("code", prob.Code),
+		zap.String("method", r.Method),
+		zap.String("path", r.URL.Path),
+	}
+	if prob.Status >= http.StatusInternalServerError {
+		logger.ErrorCtx(r.Context(), errors.Stacktrace(err), fields...)
+		return
+	}
+
+	switch msg := err.Error(); {
+	case ht.clientErrLevel <= zapcore.DebugLevel:
+		logger.DebugCtx(r.Context(), msg, fields...)
+	case ht.clientErrLevel == zapcore.InfoLevel:
+		logger.InfoCtx(r.Context(), msg, fields...)
+	case ht.clientErrLevel == zapcore.WarnLevel:
+		logger.WarnCtx(r.Context(), msg, fields...)
+	default:
+		logger.ErrorCtx(r.Context(), msg, fields...)
+	}
+}
+
+// HeaderActor identifies the user or service making the request, it is recorded in the history of
+// the items changed by the request.
+const HeaderActor = "X-Actor"
 
-		// log the full error here for troubleshooting.
-		// *Assuming* we need not log 4xx errors since they're client side errors
-		// maybe we just need internal errors to be logged.
-		if status >= http.StatusInternalServerError {
-			log.Println(errors.Stacktrace(err))
+// actorMiddleware sets the actor of the request in its context, requests without an actor are
+// recorded as anonymous. The actor of authenticated requests is always their principal, i.e. the
+// header is ignored.
+func actorMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		actor := strings.TrimSpace(req.Header.Get(HeaderActor))
+		if principal := auth.PrincipalOf(req.Context()); principal != nil {
+			actor = principal.Subject
 		}
+		if actor == "" {
+			actor = item.ActorAnonymous
+		}
+		next.ServeHTTP(w, req.WithContext(item.WithActor(req.Context(), actor)))
+	})
+}
+
+// HeaderAPIKey is the API key of the request, bearer tokens are in the Authorization header instead
+const HeaderAPIKey = "X-API-Key"
+
+// authMiddleware authenticates the requests, and sets the principal in their context. Requests of the
+// "/-/" paths (e.g. the OpenAPI document) are not authenticated, and neither are any requests if
+// there's no authenticator.
+func (ht *HTTP) authMiddleware(next http.Handler) http.Handler {
+	if ht.authn == nil {
+		return next
 	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if strings.HasPrefix(req.URL.Path, "/-/") {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		principal, err := ht.authn.Authenticate(req.Context(), auth.Credentials{
+			BearerToken: auth.BearerToken(req.Header.Get("Authorization")),
+			APIKey:      strings.TrimSpace(req.Header.Get(HeaderAPIKey)),
+		})
+		if err != nil {
+			if errors.Type(err) == errors.TypeUnauthenticated {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+			}
+			ht.ErrorHandler(func(http.ResponseWriter, *http.Request) error {
+				return err
+			})(w, req)
+			return
+		}
+
+		next.ServeHTTP(w, req.WithContext(auth.WithPrincipal(req.Context(), principal)))
+	})
 }
 
+// HeaderTenant is the tenant of the request, all the items accessed by the request are of the tenant.
+// Requests without a tenant are of item.DefaultTenant.
+const HeaderTenant = "X-Tenant-ID"
+
+// tenantMiddleware sets the tenant of the request in its context, it is validated by the item service
+func tenantMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		tenant := strings.TrimSpace(req.Header.Get(HeaderTenant))
+		if tenant != "" {
+			req = req.WithContext(item.WithTenant(req.Context(), tenant))
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// tenantLabelInvalid is the tenant label of the metrics of requests with an invalid tenant
+const tenantLabelInvalid = "invalid"
+
+// tenantLabelMiddleware adds the tenant to the labels of the HTTP metrics. It is only after authentication,
+// and the tenant is validated, so that callers can't add arbitrary labels to the metrics.
+func tenantLabelMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		labeler, ok := otelhttp.LabelerFromContext(req.Context())
+		if ok {
+			tenant := item.Tenant(req.Context())
+			if !item.ValidTenant(tenant) {
+				tenant = tenantLabelInvalid
+			}
+			labeler.Add(attribute.String("tenant", tenant))
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// HeaderIdempotencyKey makes the item creation idempotent, i.e. the retries with the same key get the
+// response of the original request.
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// idempotencyMiddleware sets the idempotency key of the request (if any) in its context
+func idempotencyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		key := strings.TrimSpace(req.Header.Get(HeaderIdempotencyKey))
+		if key != "" {
+			req = req.WithContext(idempotency.WithKey(req.Context(), key))
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// chiURIPattern returns the route pattern matching the request. A new route context is used for
+// matching, since matching appends to the route patterns of the context.
 func chiURIPattern(router *chi.Mux, r *http.Request) string {
-	cctx := chi.RouteContext(r.Context())
+	cctx := chi.NewRouteContext()
 	uriPattern := "unmatched-path"
 	if router.Match(cctx, r.Method, r.URL.Path) {
 		uriPattern = cctx.RoutePattern()
@@ -99,17 +251,20 @@ func chiURIPattern(router *chi.Mux, r *http.Request) string {
 	return uriPattern
 }
 
-func newChiRouter(cfg *Config) chi.Router { //nolint:ireturn,nolintlint
+func newChiRouter(cfg *Config, authMiddleware func(http.Handler) http.Handler) chi.Router { //nolint:ireturn,nolintlint
 	router := chi.NewRouter()
 	router.Use(
 		middleware.Recoverer,
+		tenantMiddleware,
 		func(h http.Handler) http.Handler {
 			wrapped := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				l := new(otelhttp.Labeler)
-				l.Add(attribute.KeyValue{
-					Key:   semconv.HTTPRouteKey,
-					Value: attribute.StringValue(chiURIPattern(router, r)),
-				})
+				l.Add(
+					attribute.KeyValue{
+						Key:   semconv.HTTPRouteKey,
+						Value: attribute.StringValue(chiURIPattern(router, r)),
+					},
+				)
 
 				h.ServeHTTP(
 					w,
@@ -129,6 +284,10 @@ func newChiRouter(cfg *Config) chi.Router { //nolint:ireturn,nolintlint
 			},
 		},
 		),
+		authMiddleware,
+		tenantLabelMiddleware,
+		actorMiddleware,
+		idempotencyMiddleware,
 	)
 
 	if cfg.EnableAccesslog {
@@ -138,7 +297,26 @@ func newChiRouter(cfg *Config) chi.Router { //nolint:ireturn,nolintlint
 	return router
 }
 
-func New(apis *api.API, cfg *Config) *HTTP {
+// New returns the HTTP server of the APIs. Requests are authenticated using authn, unless it is nil.
+func New(apis *api.API, authn auth.Authenticator, cfg *Config) (*HTTP, error) {
+	clientErrLevel := zapcore.InfoLevel
+	if cfg.ClientErrorLogLevel != "" {
+		level, err := zapcore.ParseLevel(cfg.ClientErrorLogLevel)
+		if err != nil {
+			return nil, errors.Validationf("invalid client error log level '%s'", cfg.ClientErrorLogLevel)
+		}
+		clientErrLevel = level
+	}
+
+	doc, err := NewOpenAPI()
+	if err != nil {
+		return nil, err
+	}
+	openAPIJSON, err := json.Marshal(doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the OpenAPI document")
+	}
+
 	ht := &HTTP{
 		locker: &sync.Mutex{},
 		apis:   apis,
@@ -148,11 +326,17 @@ func New(apis *api.API, cfg *Config) *HTTP {
 			ReadTimeout:       cfg.ReadTimeout,
 			WriteTimeout:      cfg.WriteTimeout,
 		},
+		problemTypeBaseURI: cfg.ProblemTypeBaseURI,
+		clientErrLevel:     clientErrLevel,
+		openAPIJSON:        openAPIJSON,
+		validator:          newRequestValidator(doc, cfg.MaxBodyBytes),
+		authn:              authn,
 	}
 
-	router := newChiRouter(cfg)
+	router := newChiRouter(cfg, ht.authMiddleware)
 	ht.itemRoutes(router)
+	ht.docsRoutes(router)
 	ht.server.Handler = router
 
-	return ht
+	return ht, nil
 }
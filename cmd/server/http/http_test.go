@@ -0,0 +1,30 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestTenantLabelMiddleware(t *testing.T) {
+	asserter := assert.New(t)
+
+	for tenant, label := range map[string]string{
+		"":             "default",
+		"acme":         "acme",
+		"Not A Tenant": tenantLabelInvalid,
+	} {
+		labeler := new(otelhttp.Labeler)
+		req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+		req.Header.Set(HeaderTenant, tenant)
+		req = req.WithContext(otelhttp.ContextWithLabeler(req.Context(), labeler))
+
+		handler := tenantMiddleware(tenantLabelMiddleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		asserter.Equal([]attribute.KeyValue{attribute.String("tenant", label)}, labeler.Get(), tenant)
+	}
+}
@@ -0,0 +1,517 @@
+package http
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3gen"
+	"github.com/go-chi/chi/v5"
+	"github.com/naughtygopher/errors"
+
+	"github.com/prashantkr001/template-go/internal/item"
+)
+
+const (
+	// PathOpenAPI serves the OpenAPI document of the HTTP API, and PathDocs serves a UI to browse it
+	PathOpenAPI = "/-/openapi.json"
+	PathDocs    = "/-/docs"
+
+	openAPIVersion = "3.1.0"
+	apiVersion     = "1.0.0"
+)
+
+//go:embed openapi.html
+var docsPage []byte
+
+// openAPISchemas are the types (de)serialized by the handlers, the component schemas are generated from them
+var openAPISchemas = map[string]any{
+	"Item":         item.Item{},
+	"Patch":        item.Patch{},
+	"CreateResult": item.CreateResult{},
+	"HistoryEntry": item.HistoryEntry{},
+	"FieldChange":  item.FieldChange{},
+	"FieldError":   item.FieldError{},
+	"Problem":      problem{},
+}
+
+var (
+	attributeType = reflect.TypeOf(item.Attribute{})
+	timeType      = reflect.TypeOf(time.Time{})
+	// openAPIEnums are the values of the string types, which are enums
+	openAPIEnums = map[reflect.Type][]any{
+		reflect.TypeOf(item.CreateStatus("")): {
+			item.CreateStatusCreated, item.CreateStatusDuplicate, item.CreateStatusInvalid, item.CreateStatusFailed,
+		},
+		reflect.TypeOf(item.HistoryAction("")): {
+			item.HistoryCreated, item.HistoryUpdated, item.HistoryDeleted, item.HistoryRestored,
+		},
+	}
+)
+
+func (ht *HTTP) docsRoutes(router chi.Router) {
+	router.Get(PathOpenAPI, ht.OpenAPI)
+	router.Get(PathDocs, Docs)
+}
+
+// OpenAPI responds with the OpenAPI document of the HTTP API
+func (ht *HTTP) OpenAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(ht.openAPIJSON)
+}
+
+// Docs responds with a Swagger UI page, of the document served at PathOpenAPI
+func Docs(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(docsPage)
+}
+
+// NewOpenAPI returns the OpenAPI document of the HTTP API. The schemas are generated from the item
+// types, and the operations mirror itemRoutes. TestOpenAPIRoutes fails if the routes drift from it.
+func NewOpenAPI() (*openapi3.T, error) {
+	schemas, err := newOpenAPISchemas()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &openapi3.T{
+		OpenAPI: openAPIVersion,
+		Info: &openapi3.Info{
+			Title:   "Items API",
+			Version: apiVersion,
+			Description: "All the errors are RFC 7807 problem details (application/problem+json), " +
+				"or plain text if the client prefers it using the Accept header. " +
+				"If authentication is enabled, requests should have either a bearer JWT or an API key.",
+		},
+		Paths: openapi3.NewPaths(),
+		Components: &openapi3.Components{
+			Schemas:         schemas,
+			Parameters:      newOpenAPIParameters(),
+			Headers:         newOpenAPIHeaders(),
+			Responses:       newOpenAPIResponses(),
+			SecuritySchemes: newOpenAPISecuritySchemes(),
+		},
+		Security: openapi3.SecurityRequirements{
+			openapi3.NewSecurityRequirement().Authenticate("BearerAuth"),
+			openapi3.NewSecurityRequirement().Authenticate("APIKey"),
+		},
+	}
+
+	for _, op := range itemOperations() {
+		op.Parameters = append(op.Parameters, parameterRef("Tenant"), parameterRef("Actor"))
+		op.Responses.Set("401", responseRef("Unauthenticated"))
+		op.Responses.Set("403", responseRef("Forbidden"))
+		if op.RequestBody != nil {
+			op.Responses.Set("413", responseRef("ContentTooLarge"))
+		}
+		op.Responses.Set("default", responseRef("Error"))
+		doc.AddOperation(op.path, op.method, op.Operation)
+	}
+
+	// the document is loaded from its JSON, so that all the references are resolved
+	payload, err := json.Marshal(doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal the OpenAPI document")
+	}
+
+	loader := openapi3.NewLoader()
+	doc, err = loader.LoadFromData(payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load the OpenAPI document")
+	}
+
+	err = doc.Validate(loader.Context)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid OpenAPI document")
+	}
+
+	return doc, nil
+}
+
+type operation struct {
+	*openapi3.Operation
+	method string
+	path   string
+}
+
+func itemOperations() []operation {
+	itemResponse := func(description string) *openapi3.ResponseRef {
+		resp := openapi3.NewResponse().WithDescription(description).WithJSONSchemaRef(schemaRef("Item"))
+		resp.Headers = openapi3.Headers{"ETag": headerRef("ETag")}
+		return &openapi3.ResponseRef{Value: resp}
+	}
+	pageResponse := func(description, schema string) *openapi3.ResponseRef {
+		page := openapi3.NewArraySchema()
+		page.Items = schemaRef(schema)
+		resp := openapi3.NewResponse().WithDescription(description).WithJSONSchema(page)
+		resp.Headers = openapi3.Headers{"Link": headerRef("Link")}
+		return &openapi3.ResponseRef{Value: resp}
+	}
+	jsonBody := func(description string, schema *openapi3.SchemaRef) *openapi3.RequestBodyRef {
+		return &openapi3.RequestBodyRef{
+			Value: openapi3.NewRequestBody().WithDescription(description).WithRequired(true).WithJSONSchemaRef(schema),
+		}
+	}
+
+	batchSchema := openapi3.NewArraySchema()
+	batchSchema.Items = schemaRef("Item")
+	batchSchema.MaxItems = openapi3.Uint64Ptr(item.MaxCreateBatch)
+	resultsSchema := openapi3.NewArraySchema()
+	resultsSchema.Items = schemaRef("CreateResult")
+
+	return []operation{
+		{
+			method: http.MethodPost, path: "/items",
+			Operation: &openapi3.Operation{
+				OperationID: "createItem",
+				Summary:     "Create an item, if there's none with the same ID",
+				Parameters:  openapi3.Parameters{parameterRef("IdempotencyKey")},
+				RequestBody: jsonBody("The item to create, tenant & version are ignored", schemaRef("Item")),
+				Responses: openapi3.NewResponses(
+					openapi3.WithStatus(http.StatusOK, itemResponse("The created item")),
+					openapi3.WithStatus(http.StatusBadRequest, responseRef("BadRequest")),
+					openapi3.WithStatus(http.StatusConflict, responseRef("Conflict")),
+					openapi3.WithStatus(http.StatusUnprocessableEntity, responseRef("UnprocessableEntity")),
+				),
+			},
+		},
+		{
+			method: http.MethodPost, path: "/items:batch",
+			Operation: &openapi3.Operation{
+				OperationID: "createItems",
+				Summary:     "Create items in a batch",
+				Description: "The result of every item is in the same order as the request, " +
+					"the status is 200 even if some of the items were not created. " +
+					"The batch is limited by the size of the request body as well (1 MiB by default), " +
+					"hence large items should be created in smaller batches.",
+				RequestBody: jsonBody("The items to create", &openapi3.SchemaRef{Value: batchSchema}),
+				Responses: openapi3.NewResponses(
+					openapi3.WithStatus(http.StatusOK, &openapi3.ResponseRef{
+						Value: openapi3.NewResponse().WithDescription("The result of every item").WithJSONSchema(resultsSchema),
+					}),
+					openapi3.WithStatus(http.StatusBadRequest, responseRef("BadRequest")),
+				),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/items",
+			Operation: &openapi3.Operation{
+				OperationID: "listItems",
+				Summary:     "List a page of items",
+				Description: "If there are more items, the URL of the next page is in the Link header.",
+				Parameters: openapi3.Parameters{
+					parameterRef("Limit"),
+					parameterRef("Cursor"),
+					parameterRef("NamePrefix"),
+					parameterRef("NameContains"),
+					parameterRef("IDFrom"),
+					parameterRef("IDTo"),
+					parameterRef("Sort"),
+					parameterRef("IncludeDeleted"),
+					parameterRef("Tag"),
+					parameterRef("Label"),
+				},
+				Responses: openapi3.NewResponses(
+					openapi3.WithStatus(http.StatusOK, pageResponse("A page of items", "Item")),
+					openapi3.WithStatus(http.StatusBadRequest, responseRef("BadRequest")),
+				),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/items/{id}",
+			Operation: &openapi3.Operation{
+				OperationID: "getItem",
+				Summary:     "Get an item",
+				Parameters: openapi3.Parameters{
+					parameterRef("ItemID"),
+					parameterRef("IncludeDeleted"),
+					parameterRef("IfNoneMatch"),
+				},
+				Responses: openapi3.NewResponses(
+					openapi3.WithStatus(http.StatusOK, itemResponse("The item")),
+					openapi3.WithStatus(http.StatusNotModified, &openapi3.ResponseRef{
+						Value: openapi3.NewResponse().WithDescription("The item matches the If-None-Match header"),
+					}),
+					openapi3.WithStatus(http.StatusBadRequest, responseRef("BadRequest")),
+					openapi3.WithStatus(http.StatusNotFound, responseRef("NotFound")),
+				),
+			},
+		},
+		{
+			method: http.MethodPut, path: "/items/{id}",
+			Operation: &openapi3.Operation{
+				OperationID: "updateItem",
+				Summary:     "Replace an item",
+				Parameters:  openapi3.Parameters{parameterRef("ItemID"), parameterRef("IfMatch")},
+				RequestBody: jsonBody("The item, the ID in the path takes precedence", schemaRef("Item")),
+				Responses: openapi3.NewResponses(
+					openapi3.WithStatus(http.StatusOK, itemResponse("The updated item")),
+					openapi3.WithStatus(http.StatusBadRequest, responseRef("BadRequest")),
+					openapi3.WithStatus(http.StatusNotFound, responseRef("NotFound")),
+					openapi3.WithStatus(http.StatusConflict, responseRef("Conflict")),
+					openapi3.WithStatus(http.StatusUnprocessableEntity, responseRef("UnprocessableEntity")),
+				),
+			},
+		},
+		{
+			method: http.MethodPatch, path: "/items/{id}",
+			Operation: &openapi3.Operation{
+				OperationID: "patchItem",
+				Summary:     "Update some of the fields of an item",
+				Parameters:  openapi3.Parameters{parameterRef("ItemID"), parameterRef("IfMatch")},
+				RequestBody: jsonBody("The fields to update, the ones not provided are left untouched", schemaRef("Patch")),
+				Responses: openapi3.NewResponses(
+					openapi3.WithStatus(http.StatusOK, itemResponse("The patched item")),
+					openapi3.WithStatus(http.StatusBadRequest, responseRef("BadRequest")),
+					openapi3.WithStatus(http.StatusNotFound, responseRef("NotFound")),
+					openapi3.WithStatus(http.StatusConflict, responseRef("Conflict")),
+					openapi3.WithStatus(http.StatusUnprocessableEntity, responseRef("UnprocessableEntity")),
+				),
+			},
+		},
+		{
+			method: http.MethodDelete, path: "/items/{id}",
+			Operation: &openapi3.Operation{
+				OperationID: "deleteItem",
+				Summary:     "Soft delete an item, it can be restored until it's purged",
+				Parameters:  openapi3.Parameters{parameterRef("ItemID"), parameterRef("IfMatch")},
+				Responses: openapi3.NewResponses(
+					openapi3.WithStatus(http.StatusNoContent, &openapi3.ResponseRef{
+						Value: openapi3.NewResponse().WithDescription("The item is deleted"),
+					}),
+					openapi3.WithStatus(http.StatusBadRequest, responseRef("BadRequest")),
+					openapi3.WithStatus(http.StatusNotFound, responseRef("NotFound")),
+					openapi3.WithStatus(http.StatusConflict, responseRef("Conflict")),
+				),
+			},
+		},
+		{
+			method: http.MethodPost, path: "/items/{id}:restore",
+			Operation: &openapi3.Operation{
+				OperationID: "restoreItem",
+				Summary:     "Restore a deleted item, which is not purged yet",
+				Parameters:  openapi3.Parameters{parameterRef("ItemID"), parameterRef("IfMatch")},
+				Responses: openapi3.NewResponses(
+					openapi3.WithStatus(http.StatusOK, itemResponse("The restored item")),
+					openapi3.WithStatus(http.StatusBadRequest, responseRef("BadRequest")),
+					openapi3.WithStatus(http.StatusNotFound, responseRef("NotFound")),
+					openapi3.WithStatus(http.StatusConflict, responseRef("Conflict")),
+					openapi3.WithStatus(http.StatusUnprocessableEntity, responseRef("UnprocessableEntity")),
+				),
+			},
+		},
+		{
+			method: http.MethodGet, path: "/items/{id}/history",
+			Operation: &openapi3.Operation{
+				OperationID: "listItemHistory",
+				Summary:     "List a page of the changes of an item, newest first",
+				Parameters: openapi3.Parameters{
+					parameterRef("ItemID"),
+					parameterRef("Limit"),
+					parameterRef("Cursor"),
+				},
+				Responses: openapi3.NewResponses(
+					openapi3.WithStatus(http.StatusOK, pageResponse("A page of the history", "HistoryEntry")),
+					openapi3.WithStatus(http.StatusBadRequest, responseRef("BadRequest")),
+				),
+			},
+		},
+	}
+}
+
+func newOpenAPIParameters() openapi3.ParametersMap {
+	integer := func(minimum float64) *openapi3.Schema {
+		return openapi3.NewIntegerSchema().WithMin(minimum)
+	}
+
+	sortFields := []any{}
+	for _, field := range []item.SortField{item.SortByID, item.SortByName, item.SortByCreatedAt} {
+		sortFields = append(sortFields, string(field), "-"+string(field))
+	}
+
+	params := map[string]*openapi3.Parameter{
+		"ItemID": openapi3.NewPathParameter("id").WithSchema(integer(1)),
+		"Limit": openapi3.NewQueryParameter("limit").WithSchema(integer(0)).
+			WithDescription("The page size, it is capped to the maximum page size"),
+		"Cursor": openapi3.NewQueryParameter("cursor").WithSchema(openapi3.NewStringSchema()).
+			WithDescription("The cursor of the page, from the Link header of the previous page"),
+		"NamePrefix":   openapi3.NewQueryParameter("name_prefix").WithSchema(openapi3.NewStringSchema()),
+		"NameContains": openapi3.NewQueryParameter("name_contains").WithSchema(openapi3.NewStringSchema()),
+		"IDFrom": openapi3.NewQueryParameter("id_from").WithSchema(integer(0)).
+			WithDescription("The minimum ID (inclusive)"),
+		"IDTo": openapi3.NewQueryParameter("id_to").WithSchema(integer(0)).
+			WithDescription("The maximum ID (inclusive)"),
+		"Sort": openapi3.NewQueryParameter("sort").WithSchema(openapi3.NewStringSchema().WithEnum(sortFields...)).
+			WithDescription("The field to sort by, prefixed with '-' for descending order"),
+		"IncludeDeleted": openapi3.NewQueryParameter("include_deleted").WithSchema(openapi3.NewBoolSchema()).
+			WithDescription("Includes the soft deleted items"),
+		"Tag": openapi3.NewQueryParameter("tag").WithSchema(openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())).
+			WithDescription("Filters the items with all the tags"),
+		"Label": openapi3.NewQueryParameter("label").
+			WithSchema(openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema().WithPattern(":"))).
+			WithDescription("Filters the items with all the labels, as key:value"),
+		"IfMatch": openapi3.NewHeaderParameter("If-Match").WithSchema(openapi3.NewStringSchema()).
+			WithDescription("The ETag of the item the change is expected to be applied on, or '*' for any. Weak ETags are rejected"),
+		"IfNoneMatch": openapi3.NewHeaderParameter("If-None-Match").WithSchema(openapi3.NewStringSchema()),
+		"IdempotencyKey": openapi3.NewHeaderParameter(HeaderIdempotencyKey).
+			WithSchema(openapi3.NewStringSchema().WithMaxLength(255)).
+			WithDescription("Retries with the same key get the response of the original request"),
+		"Tenant": openapi3.NewHeaderParameter(HeaderTenant).WithSchema(openapi3.NewStringSchema()).
+			WithDescription("The tenant of the items, defaults to '" + item.DefaultTenant + "'"),
+		"Actor": openapi3.NewHeaderParameter(HeaderActor).WithSchema(openapi3.NewStringSchema()).
+			WithDescription("The user or service making the change, it's recorded in the history. " +
+				"It's ignored if the request is authenticated, the actor is the authenticated principal instead."),
+	}
+
+	paramsMap := make(openapi3.ParametersMap, len(params))
+	for name, param := range params {
+		paramsMap[name] = &openapi3.ParameterRef{Value: param}
+	}
+	return paramsMap
+}
+
+func newOpenAPIHeaders() openapi3.Headers {
+	header := func(description string) *openapi3.HeaderRef {
+		return &openapi3.HeaderRef{Value: &openapi3.Header{Parameter: openapi3.Parameter{
+			Description: description,
+			Schema:      openapi3.NewStringSchema().NewRef(),
+		}}}
+	}
+
+	return openapi3.Headers{
+		"ETag": header("The version of the item, to be used with If-Match & If-None-Match"),
+		"Link": header("The URL of the next page with rel=\"next\", if there are more"),
+	}
+}
+
+func newOpenAPIResponses() openapi3.ResponseBodies {
+	problemResponse := func(description string) *openapi3.ResponseRef {
+		return &openapi3.ResponseRef{Value: openapi3.NewResponse().WithDescription(description).WithContent(
+			openapi3.NewContentWithSchemaRef(schemaRef("Problem"), []string{contentTypeProblem}),
+		)}
+	}
+
+	return openapi3.ResponseBodies{
+		"BadRequest":          problemResponse("The request is invalid, e.g. malformed JSON or query params"),
+		"NotFound":            problemResponse("The item does not exist"),
+		"Conflict":            problemResponse("The item exists already, or its version does not match"),
+		"UnprocessableEntity": problemResponse("The item is invalid, the errors have all the violations"),
+		"Unauthenticated":     problemResponse("The credentials are missing or invalid"),
+		"Forbidden":           problemResponse("The caller does not have the permission required"),
+		"ContentTooLarge":     problemResponse("The request body is larger than the limit, 1 MiB by default"),
+		"Error":               problemResponse("An unexpected error"),
+	}
+}
+
+func newOpenAPISecuritySchemes() openapi3.SecuritySchemes {
+	return openapi3.SecuritySchemes{
+		"BearerAuth": &openapi3.SecuritySchemeRef{
+			Value: openapi3.NewJWTSecurityScheme().WithDescription(
+				"A JWT signed by a key of the configured JWKS, the roles & scopes of the caller are in its claims",
+			),
+		},
+		"APIKey": &openapi3.SecuritySchemeRef{
+			Value: openapi3.NewSecurityScheme().WithType("apiKey").WithIn("header").WithName(HeaderAPIKey),
+		},
+	}
+}
+
+// newOpenAPISchemas generates the component schemas of openAPISchemas. Nested types are referred to
+// by name, so all of them are expected to be in openAPISchemas.
+func newOpenAPISchemas() (openapi3.Schemas, error) {
+	gen := openapi3gen.NewGenerator(
+		openapi3gen.CreateComponentSchemas(openapi3gen.ExportComponentSchemasOptions{
+			ExportComponentSchemas: true,
+			ExportTopLevelSchema:   true,
+		}),
+		openapi3gen.CreateTypeNameGenerator(func(t reflect.Type) string {
+			for name, value := range openAPISchemas {
+				if reflect.TypeOf(value) == t {
+					return name
+				}
+			}
+			return t.Name()
+		}),
+		openapi3gen.SchemaCustomizer(customizeSchema),
+	)
+
+	schemas := openapi3.Schemas{}
+	for _, value := range openAPISchemas {
+		_, err := gen.NewSchemaRefForValue(value, schemas)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to generate the schema of %T", value)
+		}
+	}
+
+	for name, value := range openAPISchemas {
+		schema := schemas[name]
+		if schema == nil {
+			return nil, errors.Internalf("schema of %T was not generated", value)
+		}
+		schema.Value.Required = requiredFields(reflect.TypeOf(value))
+	}
+
+	// attributes have no properties, so they're not exported by the generator
+	schemas["Attribute"] = &openapi3.SchemaRef{Value: attributeSchema()}
+
+	return schemas, nil
+}
+
+func customizeSchema(_ string, t reflect.Type, _ reflect.StructTag, schema *openapi3.Schema) error {
+	// fields of pointer types are all omitted if nil, rather than null
+	schema.Nullable = false
+	switch {
+	case t == attributeType:
+		*schema = *attributeSchema()
+	case t.Kind() == reflect.Struct && t != timeType:
+		// unknown fields are rejected by the handlers
+		schema.AdditionalProperties = openapi3.AdditionalProperties{Has: openapi3.BoolPtr(false)}
+	}
+	if enum, ok := openAPIEnums[t]; ok {
+		schema.Enum = enum
+	}
+	return nil
+}
+
+func attributeSchema() *openapi3.Schema {
+	schema := openapi3.NewOneOfSchema(
+		openapi3.NewStringSchema(),
+		openapi3.NewFloat64Schema(),
+		openapi3.NewBoolSchema(),
+	)
+	schema.Description = "A typed value, either a string, number or boolean"
+	return schema
+}
+
+// requiredFields returns the JSON names of the fields of the struct, which are always serialized
+func requiredFields(t reflect.Type) []string {
+	required := []string{}
+	for i := range t.NumField() {
+		name, opts, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" || strings.Contains(opts, "omitempty") || strings.Contains(opts, "omitzero") {
+			continue
+		}
+		required = append(required, name)
+	}
+	return required
+}
+
+func schemaRef(name string) *openapi3.SchemaRef {
+	return openapi3.NewSchemaRef("#/components/schemas/"+name, nil)
+}
+
+func parameterRef(name string) *openapi3.ParameterRef {
+	return &openapi3.ParameterRef{Ref: "#/components/parameters/" + name}
+}
+
+func headerRef(name string) *openapi3.HeaderRef {
+	return &openapi3.HeaderRef{Ref: "#/components/headers/" + name}
+}
+
+func responseRef(name string) *openapi3.ResponseRef {
+	return &openapi3.ResponseRef{Ref: "#/components/responses/" + name}
+}
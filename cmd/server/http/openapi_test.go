@@ -0,0 +1,93 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIRoutes(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+
+	ht, err := New(nil, nil, &Config{})
+	requirer.NoError(err)
+	doc, err := NewOpenAPI()
+	requirer.NoError(err)
+
+	routes := []string{}
+	router, _ := ht.server.Handler.(chi.Routes)
+	requirer.NotNil(router)
+	err = chi.Walk(router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if !strings.HasPrefix(route, "/-/") {
+			routes = append(routes, method+" "+route)
+		}
+		return nil
+	})
+	requirer.NoError(err)
+
+	operations := []string{}
+	for path, pathItem := range doc.Paths.Map() {
+		for method := range pathItem.Operations() {
+			operations = append(operations, method+" "+path)
+		}
+	}
+
+	sort.Strings(routes)
+	sort.Strings(operations)
+	asserter.Equal(routes, operations, "the registered routes & the OpenAPI operations should be the same")
+}
+
+func TestOpenAPI(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+
+	ht, err := New(nil, nil, &Config{})
+	requirer.NoError(err)
+
+	t.Run("document is served", func(_ *testing.T) {
+		rec := httptest.NewRecorder()
+		ht.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, PathOpenAPI, nil))
+		asserter.Equal(http.StatusOK, rec.Code)
+		asserter.Equal("application/json", rec.Header().Get("Content-Type"))
+
+		loader := openapi3.NewLoader()
+		doc, lerr := loader.LoadFromData(rec.Body.Bytes())
+		requirer.NoError(lerr)
+		requirer.NoError(doc.Validate(loader.Context))
+		asserter.Equal(openAPIVersion, doc.OpenAPI)
+	})
+
+	t.Run("schemas are derived from the types", func(_ *testing.T) {
+		doc, derr := NewOpenAPI()
+		requirer.NoError(derr)
+
+		itemSchema := doc.Components.Schemas["Item"].Value
+		asserter.Contains(itemSchema.Properties, "createdAt")
+		asserter.Equal("date-time", itemSchema.Properties["createdAt"].Value.Format)
+		asserter.Len(itemSchema.Properties["attributes"].Value.AdditionalProperties.Schema.Value.OneOf, 3)
+
+		problemSchema := doc.Components.Schemas["Problem"].Value
+		asserter.Equal([]string{"type", "title", "status", "code"}, problemSchema.Required)
+
+		createResult := doc.Components.Schemas["CreateResult"].Value
+		asserter.Contains(createResult.Properties["status"].Value.Enum, "duplicate")
+
+		notFound := doc.Paths.Find("/items/{id}").Get.Responses.Status(http.StatusNotFound).Value
+		asserter.NotNil(notFound.Content.Get(contentTypeProblem))
+	})
+
+	t.Run("docs page", func(_ *testing.T) {
+		rec := httptest.NewRecorder()
+		ht.server.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, PathDocs, nil))
+		asserter.Equal(http.StatusOK, rec.Code)
+		asserter.Contains(rec.Body.String(), PathOpenAPI)
+	})
+}
@@ -0,0 +1,191 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/naughtygopher/errors"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/prashantkr001/template-go/internal/item"
+)
+
+const contentTypeProblem = "application/problem+json"
+
+// Error codes of the problems, they are stable & meant for clients to branch on
+const (
+	CodeInternal            = "internal"
+	CodeValidation          = "validation"
+	CodeInvalidInput        = "invalid_input"
+	CodeDuplicate           = "duplicate"
+	CodeUnauthenticated     = "unauthenticated"
+	CodeUnauthorized        = "unauthorized"
+	CodeEmpty               = "empty"
+	CodeNotFound            = "not_found"
+	CodeMaximumAttempts     = "maximum_attempts"
+	CodeSubscriptionExpired = "subscription_expired"
+	CodeDependencyTimedout  = "dependency_timedout"
+	CodeNotImplemented      = "not_implemented"
+	CodeTimedout            = "timedout"
+	CodeCancelled           = "cancelled"
+	CodeBodyTooLarge        = "body_too_large"
+)
+
+// problem is an RFC 7807 problem details response
+type problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Code, TraceID & Errors are extension members. Code is derived from the type of the error, and
+	// Errors has all the field errors of a validation.
+	Code    string            `json:"code"`
+	TraceID string            `json:"traceId,omitempty"`
+	Errors  []item.FieldError `json:"errors,omitempty"`
+}
+
+func (ht *HTTP) newProblem(r *http.Request, err error) *problem {
+	status, message, _ := errors.HTTPStatusCodeMessage(err)
+	code := errorCode(err)
+	// the error types have no status for large bodies
+	maxErr := new(http.MaxBytesError)
+	if errors.As(err, &maxErr) {
+		status = http.StatusRequestEntityTooLarge
+		code = CodeBodyTooLarge
+	}
+	prob := &problem{
+		Type:     ht.problemTypeBaseURI + code,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   message,
+		Instance: r.URL.Path,
+		Code:     code,
+	}
+	if ht.problemTypeBaseURI == "" {
+		prob.Type = "about:blank"
+	}
+
+	if traceID := trace.SpanContextFromContext(r.Context()).TraceID(); traceID.IsValid() {
+		prob.TraceID = traceID.String()
+	}
+
+	verr := new(item.ValidationError)
+	if errors.As(err, &verr) {
+		prob.Errors = verr.Violations
+	}
+
+	return prob
+}
+
+// errorCode returns the code of the error based on its type, errors without a type are internal
+// unless they're of the context.
+func errorCode(err error) string {
+	switch errors.Type(err) {
+	case errors.TypeValidation:
+		return CodeValidation
+	case errors.TypeInputBody:
+		return CodeInvalidInput
+	case errors.TypeDuplicate:
+		return CodeDuplicate
+	case errors.TypeUnauthenticated:
+		return CodeUnauthenticated
+	case errors.TypeUnauthorized:
+		return CodeUnauthorized
+	case errors.TypeEmpty:
+		return CodeEmpty
+	case errors.TypeNotFound:
+		return CodeNotFound
+	case errors.TypeMaximumAttempts:
+		return CodeMaximumAttempts
+	case errors.TypeSubscriptionExpired:
+		return CodeSubscriptionExpired
+	case errors.TypeDownstreamDependencyTimedout:
+		return CodeDependencyTimedout
+	case errors.TypeNotImplemented:
+		return CodeNotImplemented
+	case errors.TypeContextTimedout:
+		return CodeTimedout
+	case errors.TypeContextCancelled:
+		return CodeCancelled
+	case errors.TypeInternal:
+		return CodeInternal
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeTimedout
+	case errors.Is(err, context.Canceled):
+		return CodeCancelled
+	}
+	return CodeInternal
+}
+
+func writeProblem(w http.ResponseWriter, prob *problem) error {
+	pbytes, err := json.Marshal(prob)
+	if err != nil {
+		return err //nolint:wrapcheck // the caller falls back to plain text
+	}
+
+	w.Header().Set("Content-Type", contentTypeProblem)
+	w.WriteHeader(prob.Status)
+	_, _ = w.Write(pbytes)
+	return nil
+}
+
+// prefersPlainText reports if the Accept header prefers text/plain over JSON. JSON is preferred if
+// both are equally acceptable, or if there's no Accept header.
+func prefersPlainText(accept string) bool {
+	if strings.TrimSpace(accept) == "" {
+		return false
+	}
+
+	jsonQ := acceptQuality(accept, contentTypeProblem, "application/json")
+	textQ := acceptQuality(accept, "text/plain")
+	return textQ > jsonQ
+}
+
+// acceptQuality returns the highest quality of the media types in the Accept header. The most
+// specific media range matching a media type decides its quality, e.g. "text/plain" over "text/*".
+func acceptQuality(accept string, mediaTypes ...string) float64 {
+	best := 0.0
+	for _, mediaType := range mediaTypes {
+		mainType, _, _ := strings.Cut(mediaType, "/")
+		quality, specificity := 0.0, -1
+		for _, mediaRange := range strings.Split(accept, ",") {
+			rangeType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err != nil {
+				continue
+			}
+
+			rangeSpecificity := -1
+			switch rangeType {
+			case mediaType:
+				rangeSpecificity = 2
+			case mainType + "/*":
+				rangeSpecificity = 1
+			case "*/*":
+				rangeSpecificity = 0
+			}
+			if rangeSpecificity <= specificity {
+				continue
+			}
+
+			specificity = rangeSpecificity
+			quality = 1
+			if qvalue, ok := params["q"]; ok {
+				quality, err = strconv.ParseFloat(qvalue, 64)
+				if err != nil {
+					quality = 0
+				}
+			}
+		}
+		best = max(best, quality)
+	}
+
+	return best
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/naughtygopher/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/prashantkr001/template-go/internal/item"
+)
+
+func TestErrorHandler(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+
+	ht, err := New(nil, nil, &Config{ProblemTypeBaseURI: "urn:test:problem:", ClientErrorLogLevel: "warn"})
+	requirer.NoError(err)
+
+	serve := func(handlerErr error, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+		spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+		req = req.WithContext(trace.ContextWithSpanContext(req.Context(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID,
+			SpanID:  spanID,
+		})))
+
+		rec := httptest.NewRecorder()
+		ht.ErrorHandler(func(http.ResponseWriter, *http.Request) error {
+			return handlerErr
+		})(rec, req)
+		return rec
+	}
+
+	t.Run("errors are problem details", func(_ *testing.T) {
+		rec := serve(errors.Wrap(item.ErrNotFound, "item 1"), "")
+		asserter.Equal(http.StatusNotFound, rec.Code)
+		asserter.Equal(contentTypeProblem, rec.Header().Get("Content-Type"))
+
+		prob := problem{}
+		requirer.NoError(json.Unmarshal(rec.Body.Bytes(), &prob))
+		asserter.Equal(problem{
+			Type:     "urn:test:problem:not_found",
+			Title:    "Not Found",
+			Status:   http.StatusNotFound,
+			Detail:   "item 1: Item not found",
+			Instance: "/items/1",
+			Code:     CodeNotFound,
+			TraceID:  "4bf92f3577b34da6a3ce929d0e0e4736",
+		}, prob)
+	})
+
+	t.Run("validation problems have the field errors", func(_ *testing.T) {
+		rec := serve((&item.Item{ID: 0, Name: "Box"}).Validate(nil), contentTypeProblem)
+		asserter.Equal(http.StatusUnprocessableEntity, rec.Code)
+
+		prob := problem{}
+		requirer.NoError(json.Unmarshal(rec.Body.Bytes(), &prob))
+		asserter.Equal(CodeValidation, prob.Code)
+		requirer.NotEmpty(prob.Errors)
+		asserter.Equal("id", prob.Errors[0].Field)
+	})
+
+	t.Run("plain text if preferred by the client", func(_ *testing.T) {
+		rec := serve(errors.Duplicate("item exists"), "text/plain, application/json;q=0.5")
+		asserter.Equal(http.StatusConflict, rec.Code)
+		asserter.Equal("text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+		asserter.Equal("item exists", rec.Body.String())
+	})
+
+	t.Run("untyped errors are internal", func(_ *testing.T) {
+		asserter.Equal(CodeInternal, errorCode(errors.New("failed")))
+		asserter.Equal(CodeCancelled, errorCode(errors.Wrap(context.Canceled, "request")))
+		asserter.Equal(CodeInvalidInput, errorCode(errors.InputBody("invalid JSON")))
+	})
+
+	t.Run("invalid client error log level", func(_ *testing.T) {
+		_, nerr := New(nil, nil, &Config{ClientErrorLogLevel: "loud"})
+		asserter.Error(nerr)
+	})
+}
+
+func TestPrefersPlainText(t *testing.T) {
+	asserter := assert.New(t)
+
+	for accept, expected := range map[string]bool{
+		"":                               false,
+		"*/*":                            false,
+		"text/plain":                     true,
+		"text/*":                         true,
+		"application/json, text/plain":   false,
+		"text/plain, */*;q=0.1":          true,
+		"application/*;q=0.2, text/html": false,
+		"text/plain;q=0.5, application/problem+json": false,
+		"application/json;q=0, text/*;q=0.1":         true,
+	} {
+		asserter.Equal(expected, prefersPlainText(accept), accept)
+	}
+}
@@ -0,0 +1,276 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/go-chi/chi/v5"
+	"github.com/naughtygopher/errors"
+
+	"github.com/prashantkr001/template-go/internal/item"
+)
+
+const defaultMaxBodyBytes = 1 << 20
+
+var (
+	ErrUnsupportedContentType = errors.InputBody("request body should be JSON, with Content-Type application/json")
+	ErrInvalidJSON            = errors.InputBody("request body should be a single valid JSON value")
+)
+
+// requestValidator validates the requests against the operations of the OpenAPI document
+type requestValidator struct {
+	// routes are the operations of the document, by method & path (the same as the chi route pattern)
+	routes       map[string]*routers.Route
+	maxBodyBytes int64
+	options      *openapi3filter.Options
+}
+
+func newRequestValidator(doc *openapi3.T, maxBodyBytes int64) *requestValidator {
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = defaultMaxBodyBytes
+	}
+
+	rv := &requestValidator{
+		routes:       make(map[string]*routers.Route),
+		maxBodyBytes: maxBodyBytes,
+		options: &openapi3filter.Options{
+			MultiError:          true,
+			SkipSettingDefaults: true,
+			// authentication is not part of the contract validation
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		},
+	}
+	for path, pathItem := range doc.Paths.Map() {
+		for method, op := range pathItem.Operations() {
+			rv.routes[method+" "+path] = &routers.Route{
+				Spec:      doc,
+				Path:      path,
+				PathItem:  pathItem,
+				Method:    method,
+				Operation: op,
+			}
+		}
+	}
+
+	return rv
+}
+
+// validationMiddleware validates the path, query, headers & JSON body of the requests against their
+// operation in the OpenAPI document, before the handler is called. It should be used inline with
+// the routes (i.e. after routing), since the operation is looked up using the chi route pattern.
+// Requests of routes which are not in the document are not validated.
+func (ht *HTTP) validationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		err := ht.validator.validate(w, req)
+		if err != nil {
+			ht.ErrorHandler(func(http.ResponseWriter, *http.Request) error {
+				return err
+			})(w, req)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+func (rv *requestValidator) validate(w http.ResponseWriter, req *http.Request) error {
+	rctx := chi.RouteContext(req.Context())
+	if rctx == nil {
+		return nil
+	}
+	route := rv.routes[req.Method+" "+rctx.RoutePattern()]
+	if route == nil {
+		return nil
+	}
+
+	err := rv.readBody(w, req, route.Operation)
+	if err != nil {
+		return err
+	}
+
+	pathParams := make(map[string]string, len(rctx.URLParams.Keys))
+	for i, key := range rctx.URLParams.Keys {
+		pathParams[key] = rctx.URLParams.Values[i]
+	}
+
+	err = openapi3filter.ValidateRequest(req.Context(), &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route:      route,
+		Options:    rv.options,
+	})
+	if err != nil {
+		return requestValidationErr(err)
+	}
+
+	return nil
+}
+
+// readBody reads the body (if any) within the size limit, and replaces it so that it can be read again.
+// The body is checked to be a single JSON value, since the decoders ignore any trailing data.
+func (rv *requestValidator) readBody(w http.ResponseWriter, req *http.Request, op *openapi3.Operation) error {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+
+	payload, err := io.ReadAll(http.MaxBytesReader(w, req.Body, rv.maxBodyBytes))
+	if err != nil {
+		maxErr := new(http.MaxBytesError)
+		if errors.As(err, &maxErr) {
+			return errors.InputBodyErrf(maxErr, "request body should be at most %d bytes", maxErr.Limit)
+		}
+		return errors.InputBodyErr(err, "failed to read request body")
+	}
+	req.Body = io.NopCloser(bytes.NewReader(payload))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(payload)), nil
+	}
+
+	if len(payload) == 0 || op.RequestBody == nil {
+		return nil
+	}
+
+	// requests without a Content-Type are assumed to be JSON
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if op.RequestBody.Value.Content.Get(req.Header.Get("Content-Type")) == nil {
+		return ErrUnsupportedContentType
+	}
+	if !json.Valid(payload) {
+		return ErrInvalidJSON
+	}
+
+	return nil
+}
+
+// requestValidationErr returns an input error with the violations of all the validation errors as
+// field errors. Fields are prefixed with the location, e.g. "query.limit", "header.If-Match" or
+// "body.tags[1]".
+func requestValidationErr(err error) error {
+	fields := fieldErrors(err)
+	if len(fields) == 0 {
+		return errors.InputBodyErr(err, "request is invalid")
+	}
+
+	verr := &item.ValidationError{Violations: fields}
+	return errors.InputBodyErr(verr, verr.Error())
+}
+
+func fieldErrors(err error) []item.FieldError {
+	// the errors of the parameters & body are also multi errors, hence not errors.As
+	merr, ok := err.(openapi3.MultiError) //nolint:errorlint // see above
+	if ok {
+		fields := []item.FieldError{}
+		for _, err := range merr {
+			fields = append(fields, fieldErrors(err)...)
+		}
+		return fields
+	}
+
+	rerr := new(openapi3filter.RequestError)
+	if !errors.As(err, &rerr) {
+		return nil
+	}
+
+	switch {
+	case rerr.Parameter != nil:
+		return violationsOf(rerr.Err, rerr.Parameter.In+"."+rerr.Parameter.Name, rerr.Reason)
+	case rerr.RequestBody != nil:
+		return violationsOf(rerr.Err, "body", rerr.Reason)
+	}
+
+	return nil
+}
+
+func violationsOf(err error, field, reason string) []item.FieldError {
+	// the schema errors of oneOf wrap the errors of every schema, which are not reported separately
+	switch verr := err.(type) { //nolint:errorlint // see above
+	case openapi3.MultiError:
+		fields := []item.FieldError{}
+		for _, err := range verr {
+			fields = append(fields, violationsOf(err, field, reason)...)
+		}
+		return fields
+	case *openapi3.SchemaError:
+		return []item.FieldError{schemaViolation(verr, field)}
+	}
+
+	perr := new(openapi3filter.ParseError)
+	switch {
+	case errors.As(err, &perr):
+		return []item.FieldError{{Field: field, Code: item.CodeInvalidType, Message: parseErrorMessage(perr)}}
+	case errors.Is(err, openapi3filter.ErrInvalidRequired):
+		return []item.FieldError{{Field: field, Code: item.CodeRequired, Message: "is required"}}
+	}
+
+	if reason == "" && err != nil {
+		reason = err.Error()
+	}
+	return []item.FieldError{{Field: field, Code: item.CodeInvalid, Message: reason}}
+}
+
+// schemaCodes are the codes of the field errors, by the schema field violated
+var schemaCodes = map[string]string{
+	"type":       item.CodeInvalidType,
+	"oneOf":      item.CodeInvalidType,
+	"format":     item.CodeInvalidType,
+	"required":   item.CodeRequired,
+	"properties": item.CodeNotAllowed,
+	"enum":       item.CodeNotAllowed,
+	"minimum":    item.CodeOutOfRange,
+	"maximum":    item.CodeOutOfRange,
+	"minLength":  item.CodeTooShort,
+	"maxLength":  item.CodeTooLong,
+	"maxItems":   item.CodeTooMany,
+	"pattern":    item.CodeInvalidChars,
+}
+
+func schemaViolation(serr *openapi3.SchemaError, field string) item.FieldError {
+	path := serr.JSONPointer()
+	if serr.SchemaField == "properties" {
+		// the path of unknown properties is of the object, the property is only in the reason
+		property := ""
+		_, err := fmt.Sscanf(serr.Reason, "property %q is unsupported", &property)
+		if err == nil {
+			path = append(path, property)
+		}
+	}
+
+	code, ok := schemaCodes[serr.SchemaField]
+	if !ok {
+		code = item.CodeInvalid
+	}
+
+	return item.FieldError{Field: field + jsonPath(path), Code: code, Message: serr.Reason}
+}
+
+// jsonPath converts a JSON pointer to the path format of field errors, e.g. ["tags", "1"] to ".tags[1]"
+func jsonPath(pointer []string) string {
+	path := strings.Builder{}
+	for _, segment := range pointer {
+		if _, err := strconv.Atoi(segment); err == nil {
+			path.WriteString("[" + segment + "]")
+			continue
+		}
+		path.WriteString("." + segment)
+	}
+	return path.String()
+}
+
+func parseErrorMessage(perr *openapi3filter.ParseError) string {
+	if perr.Reason != "" {
+		return perr.Reason
+	}
+	if perr.Cause != nil {
+		return perr.Cause.Error()
+	}
+	return "is invalid"
+}
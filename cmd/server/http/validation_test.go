@@ -0,0 +1,135 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prashantkr001/template-go/internal/api"
+	"github.com/prashantkr001/template-go/internal/item"
+)
+
+func TestValidationMiddleware(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+
+	store, err := item.NewMemoryPersistentStore()
+	requirer.NoError(err)
+	svc, err := item.NewService(store, nil, nil, nil, &item.Config{UseOutbox: true})
+	requirer.NoError(err)
+	ht, err := New(api.NewService(svc, nil, nil), nil, &Config{MaxBodyBytes: 512})
+	requirer.NoError(err)
+
+	serve := func(method, target, body string, headers map[string]string) (*httptest.ResponseRecorder, problem) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		rec := httptest.NewRecorder()
+		ht.server.Handler.ServeHTTP(rec, req)
+
+		prob := problem{}
+		if rec.Header().Get("Content-Type") == contentTypeProblem {
+			requirer.NoError(json.Unmarshal(rec.Body.Bytes(), &prob))
+		}
+		return rec, prob
+	}
+	jsonHeaders := map[string]string{"Content-Type": "application/json"}
+
+	t.Run("valid requests are handled", func(_ *testing.T) {
+		rec, _ := serve(http.MethodPost, "/items", `{"id":1,"name":"Box","attributes":{"weight":2}}`, jsonHeaders)
+		asserter.Equal(http.StatusOK, rec.Code, rec.Body.String())
+
+		rec, _ = serve(http.MethodGet, "/items/1?include_deleted=true", "", nil)
+		asserter.Equal(http.StatusOK, rec.Code, rec.Body.String())
+
+		// JSON is assumed if there's no Content-Type
+		rec, _ = serve(http.MethodPatch, "/items/1", `{"name":"Jar"}`, nil)
+		asserter.Equal(http.StatusOK, rec.Code, rec.Body.String())
+	})
+
+	t.Run("invalid query params", func(_ *testing.T) {
+		rec, prob := serve(http.MethodGet, "/items?limit=haha&sort=size", "", nil)
+		asserter.Equal(http.StatusBadRequest, rec.Code)
+		asserter.Equal(CodeInvalidInput, prob.Code)
+		requirer.Len(prob.Errors, 2)
+		asserter.Equal("query.limit", prob.Errors[0].Field)
+		asserter.Equal(item.CodeInvalidType, prob.Errors[0].Code)
+		asserter.Equal("query.sort", prob.Errors[1].Field)
+		asserter.Contains(prob.Detail, "query.limit")
+	})
+
+	t.Run("invalid path params", func(_ *testing.T) {
+		rec, prob := serve(http.MethodGet, "/items/0", "", nil)
+		asserter.Equal(http.StatusBadRequest, rec.Code)
+		requirer.Len(prob.Errors, 1)
+		asserter.Equal("path.id", prob.Errors[0].Field)
+		asserter.Equal(item.CodeOutOfRange, prob.Errors[0].Code)
+	})
+
+	t.Run("invalid headers", func(_ *testing.T) {
+		rec, prob := serve(http.MethodPost, "/items", `{"id":2,"name":"Box"}`, map[string]string{
+			HeaderIdempotencyKey: strings.Repeat("k", 256),
+		})
+		asserter.Equal(http.StatusBadRequest, rec.Code)
+		requirer.Len(prob.Errors, 1)
+		asserter.Equal("header."+HeaderIdempotencyKey, prob.Errors[0].Field)
+		asserter.Equal(item.CodeTooLong, prob.Errors[0].Code)
+	})
+
+	t.Run("unknown & invalid fields in the body", func(_ *testing.T) {
+		rec, prob := serve(
+			http.MethodPost,
+			"/items",
+			`{"id":"2","name":"Box","colour":"red","tags":["a",1],"attributes":{"weight":[1]}}`,
+			jsonHeaders,
+		)
+		asserter.Equal(http.StatusBadRequest, rec.Code)
+
+		fields := map[string]string{}
+		for _, ferr := range prob.Errors {
+			fields[ferr.Field] = ferr.Code
+		}
+		asserter.Equal(map[string]string{
+			"body.id":                item.CodeInvalidType,
+			"body.colour":            item.CodeNotAllowed,
+			"body.tags[1]":           item.CodeInvalidType,
+			"body.attributes.weight": item.CodeInvalidType,
+		}, fields)
+	})
+
+	t.Run("invalid JSON", func(_ *testing.T) {
+		rec, prob := serve(http.MethodPost, "/items", `{"id":3,"name":"Box"} trailing`, jsonHeaders)
+		asserter.Equal(http.StatusBadRequest, rec.Code)
+		asserter.Equal("request body should be a single valid JSON value", prob.Detail)
+	})
+
+	t.Run("missing body", func(_ *testing.T) {
+		rec, prob := serve(http.MethodPut, "/items/1", "", jsonHeaders)
+		asserter.Equal(http.StatusBadRequest, rec.Code)
+		requirer.Len(prob.Errors, 1)
+		asserter.Equal("body", prob.Errors[0].Field)
+		asserter.Equal(item.CodeRequired, prob.Errors[0].Code)
+	})
+
+	t.Run("unsupported content type", func(_ *testing.T) {
+		rec, prob := serve(http.MethodPost, "/items", `id=4`, map[string]string{
+			"Content-Type": "application/x-www-form-urlencoded",
+		})
+		asserter.Equal(http.StatusBadRequest, rec.Code)
+		asserter.Equal("request body should be JSON, with Content-Type application/json", prob.Detail)
+	})
+
+	t.Run("body too large", func(_ *testing.T) {
+		body := `{"id":5,"name":"` + strings.Repeat("a", 512) + `"}`
+		rec, prob := serve(http.MethodPost, "/items", body, jsonHeaders)
+		asserter.Equal(http.StatusRequestEntityTooLarge, rec.Code)
+		asserter.Equal(CodeBodyTooLarge, prob.Code)
+		asserter.Equal("request body should be at most 512 bytes", prob.Detail)
+	})
+}
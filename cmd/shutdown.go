@@ -2,18 +2,22 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"net/http"
 	"sync"
 	"time"
 
 	"github.com/naughtygopher/proberesponder"
+	"github.com/redis/go-redis/v9"
 	"go.mongodb.org/mongo-driver/mongo"
 
 	"github.com/prashantkr001/template-go/cmd/server/grpc"
 	xhttp "github.com/prashantkr001/template-go/cmd/server/http"
 	kafkaSubs "github.com/prashantkr001/template-go/cmd/subscriber/kafka"
+	"github.com/prashantkr001/template-go/internal/item"
 	"github.com/prashantkr001/template-go/internal/pkg/apm"
+	"github.com/prashantkr001/template-go/internal/pkg/kafka"
 	"github.com/prashantkr001/template-go/internal/pkg/logger"
 )
 
@@ -42,7 +46,11 @@ func shutdown(
 	httpServer *xhttp.HTTP,
 	grpcServer *grpc.GRPC,
 	ksub *kafkaSubs.Kafka,
-	mongoCli *mongo.Client,
+	itemService *item.Service,
+	outboxRelay *item.OutboxRelay,
+	purger *item.Purger,
+	kafkaCli *kafka.Kafka,
+	stores *storeClients,
 	apmHandler *apm.APM,
 ) {
 	// the time should be decided based on the K8s grace period allowed for shutdown
@@ -69,7 +77,7 @@ func shutdown(
 	// after all the APIs of the application are shutdown (e.g. HTTP, gRPC, Pubsub listener etc.)
 	// we should close connections to dependencies like database, cache etc.
 	// This should only be done after the APIs are shutdown completely
-	shutdownDependencies(ctx, wgroup, pResp, mongoCli, apmHandler)
+	shutdownDependencies(ctx, wgroup, pResp, itemService, outboxRelay, purger, kafkaCli, stores, apmHandler)
 
 	wgroup.Wait()
 }
@@ -137,25 +145,88 @@ func shutdownDependencies(
 	ctx context.Context,
 	wgroup *sync.WaitGroup,
 	pResp *proberesponder.ProbeResponder,
-	mongoCli *mongo.Client,
+	itemService *item.Service,
+	outboxRelay *item.OutboxRelay,
+	purger *item.Purger,
+	kafkaCli *kafka.Kafka,
+	stores *storeClients,
 	apmHandler *apm.APM,
 ) {
+	// the events pending in the publish queue should be published before closing Kafka
+	pResp.AppendHealthResponse(
+		"shutdown/item-publisher",
+		fmt.Sprintf("initiated %s", time.Now().Format(time.RFC3339)),
+	)
+	err := itemService.Drain(ctx)
+	if err != nil {
+		logger.ErrWithStacktrace(err)
+	}
+	pResp.AppendHealthResponse(
+		"shutdown/item-publisher",
+		fmt.Sprintf("completed %s", time.Now().Format(time.RFC3339)),
+	)
+
+	// the outbox relay depends on both MongoDB & Kafka, hence it is drained before closing either
+	if outboxRelay != nil {
+		pResp.AppendHealthResponse(
+			"shutdown/outbox-relay",
+			fmt.Sprintf("initiated %s", time.Now().Format(time.RFC3339)),
+		)
+		err = outboxRelay.Shutdown(ctx)
+		if err != nil {
+			logger.ErrWithStacktrace(err)
+		}
+		pResp.AppendHealthResponse(
+			"shutdown/outbox-relay",
+			fmt.Sprintf("completed %s", time.Now().Format(time.RFC3339)),
+		)
+	}
+
+	// the purger depends only on the store, hence it is stopped before closing the store clients
+	if purger != nil {
+		pResp.AppendHealthResponse(
+			"shutdown/item-purger",
+			fmt.Sprintf("initiated %s", time.Now().Format(time.RFC3339)),
+		)
+		err = purger.Shutdown(ctx)
+		if err != nil {
+			logger.ErrWithStacktrace(err)
+		}
+		pResp.AppendHealthResponse(
+			"shutdown/item-purger",
+			fmt.Sprintf("completed %s", time.Now().Format(time.RFC3339)),
+		)
+	}
+
 	wgroup.Add(1)
 	go func() {
 		defer func() {
 			wgroup.Done()
 			pResp.AppendHealthResponse(
-				"shutdown/mongodb-driver",
+				"shutdown/kafka-client",
 				fmt.Sprintf("completed %s", time.Now().Format(time.RFC3339)),
 			)
 		}()
 		pResp.AppendHealthResponse(
-			"shutdown/mongodb-driver",
+			"shutdown/kafka-client",
 			fmt.Sprintf("initiated %s", time.Now().Format(time.RFC3339)),
 		)
-		_ = mongoCli.Disconnect(ctx)
+		err := kafkaCli.Shutdown(ctx)
+		if err != nil {
+			logger.ErrWithStacktrace(err)
+		}
 	}()
 
+	if stores.mongo != nil {
+		shutdownMongoDB(ctx, wgroup, pResp, stores.mongo)
+	}
+	if stores.postgres != nil {
+		shutdownPostgres(wgroup, pResp, stores.postgres)
+	}
+	if stores.redis != nil {
+		shutdownRedis(wgroup, pResp, stores.redis)
+	}
+
 	wgroup.Add(1)
 	go func() {
 		defer func() {
@@ -175,3 +246,71 @@ func shutdownDependencies(
 		}
 	}()
 }
+
+func shutdownMongoDB(
+	ctx context.Context,
+	wgroup *sync.WaitGroup,
+	pResp *proberesponder.ProbeResponder,
+	mongoCli *mongo.Client,
+) {
+	wgroup.Add(1)
+	go func() {
+		defer func() {
+			wgroup.Done()
+			pResp.AppendHealthResponse(
+				"shutdown/mongodb-driver",
+				fmt.Sprintf("completed %s", time.Now().Format(time.RFC3339)),
+			)
+		}()
+		pResp.AppendHealthResponse(
+			"shutdown/mongodb-driver",
+			fmt.Sprintf("initiated %s", time.Now().Format(time.RFC3339)),
+		)
+		_ = mongoCli.Disconnect(ctx)
+	}()
+}
+
+func shutdownPostgres(
+	wgroup *sync.WaitGroup,
+	pResp *proberesponder.ProbeResponder,
+	db *sql.DB,
+) {
+	wgroup.Add(1)
+	go func() {
+		defer func() {
+			wgroup.Done()
+			pResp.AppendHealthResponse(
+				"shutdown/postgres-driver",
+				fmt.Sprintf("completed %s", time.Now().Format(time.RFC3339)),
+			)
+		}()
+		pResp.AppendHealthResponse(
+			"shutdown/postgres-driver",
+			fmt.Sprintf("initiated %s", time.Now().Format(time.RFC3339)),
+		)
+		// Close waits for the ongoing queries to complete
+		_ = db.Close()
+	}()
+}
+
+func shutdownRedis(
+	wgroup *sync.WaitGroup,
+	pResp *proberesponder.ProbeResponder,
+	client *redis.Client,
+) {
+	wgroup.Add(1)
+	go func() {
+		defer func() {
+			wgroup.Done()
+			pResp.AppendHealthResponse(
+				"shutdown/redis-client",
+				fmt.Sprintf("completed %s", time.Now().Format(time.RFC3339)),
+			)
+		}()
+		pResp.AppendHealthResponse(
+			"shutdown/redis-client",
+			fmt.Sprintf("initiated %s", time.Now().Format(time.RFC3339)),
+		)
+		_ = client.Close()
+	}()
+}
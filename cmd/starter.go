@@ -9,7 +9,6 @@ import (
 
 	"github.com/naughtygopher/proberesponder"
 	proberespHTTP "github.com/naughtygopher/proberesponder/extensions/http"
-	"go.mongodb.org/mongo-driver/mongo"
 
 	"github.com/prashantkr001/template-go/cmd/server/grpc"
 	xhttp "github.com/prashantkr001/template-go/cmd/server/http"
@@ -17,18 +16,26 @@ import (
 	"github.com/prashantkr001/template-go/internal/api"
 	"github.com/prashantkr001/template-go/internal/config"
 	"github.com/prashantkr001/template-go/internal/item"
+	"github.com/prashantkr001/template-go/internal/pkg/auth"
 	"github.com/prashantkr001/template-go/internal/pkg/kafka"
 	"github.com/prashantkr001/template-go/internal/pkg/logger"
+	"github.com/prashantkr001/template-go/internal/pkg/resilience"
 )
 
+const healthKeyPublisherBreaker = "kafka/publisher-breaker"
+
 func startItemHTTPServer(
 	ctx context.Context,
 	pResp *proberesponder.ProbeResponder,
 	fatalErr chan<- error,
 	apis *api.API,
+	authn auth.Authenticator,
 	cfg *xhttp.Config,
-) (*xhttp.HTTP, error) { //nolint:unparam,nolintlint
-	itemServer := xhttp.New(apis, cfg)
+) (*xhttp.HTTP, error) {
+	itemServer, err := xhttp.New(apis, authn, cfg)
+	if err != nil {
+		return nil, err
+	}
 	go func() {
 		defer logger.InfoCtx(ctx, fmt.Sprintf("[http] %s:%d shutdown complete", cfg.Host, cfg.Port))
 		logger.InfoCtx(ctx, fmt.Sprintf("[http] listening on %s:%d", cfg.Host, cfg.Port))
@@ -47,9 +54,10 @@ func startItemGrpcServer(
 	pResp *proberesponder.ProbeResponder,
 	fatalErr chan<- error,
 	apis *api.API,
+	authn auth.Authenticator,
 	cfg *grpc.Config,
 ) (*grpc.GRPC, error) { //nolint:unparam,nolintlint
-	itemServer := grpc.New(apis, cfg)
+	itemServer := grpc.New(apis, authn, cfg)
 	go func() {
 		defer logger.InfoCtx(ctx, fmt.Sprintf("[grpc] %s:%d shutdown complete", cfg.Host, cfg.Port))
 		logger.InfoCtx(ctx, fmt.Sprintf("[grpc] listening on %s:%d", cfg.Host, cfg.Port))
@@ -106,6 +114,44 @@ func startItemSubscriber(
 	return ksub, nil
 }
 
+func startOutboxRelay(
+	ctx context.Context,
+	pResp *proberesponder.ProbeResponder,
+	relay *item.OutboxRelay,
+) {
+	go func() {
+		defer logger.InfoCtx(ctx, "[item/outbox-relay] shutdown complete")
+		logger.InfoCtx(ctx, "[item/outbox-relay] relaying pending outbox records")
+		pResp.AppendHealthResponse(
+			"item/outbox-relay",
+			fmt.Sprintf("OK: %s", time.Now().Format(time.RFC3339)),
+		)
+		err := relay.Start(context.Background())
+		if err != nil {
+			logger.ErrWithStacktrace(err)
+		}
+	}()
+}
+
+func startPurger(
+	ctx context.Context,
+	pResp *proberesponder.ProbeResponder,
+	purger *item.Purger,
+) {
+	go func() {
+		defer logger.InfoCtx(ctx, "[item/purger] shutdown complete")
+		logger.InfoCtx(ctx, "[item/purger] purging deleted items past retention")
+		pResp.AppendHealthResponse(
+			"item/purger",
+			fmt.Sprintf("OK: %s", time.Now().Format(time.RFC3339)),
+		)
+		err := purger.Start(context.Background())
+		if err != nil {
+			logger.ErrWithStacktrace(err)
+		}
+	}()
+}
+
 func startServices(
 	ctx context.Context,
 	pResp *proberesponder.ProbeResponder,
@@ -113,6 +159,7 @@ func startServices(
 	cfg *config.Config,
 	kafkaClient *kafka.Kafka,
 	apiService *api.API,
+	authn auth.Authenticator,
 ) (ksub *kafkaSubs.Kafka, hserver *xhttp.HTTP, gserver *grpc.GRPC, err error) {
 	ksub, err = startItemSubscriber(
 		ctx,
@@ -120,7 +167,7 @@ func startServices(
 		fatalErr,
 		kafkaClient,
 		apiService,
-		&kafkaSubs.Config{TopicItemCreate: cfg.Kafka.Topics[0]},
+		&kafkaSubs.Config{TopicItemCreate: cfg.Kafka.Topics[0], Principal: subscriberPrincipal(cfg)},
 	)
 	if err != nil {
 		return nil, nil, nil, err
@@ -133,7 +180,7 @@ func startServices(
 		[]string{config.EnvDevelopment, config.EnvCI},
 		cfg.Environment,
 	)
-	hserver, err = startItemHTTPServer(ctx, pResp, fatalErr, apiService, &hConfig)
+	hserver, err = startItemHTTPServer(ctx, pResp, fatalErr, apiService, authn, &hConfig)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -143,7 +190,7 @@ func startServices(
 		[]string{config.EnvDevelopment, config.EnvCI},
 		cfg.Environment,
 	)
-	gserver, err = startItemGrpcServer(ctx, pResp, fatalErr, apiService, &gcfg)
+	gserver, err = startItemGrpcServer(ctx, pResp, fatalErr, apiService, authn, &gcfg)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -157,43 +204,151 @@ func start(
 	probestatus *proberesponder.ProbeResponder,
 	fatalErr chan<- error,
 ) (
-	mongoClient *mongo.Client,
+	stores *storeClients,
 	kafkaClient *kafka.Kafka,
 	hserver *xhttp.HTTP,
 	gserver *grpc.GRPC,
 	ksub *kafkaSubs.Kafka,
+	itemService *item.Service,
+	outboxRelay *item.OutboxRelay,
+	purger *item.Purger,
 ) {
 	err := initAPM(ctx, cfg)
 	if err != nil {
 		panic(err)
 	}
 
-	mongoClient, mongoDB, err := initializeMongoDB(ctx, cfg)
+	stores, itemPersistence, err := initItemStore(ctx, cfg)
 	if err != nil {
 		panic(err)
 	}
 
+	redisClient, itemCache, err := initItemCache(ctx, cfg)
+	if err != nil {
+		panic(err)
+	}
+	stores.redis = redisClient
+
 	kafkaClient, _, err = initKafka(ctx, cfg)
 	if err != nil {
 		panic(err)
 	}
 
-	itemPersistence, err := item.NewMongoPersistentStore(mongoDB)
+	kafkaPublisher, err := item.NewKafkaItemPublisher(kafkaClient, &item.Topics{
+		Created: cfg.Item.TopicCreated,
+		Updated: cfg.Item.TopicUpdated,
+		Deleted: cfg.Item.TopicDeleted,
+	}, cfg.Item.EventFormat)
+	if err != nil {
+		panic(err)
+	}
+
+	probestatus.AppendHealthResponse(healthKeyPublisherBreaker, resilience.BreakerClosed.String())
+	itemPublisher, err := item.NewResilientPublisher(kafkaPublisher, &item.ResilientPublisherConfig{
+		MaxAttempts: cfg.Publisher.MaxAttempts,
+		Backoff: resilience.Backoff{
+			Initial:    cfg.Publisher.BackoffInitial,
+			Max:        cfg.Publisher.BackoffMax,
+			Multiplier: cfg.Publisher.BackoffMultiplier,
+			Jitter:     cfg.Publisher.BackoffJitter,
+		},
+		Breaker: resilience.BreakerConfig{
+			FailureThreshold: cfg.Publisher.BreakerFailureThreshold,
+			OpenTimeout:      cfg.Publisher.BreakerOpenTimeout,
+			OnStateChange: func(_, to resilience.BreakerState) {
+				probestatus.AppendHealthResponse(
+					healthKeyPublisherBreaker,
+					fmt.Sprintf("%s: %s", to, time.Now().Format(time.RFC3339)),
+				)
+			},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	itemNamer, err := item.NewNamer(cfg.Item.NamingStrategy)
+	if err != nil {
+		panic(err)
+	}
+
+	itemRules, err := initItemRules(cfg)
 	if err != nil {
 		panic(err)
 	}
 
-	itemPublisher, err := item.NewKafkaItemPublisher(kafkaClient, "template-item-created")
+	itemService, err = item.NewService(
+		itemPersistence,
+		itemCache,
+		itemPublisher,
+		itemNamer,
+		&item.Config{
+			UseOutbox:              cfg.Outbox.Enabled,
+			PublishWorkers:         cfg.Item.PublishWorkers,
+			PublishQueueDepth:      cfg.Item.PublishQueueDepth,
+			PublishQueueFullPolicy: cfg.Item.PublishQueueFullPolicy,
+			PublishTimeout:         cfg.Item.PublishTimeout,
+			EventSource:            cfg.AppFullname(),
+			Limits:                 itemLimits(cfg),
+			Rules:                  itemRules,
+		},
+	)
 	if err != nil {
 		panic(err)
 	}
 
-	itemService, err := item.NewService(itemPersistence, itemPublisher)
+	if cfg.Outbox.Enabled {
+		outboxRelay, err = item.NewOutboxRelay(itemPersistence, itemPublisher, &item.OutboxRelayConfig{
+			PollInterval:   cfg.Outbox.PollInterval,
+			BatchSize:      cfg.Outbox.BatchSize,
+			PublishTimeout: cfg.Item.PublishTimeout,
+		})
+		if err != nil {
+			panic(err)
+		}
+		startOutboxRelay(ctx, probestatus, outboxRelay)
+	}
+
+	if cfg.Purger.Enabled {
+		purger, err = item.NewPurger(itemPersistence, &item.PurgerConfig{
+			Retention: cfg.Purger.Retention,
+			Interval:  cfg.Purger.Interval,
+			BatchSize: cfg.Purger.BatchSize,
+			OnProgress: func(progress item.PurgeProgress) {
+				status := fmt.Sprintf(
+					"OK: purged %d (total %d) %s",
+					progress.Purged,
+					progress.TotalPurged,
+					progress.At.Format(time.RFC3339),
+				)
+				if progress.Err != nil {
+					status = fmt.Sprintf("FAIL: %s %s", progress.Err.Error(), progress.At.Format(time.RFC3339))
+				}
+				probestatus.AppendHealthResponse("item/purger", status)
+			},
+		})
+		if err != nil {
+			panic(err)
+		}
+		startPurger(ctx, probestatus, purger)
+	}
+
+	idem, err := initIdempotency(ctx, cfg, stores)
 	if err != nil {
 		panic(err)
 	}
 
-	apiService := api.NewService(itemService)
+	authz, err := initAuthorizer(cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	apiService := api.NewService(itemService, idem, authz)
+
+	authn, err := initAuth(ctx, cfg)
+	if err != nil {
+		panic(err)
+	}
 
 	ksub, hserver, gserver, err = startServices(
 		ctx,
@@ -202,10 +357,11 @@ func start(
 		cfg,
 		kafkaClient,
 		apiService,
+		authn,
 	)
 	if err != nil {
 		panic(err)
 	}
 
-	return mongoClient, kafkaClient, hserver, gserver, ksub
+	return stores, kafkaClient, hserver, gserver, ksub, itemService, outboxRelay, purger
 }
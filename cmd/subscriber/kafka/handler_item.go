@@ -1,22 +1,52 @@
 package kafka
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"github.com/prashantkr001/template-go/internal/api"
 	"github.com/prashantkr001/template-go/internal/item"
+	"github.com/prashantkr001/template-go/internal/pkg/auth"
+	"github.com/prashantkr001/template-go/internal/pkg/cloudevents"
 	"github.com/prashantkr001/template-go/internal/pkg/logger"
 )
 
-func (kfk *Kafka) ItemCreate(ctx context.Context, payload []byte) error {
-	createItem := new(item.Item)
-	err := json.Unmarshal(payload, createItem)
+// ItemCreate accepts CloudEvents in both binary & structured modes, as well as the legacy raw JSON
+// of the item. For CloudEvents, the data should be the JSON of the item. If the data is a JSON array
+// of items, all of them are created in bulk. The topic is recorded as the actor of the items created,
+// and the items are created for the tenant of the item.KafkaHeaderTenant header (if any). The items are
+// created by the principal of the subscriber, or anonymously if there's none.
+func (kfk *Kafka) ItemCreate(ctx context.Context, record *kgo.Record) error {
+	ctx = item.WithActor(ctx, "kafka:"+record.Topic)
+	if kfk.principal != nil {
+		ctx = auth.WithPrincipal(ctx, kfk.principal)
+	}
+	if tenant := recordTenant(record); tenant != "" {
+		ctx = item.WithTenant(ctx, tenant)
+	}
+	payload, err := eventData(record)
 	if err != nil {
 		// log the error and move on, if `nack`-ed, app will receive the same message, and the error.
 		// Ending up in an infinite loop or Kafka backing off from delivering messages to the consumer
 		// group
+		logger.ErrWithStacktrace(fmt.Errorf("%q %w", string(record.Value), err))
+		return nil
+	}
+
+	if isJSONArray(payload) {
+		return kfk.itemCreateMany(ctx, payload)
+	}
+
+	createItem := new(item.Item)
+	err = json.Unmarshal(payload, createItem)
+	if err != nil {
 		logger.ErrWithStacktrace(fmt.Errorf("%q %w", string(payload), err))
 		return nil
 	}
@@ -29,6 +59,78 @@ func (kfk *Kafka) ItemCreate(ctx context.Context, payload []byte) error {
 		logger.Info(fmt.Sprintf("item with ID %d already exists", createItem.ID))
 		return nil
 	}
+	if unprocessable(err) {
+		logger.ErrWithStacktrace(err)
+		return nil
+	}
 
 	return err
 }
+
+func (kfk *Kafka) itemCreateMany(ctx context.Context, payload []byte) error {
+	items := []item.Item{}
+	err := json.Unmarshal(payload, &items)
+	if err != nil {
+		logger.ErrWithStacktrace(fmt.Errorf("%q %w", string(payload), err))
+		return nil
+	}
+
+	// the batch is split, so that a record of any size can be processed
+	for batch := range slices.Chunk(items, item.MaxCreateBatch) {
+		results, cerr := kfk.apiSvc.ItemCreateMany(ctx, batch)
+		if unprocessable(cerr) {
+			logger.ErrWithStacktrace(cerr)
+			return nil
+		}
+		if cerr != nil {
+			return cerr
+		}
+
+		for _, result := range results {
+			switch result.Status {
+			case item.CreateStatusCreated:
+			case item.CreateStatusDuplicate:
+				logger.Info(fmt.Sprintf("item with ID %d already exists", result.ID))
+			case item.CreateStatusInvalid, item.CreateStatusFailed:
+				logger.ErrWithStacktrace(fmt.Errorf("item with ID %d not created (%s): %s", result.ID, result.Status, result.Error))
+			}
+		}
+	}
+
+	return nil
+}
+
+// unprocessable reports if the error is of the record rather than of the call, i.e. retries would fail
+// the same way. The subscriber not having the permission required is fixed by the policy, not by retries.
+func unprocessable(err error) bool {
+	return errors.Is(err, item.ErrInvalidTenant) || errors.Is(err, api.ErrPermissionDenied)
+}
+
+// recordTenant returns the value of the last tenant header, or an empty string if there's none
+func recordTenant(record *kgo.Record) string {
+	tenant := ""
+	for _, header := range record.Headers {
+		if header.Key == item.KafkaHeaderTenant {
+			tenant = strings.TrimSpace(string(header.Value))
+		}
+	}
+	return tenant
+}
+
+func isJSONArray(payload []byte) bool {
+	trimmed := bytes.TrimSpace(payload)
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+// eventData returns the data of the CloudEvent if the record is one, or the record value as is
+func eventData(record *kgo.Record) ([]byte, error) {
+	cevent, _, err := cloudevents.FromRecord(record)
+	if errors.Is(err, cloudevents.ErrNotCloudEvent) {
+		return record.Value, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return cevent.Data, nil
+}
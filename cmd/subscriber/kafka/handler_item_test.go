@@ -0,0 +1,220 @@
+package kafka
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"github.com/prashantkr001/template-go/internal/api"
+	"github.com/prashantkr001/template-go/internal/item"
+	"github.com/prashantkr001/template-go/internal/pkg/auth"
+	"github.com/prashantkr001/template-go/internal/pkg/cloudevents"
+)
+
+const testTopic = "items"
+
+func newTestSubscriber(t *testing.T) (*Kafka, *item.Service) {
+	t.Helper()
+
+	store, err := item.NewMemoryPersistentStore()
+	require.NoError(t, err)
+	svc, err := item.NewService(store, nil, nil, nil, &item.Config{UseOutbox: true})
+	require.NoError(t, err)
+	kfk, err := NewService(nil, api.NewService(svc, nil, nil), &Config{TopicItemCreate: testTopic})
+	require.NoError(t, err)
+
+	return kfk, svc
+}
+
+// cloudEventRecord returns the record of a CloudEvent with the data, encoded as per the mode
+func cloudEventRecord(t *testing.T, mode cloudevents.Mode, data string) *kgo.Record {
+	t.Helper()
+
+	record := &kgo.Record{Topic: testTopic}
+	require.NoError(t, cloudevents.ToRecord(&cloudevents.Event{
+		ID:              "01J9Z3NDEKTSV4RRFFQ69G5FAV",
+		Source:          "tests",
+		Type:            "item.create",
+		DataContentType: cloudevents.ContentTypeJSON,
+		Data:            []byte(data),
+	}, mode, record))
+
+	return record
+}
+
+func TestItemCreate(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+
+	kfk, svc := newTestSubscriber(t)
+
+	id := 0
+	// itemJSON returns the JSON of the given number of new items, as an array if batch is true
+	itemJSON := func(count int, batch bool) (string, []int) {
+		ids := make([]int, 0, count)
+		raw := ""
+		for i := range count {
+			id++
+			ids = append(ids, id)
+			if i > 0 {
+				raw += ","
+			}
+			raw += fmt.Sprintf(`{"id":%d,"name":"Item %d"}`, id, id)
+		}
+		if batch {
+			raw = "[" + raw + "]"
+		}
+		return raw, ids
+	}
+
+	formats := map[string]func(data string) *kgo.Record{
+		"legacy raw JSON": func(data string) *kgo.Record {
+			return &kgo.Record{Topic: testTopic, Value: []byte(data)}
+		},
+		"cloudevents binary mode": func(data string) *kgo.Record {
+			return cloudEventRecord(t, cloudevents.ModeBinary, data)
+		},
+		"cloudevents structured mode": func(data string) *kgo.Record {
+			return cloudEventRecord(t, cloudevents.ModeStructured, data)
+		},
+	}
+
+	for format, newRecord := range formats {
+		for _, batch := range []bool{false, true} {
+			for _, tenant := range []string{"", "acme"} {
+				name := fmt.Sprintf("%s, batch: %t, tenant: '%s'", format, batch, tenant)
+				t.Run(name, func(_ *testing.T) {
+					count := 1
+					if batch {
+						count = 3
+					}
+					data, ids := itemJSON(count, batch)
+					record := newRecord(data)
+					if tenant != "" {
+						record.Headers = append(record.Headers, kgo.RecordHeader{
+							Key:   item.KafkaHeaderTenant,
+							Value: []byte(tenant),
+						})
+					}
+					requirer.NoError(kfk.ItemCreate(t.Context(), record))
+
+					ctx := t.Context()
+					if tenant != "" {
+						ctx = item.WithTenant(ctx, tenant)
+					}
+					for _, id := range ids {
+						created, err := svc.Get(ctx, id, false)
+						requirer.NoError(err, name)
+						asserter.Equal(item.Tenant(ctx), created.Tenant, name)
+
+						history, err := svc.History(ctx, id, item.HistoryQuery{})
+						requirer.NoError(err, name)
+						requirer.NotEmpty(history.Entries, name)
+						asserter.Equal("kafka:"+testTopic, history.Entries[0].Actor, name)
+					}
+
+					// the items are not created for the other tenants
+					other := item.WithTenant(t.Context(), "other")
+					_, err := svc.Get(other, ids[0], false)
+					asserter.ErrorIs(err, item.ErrNotFound, name)
+				})
+			}
+		}
+	}
+
+	t.Run("unprocessable records are skipped", func(_ *testing.T) {
+		data, ids := itemJSON(1, false)
+		invalidTenant := &kgo.Record{
+			Topic:   testTopic,
+			Value:   []byte(data),
+			Headers: []kgo.RecordHeader{{Key: item.KafkaHeaderTenant, Value: []byte("Not A Tenant")}},
+		}
+		incompleteEvent := &kgo.Record{
+			Topic:   testTopic,
+			Value:   []byte(data),
+			Headers: []kgo.RecordHeader{{Key: "ce_specversion", Value: []byte(cloudevents.SpecVersion)}},
+		}
+
+		for name, record := range map[string]*kgo.Record{
+			"invalid tenant":        invalidTenant,
+			"invalid JSON":          {Topic: testTopic, Value: []byte(`{"id":`)},
+			"invalid batch":         {Topic: testTopic, Value: []byte(`[{"id":"1"}]`)},
+			"incomplete cloudevent": incompleteEvent,
+		} {
+			// errors are logged, and the records are committed so they're not redelivered
+			asserter.NoError(kfk.ItemCreate(t.Context(), record), name)
+		}
+
+		_, err := svc.Get(t.Context(), ids[0], false)
+		asserter.ErrorIs(err, item.ErrNotFound)
+	})
+
+	t.Run("duplicate items are skipped", func(_ *testing.T) {
+		data, _ := itemJSON(1, false)
+		record := &kgo.Record{Topic: testTopic, Value: []byte(data)}
+		requirer.NoError(kfk.ItemCreate(t.Context(), record))
+		asserter.NoError(kfk.ItemCreate(t.Context(), record))
+	})
+}
+
+func TestItemCreateAuthorization(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+
+	store, err := item.NewMemoryPersistentStore()
+	requirer.NoError(err)
+	svc, err := item.NewService(store, nil, nil, nil, &item.Config{UseOutbox: true})
+	requirer.NoError(err)
+	authz, err := api.NewAuthorizer(&api.Policy{
+		Roles: map[string]api.Grant{"ingest": {Permissions: []string{api.PermissionItemCreate}}},
+	}, item.Limits{})
+	requirer.NoError(err)
+	apis := api.NewService(svc, nil, authz)
+
+	record := func(id int, tenant string) *kgo.Record {
+		return &kgo.Record{
+			Topic:   testTopic,
+			Value:   fmt.Appendf(nil, `{"id":%d,"name":"Item %d"}`, id, id),
+			Headers: []kgo.RecordHeader{{Key: item.KafkaHeaderTenant, Value: []byte(tenant)}},
+		}
+	}
+
+	t.Run("the items are created by the principal of the subscriber", func(_ *testing.T) {
+		kfk, kerr := NewService(nil, apis, &Config{
+			TopicItemCreate: testTopic,
+			Principal: &auth.Principal{
+				Subject: "items-ingest",
+				Method:  auth.MethodService,
+				Roles:   []string{"ingest"},
+				Tenants: []string{"acme"},
+			},
+		})
+		requirer.NoError(kerr)
+
+		requirer.NoError(kfk.ItemCreate(t.Context(), record(1, "acme")))
+		_, gerr := svc.Get(item.WithTenant(t.Context(), "acme"), 1, false)
+		asserter.NoError(gerr)
+
+		// denied records are logged & skipped, since retries would be denied as well
+		asserter.NoError(kfk.ItemCreate(t.Context(), record(2, "globex")))
+		_, gerr = svc.Get(item.WithTenant(t.Context(), "globex"), 2, false)
+		asserter.ErrorIs(gerr, item.ErrNotFound)
+	})
+
+	t.Run("anonymous subscriber without the permission", func(_ *testing.T) {
+		kfk, kerr := NewService(nil, apis, &Config{TopicItemCreate: testTopic})
+		requirer.NoError(kerr)
+
+		asserter.NoError(kfk.ItemCreate(t.Context(), record(3, item.DefaultTenant)))
+		batch := &kgo.Record{Topic: testTopic, Value: []byte(`[{"id":4,"name":"Jar"}]`)}
+		asserter.NoError(kfk.ItemCreate(t.Context(), batch))
+
+		for _, id := range []int{3, 4} {
+			_, gerr := svc.Get(t.Context(), id, false)
+			asserter.ErrorIs(gerr, item.ErrNotFound)
+		}
+	})
+}
@@ -11,12 +11,16 @@ import (
 	"github.com/twmb/franz-go/pkg/kgo"
 
 	"github.com/prashantkr001/template-go/internal/api"
+	"github.com/prashantkr001/template-go/internal/pkg/auth"
 	"github.com/prashantkr001/template-go/internal/pkg/kafka"
 	"github.com/prashantkr001/template-go/internal/pkg/logger"
 )
 
 type Config struct {
 	TopicItemCreate string
+	// Principal is of the API calls of the subscriber, they're anonymous if it's nil. It is authorized
+	// by the policy, similar to the principals of the HTTP & gRPC requests.
+	Principal *auth.Principal
 }
 type Kafka struct {
 	client *kafka.Kafka
@@ -26,8 +30,12 @@ type Kafka struct {
 	locker                 *sync.Mutex
 	receivedFirstMessageAt *time.Time
 	receivedLastMessageAt  *time.Time
+	// stopPolling is set when subscribing, and stopped is closed once the subscriber exits
+	stopPolling context.CancelFunc
+	stopped     chan struct{}
 
 	topicItemCreate string
+	principal       *auth.Principal
 }
 
 func NewService(kfk *kafka.Kafka, apiSvc *api.API, cfg *Config) (*Kafka, error) {
@@ -35,17 +43,38 @@ func NewService(kfk *kafka.Kafka, apiSvc *api.API, cfg *Config) (*Kafka, error)
 		client:          kfk,
 		apiSvc:          apiSvc,
 		locker:          &sync.Mutex{},
+		stopped:         make(chan struct{}),
 		topicItemCreate: cfg.TopicItemCreate,
+		principal:       cfg.Principal,
 	}
 
 	return kf, nil
 }
 
+// Shutdown stops consuming and waits for the records already fetched to be handled. It does not
+// close the Kafka client, since the client is shared with the publishers.
 func (kfk *Kafka) Shutdown(ctx context.Context) error {
 	if kfk == nil || kfk.client == nil {
 		return nil
 	}
-	return kfk.client.Shutdown(ctx)
+
+	kfk.client.PauseFetching()
+
+	kfk.locker.Lock()
+	stopPolling := kfk.stopPolling
+	kfk.locker.Unlock()
+	if stopPolling == nil {
+		// was never subscribed
+		return nil
+	}
+	stopPolling()
+
+	select {
+	case <-kfk.stopped:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "kafka subscriber did not stop")
+	}
 }
 
 func (kfk *Kafka) ReceivedFirstMessageAt() *time.Time {
@@ -73,8 +102,23 @@ func (kfk *Kafka) ReceivedLastMessageAt() *time.Time {
 }
 
 func (kfk *Kafka) Subscribe(ctx context.Context) error {
+	// a separate context for polling, so that records being handled are not cancelled on shutdown
+	pollCtx, cancel := context.WithCancel(ctx)
+	kfk.locker.Lock()
+	kfk.stopPolling = cancel
+	kfk.locker.Unlock()
+	defer func() {
+		cancel()
+		close(kfk.stopped)
+	}()
+
 	for {
-		fetches := kfk.client.PollFetches(ctx)
+		fetches := kfk.client.PollFetches(pollCtx)
+		if pollCtx.Err() != nil {
+			// shutdown initiated, records fetched (if any) are not committed, and would be redelivered
+			return nil
+		}
+
 		if errs := fetches.Errors(); len(errs) > 0 {
 			// All errors are retried internally when fetching, but non-retriable errors are
 			// returned from polls.
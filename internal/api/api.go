@@ -12,19 +12,32 @@ import (
 	"context"
 
 	"github.com/prashantkr001/template-go/internal/item"
+	"github.com/prashantkr001/template-go/internal/pkg/idempotency"
 )
 
 type itemService interface {
 	CreateIfNotExist(ctx context.Context, newItem item.Item) (*item.Item, error)
-	List(ctx context.Context, limit int) ([]item.Item, error)
+	CreateMany(ctx context.Context, items []item.Item) ([]item.CreateResult, error)
+	List(ctx context.Context, query item.ListQuery) (*item.ListResult, error)
+	Get(ctx context.Context, id int, includeDeleted bool) (*item.Item, error)
+	Update(ctx context.Context, it item.Item) (*item.Item, error)
+	Patch(ctx context.Context, id int, patch item.Patch) (*item.Item, error)
+	Delete(ctx context.Context, id int, version int64) error
+	Restore(ctx context.Context, id int, version int64) (*item.Item, error)
+	History(ctx context.Context, id int, query item.HistoryQuery) (*item.HistoryResult, error)
+	HistoryLabels(ctx context.Context, id int) (map[string]string, error)
 }
 
 // API struct holds all the initialized service structs of respective modules, which has
 // its API exposed.
 type API struct {
 	itemService itemService
+	// idempotency is optional, the requests with an idempotency key are processed again if it's nil
+	idempotency *idempotency.Idempotency
+	// authorizer is optional, all the calls are allowed if it's nil
+	authorizer *Authorizer
 }
 
-func NewService(itSvc itemService) *API {
-	return &API{itemService: itSvc}
+func NewService(itSvc itemService, idem *idempotency.Idempotency, authz *Authorizer) *API {
+	return &API{itemService: itSvc, idempotency: idem, authorizer: authz}
 }
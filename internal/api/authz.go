@@ -0,0 +1,306 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/naughtygopher/errors"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/prashantkr001/template-go/internal/item"
+	"github.com/prashantkr001/template-go/internal/pkg/auth"
+	"github.com/prashantkr001/template-go/internal/pkg/logger"
+)
+
+// Permissions required by the API methods. The permissions granted by a policy can end with a "*"
+// wildcard, e.g. "items:*" grants all of them.
+const (
+	// PermissionItemCreate is required by ItemCreateIfNotExists & ItemCreateMany
+	PermissionItemCreate  = "items:create"
+	PermissionItemList    = "items:list"
+	PermissionItemRead    = "items:read"
+	PermissionItemHistory = "items:history"
+	// PermissionItemUpdate is required by ItemUpdate & ItemPatch
+	PermissionItemUpdate  = "items:update"
+	PermissionItemDelete  = "items:delete"
+	PermissionItemRestore = "items:restore"
+)
+
+var permissions = []string{
+	PermissionItemCreate,
+	PermissionItemList,
+	PermissionItemRead,
+	PermissionItemHistory,
+	PermissionItemUpdate,
+	PermissionItemDelete,
+	PermissionItemRestore,
+}
+
+// ErrPermissionDenied is returned if the caller does not have the permission required by the API
+var ErrPermissionDenied = errors.Unauthorized("permission denied")
+
+// Grant is a set of permissions granted to a role, a scope or the anonymous callers
+type Grant struct {
+	// Permissions are granted for all the items
+	Permissions []string `json:"permissions,omitempty" yaml:"permissions"`
+	// OwnedPermissions are granted only for the items owned by the principal, see Policy.OwnerLabel
+	OwnedPermissions []string `json:"ownedPermissions,omitempty" yaml:"ownedPermissions"`
+}
+
+// Policy grants permissions to the principals by their roles & scopes. A permission is granted if
+// any of the roles or scopes of the principal grants it.
+type Policy struct {
+	Roles  map[string]Grant `json:"roles,omitempty" yaml:"roles"`
+	Scopes map[string]Grant `json:"scopes,omitempty" yaml:"scopes"`
+	// Anonymous is granted to the calls without a principal, i.e. if authentication is disabled, and
+	// the internal calls (e.g. of the Kafka subscriber, unless it has a principal). It should grant
+	// items:create for the anonymous subscriber, since the records denied are skipped.
+	Anonymous Grant `json:"anonymous" yaml:"anonymous"`
+	// OwnerLabel is the label of the items with the subject of their owner. It is set to the subject
+	// of the principal creating the item, and it is required for the owned permissions.
+	OwnerLabel string `json:"ownerLabel,omitempty" yaml:"ownerLabel"`
+	// AnonymousTenants are the tenants the anonymous calls are bound to, similar to the tenants of
+	// the principals
+	AnonymousTenants []string `json:"anonymousTenants,omitempty" yaml:"anonymousTenants"`
+}
+
+// LoadPolicy reads the policy from a YAML (or JSON) file, unknown fields are not allowed
+func LoadPolicy(path string) (*Policy, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read policy file '%s'", path)
+	}
+
+	policy := &Policy{}
+	dec := yaml.NewDecoder(bytes.NewReader(raw))
+	dec.KnownFields(true)
+	err = dec.Decode(policy)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid policy file '%s'", path)
+	}
+
+	return policy, nil
+}
+
+// Authorizer evaluates the permissions required by the API methods, using the policy
+type Authorizer struct {
+	policy Policy
+}
+
+// NewAuthorizer validates the policy, all the permissions granted should match at least one of the
+// permissions of the API. The owner label should be a valid label key as per the limits of the items,
+// e.g. with the same key pattern.
+func NewAuthorizer(policy *Policy, limits item.Limits) (*Authorizer, error) {
+	if len(policy.Anonymous.OwnedPermissions) > 0 {
+		return nil, errors.Validation("anonymous callers can not own items, they can't have owned permissions")
+	}
+	if policy.OwnerLabel != "" {
+		err := limits.ValidKey(policy.OwnerLabel)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid owner label")
+		}
+	}
+
+	grants := map[string]Grant{"anonymous": policy.Anonymous}
+	for role, grant := range policy.Roles {
+		grants["role '"+role+"'"] = grant
+	}
+	for scope, grant := range policy.Scopes {
+		grants["scope '"+scope+"'"] = grant
+	}
+
+	for name, grant := range grants {
+		if len(grant.OwnedPermissions) > 0 && policy.OwnerLabel == "" {
+			return nil, errors.Validationf("%s has owned permissions, which require the owner label", name)
+		}
+		for _, granted := range append(grant.Permissions, grant.OwnedPermissions...) {
+			if !grantsAny(granted) {
+				return nil, errors.Validationf("%s has unknown permission '%s'", name, granted)
+			}
+		}
+	}
+
+	return &Authorizer{policy: *policy}, nil
+}
+
+// grantsAny reports if the granted permission matches at least one of the API permissions
+func grantsAny(granted string) bool {
+	for _, permission := range permissions {
+		if grants(granted, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+func grants(granted, permission string) bool {
+	if prefix, ok := strings.CutSuffix(granted, "*"); ok {
+		return strings.HasPrefix(permission, prefix)
+	}
+	return granted == permission
+}
+
+func grantsPermission(granted []string, permission string) bool {
+	for _, g := range granted {
+		if grants(g, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+type access int
+
+const (
+	accessDenied access = iota
+	// accessOwned is the access only to the items owned by the principal
+	accessOwned
+	accessAll
+)
+
+// access returns the access of the principal to the permission, i.e. the highest access granted by
+// any of its roles or scopes. The principal is nil for anonymous calls.
+func (az *Authorizer) access(principal *auth.Principal, permission string) access {
+	grants := []Grant{az.policy.Anonymous}
+	if principal != nil {
+		grants = make([]Grant, 0, len(principal.Roles)+len(principal.Scopes))
+		for _, role := range principal.Roles {
+			grants = append(grants, az.policy.Roles[role])
+		}
+		for _, scope := range principal.Scopes {
+			grants = append(grants, az.policy.Scopes[scope])
+		}
+	}
+
+	granted := accessDenied
+	for _, grant := range grants {
+		if grantsPermission(grant.Permissions, permission) {
+			return accessAll
+		}
+		if grantsPermission(grant.OwnedPermissions, permission) {
+			granted = accessOwned
+		}
+	}
+
+	return granted
+}
+
+// boundTo reports if the caller is bound to the tenant. Principals are bound to their tenants, and the
+// anonymous calls to the anonymous tenants of the policy. If there are none, it's only item.DefaultTenant.
+func (az *Authorizer) boundTo(principal *auth.Principal, tenant string) bool {
+	tenants := az.policy.AnonymousTenants
+	if principal != nil {
+		tenants = principal.Tenants
+	}
+	if len(tenants) == 0 {
+		return tenant == item.DefaultTenant
+	}
+
+	return slices.Contains(tenants, auth.AnyTenant) || slices.Contains(tenants, tenant)
+}
+
+// authorize returns the access of the caller to the permission, or ErrPermissionDenied if it has
+// none or if it is not bound to the tenant of the call. If the access is only to the owned items, the
+// caller should check the ownership of the item, or restrict the call to the owned items. All the calls
+// are allowed if there's no authorizer.
+func (ap *API) authorize(ctx context.Context, permission string) (access, error) {
+	if ap.authorizer == nil {
+		return accessAll, nil
+	}
+
+	principal := auth.PrincipalOf(ctx)
+	if tenant := item.Tenant(ctx); !ap.authorizer.boundTo(principal, tenant) {
+		return accessDenied, ap.denied(ctx, permission, 0, fmt.Sprintf("tenant '%s' is not allowed", tenant))
+	}
+
+	granted := ap.authorizer.access(principal, permission)
+	if granted == accessDenied {
+		return granted, ap.denied(ctx, permission, 0, fmt.Sprintf("permission '%s' is required", permission))
+	}
+
+	return granted, nil
+}
+
+// authorizeItem is authorize for a single item, if the access is only to the owned items the item is
+// fetched (including deleted ones) to check its owner. Items which don't exist are denied the same as
+// the ones not owned, so that the IDs of the items of others can't be probed.
+func (ap *API) authorizeItem(ctx context.Context, permission string, id int) (access, error) {
+	granted, err := ap.authorize(ctx, permission)
+	if err != nil || granted == accessAll {
+		return granted, err
+	}
+
+	labels, err := ap.itemLabels(ctx, permission, id)
+	if err != nil && !errors.Is(err, item.ErrNotFound) {
+		return accessDenied, err
+	}
+	if err != nil || labels[ap.authorizer.policy.OwnerLabel] != auth.PrincipalOf(ctx).Subject {
+		return accessDenied, ap.denied(
+			ctx,
+			permission,
+			id,
+			fmt.Sprintf("permission '%s' is granted only for the owned items", permission),
+		)
+	}
+
+	return granted, nil
+}
+
+// itemLabels returns the labels of the item, including the deleted ones. The history is available even
+// after the item is purged, hence the labels of purged items are of their history for its permission.
+func (ap *API) itemLabels(ctx context.Context, permission string, id int) (map[string]string, error) {
+	it, err := ap.itemService.Get(ctx, id, true)
+	if errors.Is(err, item.ErrNotFound) && permission == PermissionItemHistory {
+		return ap.itemService.HistoryLabels(ctx, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return it.Labels, nil
+}
+
+// withOwner returns a copy of the labels with the principal of the context as the owner, if the policy
+// has an owner label. The labels are returned as is otherwise.
+func (ap *API) withOwner(ctx context.Context, labels map[string]string) map[string]string {
+	principal := auth.PrincipalOf(ctx)
+	if ap.authorizer == nil || ap.authorizer.policy.OwnerLabel == "" || principal == nil {
+		return labels
+	}
+
+	owned := maps.Clone(labels)
+	if owned == nil {
+		owned = make(map[string]string, 1)
+	}
+	owned[ap.authorizer.policy.OwnerLabel] = principal.Subject
+	return owned
+}
+
+// denied records the denial in the audit log, and returns it as ErrPermissionDenied
+func (ap *API) denied(ctx context.Context, permission string, itemID int, reason string) error {
+	subject, method := item.ActorAnonymous, ""
+	if principal := auth.PrincipalOf(ctx); principal != nil {
+		subject, method = principal.Subject, principal.Method
+	}
+
+	fields := []zap.Field{
+		zap.Bool("audit", true),
+		zap.String("decision", "deny"),
+		zap.String("subject", subject),
+		zap.String("auth_method", method),
+		zap.String("tenant", item.Tenant(ctx)),
+		zap.String("permission", permission),
+		zap.String("reason", reason),
+	}
+	if itemID > 0 {
+		fields = append(fields, zap.Int("item_id", itemID))
+	}
+	logger.WarnCtx(ctx, "permission denied", fields...)
+
+	return errors.UnauthorizedErr(ErrPermissionDenied, reason)
+}
@@ -0,0 +1,296 @@
+package api
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/naughtygopher/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/prashantkr001/template-go/internal/item"
+	"github.com/prashantkr001/template-go/internal/pkg/auth"
+	"github.com/prashantkr001/template-go/internal/pkg/logger"
+)
+
+const testPolicy = `
+ownerLabel: owner
+anonymous:
+  permissions: ["items:create"]
+roles:
+  admin:
+    permissions: ["items:*"]
+  viewer:
+    permissions: ["items:list", "items:read", "items:history"]
+  editor:
+    permissions: ["items:create", "items:read"]
+    ownedPermissions: ["items:list", "items:update", "items:delete", "items:restore"]
+scopes:
+  items.read:
+    permissions: ["items:list", "items:read"]
+`
+
+func newTestAuthorizer(t *testing.T, policy string) *Authorizer {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(policy), 0o600))
+	pol, err := LoadPolicy(path)
+	require.NoError(t, err)
+	authz, err := NewAuthorizer(pol, item.Limits{})
+	require.NoError(t, err)
+
+	return authz
+}
+
+func newTestAPI(t *testing.T, policy string) *API {
+	t.Helper()
+
+	store, err := item.NewMemoryPersistentStore()
+	require.NoError(t, err)
+	svc, err := item.NewService(store, nil, nil, nil, &item.Config{UseOutbox: true})
+	require.NoError(t, err)
+
+	return NewService(svc, nil, newTestAuthorizer(t, policy))
+}
+
+func as(ctx context.Context, subject string, roles, scopes []string) context.Context {
+	return auth.WithPrincipal(ctx, &auth.Principal{
+		Subject: subject,
+		Method:  auth.MethodAPIKey,
+		Roles:   roles,
+		Scopes:  scopes,
+	})
+}
+
+func TestAuthorization(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+
+	// the audit logs are observed, the package's logger is the global zap logger by default
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger.SetGlobal(zap.New(core))
+	t.Cleanup(func() {
+		logger.SetGlobal(zap.L())
+	})
+
+	ap := newTestAPI(t, testPolicy)
+	admin := as(t.Context(), "root", []string{"admin"}, nil)
+	viewer := as(t.Context(), "jane", []string{"viewer"}, nil)
+	alice := as(t.Context(), "alice", []string{"editor"}, nil)
+	bob := as(t.Context(), "bob", []string{"editor"}, nil)
+
+	t.Run("roles", func(_ *testing.T) {
+		_, err := ap.ItemCreateIfNotExists(admin, item.Item{ID: 1, Name: "Box"})
+		requirer.NoError(err)
+
+		_, err = ap.ItemGet(viewer, 1, false)
+		asserter.NoError(err)
+		_, err = ap.ItemCreateIfNotExists(viewer, item.Item{ID: 2, Name: "Jar"})
+		asserter.ErrorIs(err, ErrPermissionDenied)
+		asserter.Equal(errors.TypeUnauthorized, errors.Type(err))
+		asserter.ErrorIs(ap.ItemDelete(viewer, 1, 0), ErrPermissionDenied)
+
+		// principals without any known role or scope have no permissions
+		_, err = ap.ItemGet(as(t.Context(), "joe", []string{"unknown"}, nil), 1, false)
+		asserter.ErrorIs(err, ErrPermissionDenied)
+	})
+
+	t.Run("scopes", func(_ *testing.T) {
+		service := as(t.Context(), "reports", nil, []string{"items.read"})
+		_, err := ap.ItemList(service, item.ListQuery{})
+		asserter.NoError(err)
+		_, err = ap.ItemPatch(service, 1, item.Patch{Name: new(string)})
+		asserter.ErrorIs(err, ErrPermissionDenied)
+	})
+
+	t.Run("anonymous calls", func(_ *testing.T) {
+		created, err := ap.ItemCreateIfNotExists(t.Context(), item.Item{ID: 3, Name: "Tray"})
+		requirer.NoError(err)
+		asserter.Empty(created.Labels)
+
+		_, err = ap.ItemList(t.Context(), item.ListQuery{})
+		asserter.ErrorIs(err, ErrPermissionDenied)
+	})
+
+	t.Run("owned items", func(_ *testing.T) {
+		created, err := ap.ItemCreateIfNotExists(alice, item.Item{
+			ID:     10,
+			Name:   "Vase",
+			Labels: map[string]string{"owner": "bob", "color": "red"},
+		})
+		requirer.NoError(err)
+		asserter.Equal(map[string]string{"owner": "alice", "color": "red"}, created.Labels)
+		_, err = ap.ItemCreateIfNotExists(bob, item.Item{ID: 11, Name: "Mug"})
+		requirer.NoError(err)
+
+		// the owner can not be changed by the owner
+		patched, err := ap.ItemPatch(alice, 10, item.Patch{Labels: map[string]string{"owner": "bob"}})
+		requirer.NoError(err)
+		asserter.Equal(map[string]string{"owner": "alice"}, patched.Labels)
+
+		_, err = ap.ItemPatch(bob, 10, item.Patch{Labels: map[string]string{}})
+		asserter.ErrorIs(err, ErrPermissionDenied)
+		asserter.ErrorIs(ap.ItemDelete(bob, 10, 0), ErrPermissionDenied)
+		asserter.NoError(ap.ItemDelete(alice, 10, 0))
+		_, err = ap.ItemRestore(alice, 10, 0)
+		asserter.NoError(err)
+
+		// the items which are not owned are not listed
+		list, err := ap.ItemList(bob, item.ListQuery{})
+		requirer.NoError(err)
+		requirer.Len(list.Items, 1)
+		asserter.Equal(11, list.Items[0].ID)
+
+		// the items are readable irrespective of the owner
+		_, err = ap.ItemGet(bob, 10, false)
+		asserter.NoError(err)
+
+		// items which don't exist are denied the same as the ones not owned, so that IDs can't be probed
+		asserter.ErrorIs(ap.ItemDelete(bob, 404, 0), ErrPermissionDenied)
+	})
+
+	t.Run("history of owned items after they're purged", func(_ *testing.T) {
+		store, err := item.NewMemoryPersistentStore()
+		requirer.NoError(err)
+		svc, err := item.NewService(store, nil, nil, nil, &item.Config{UseOutbox: true})
+		requirer.NoError(err)
+		owned := NewService(svc, nil, newTestAuthorizer(t, `
+ownerLabel: owner
+roles:
+  auditor:
+    permissions: ["items:create", "items:delete"]
+    ownedPermissions: ["items:history"]
+`))
+		carol := as(t.Context(), "carol", []string{"auditor"}, nil)
+		dave := as(t.Context(), "dave", []string{"auditor"}, nil)
+
+		_, err = owned.ItemCreateIfNotExists(carol, item.Item{ID: 1, Name: "Box"})
+		requirer.NoError(err)
+		requirer.NoError(owned.ItemDelete(carol, 1, 0))
+		_, err = store.PurgeItems(t.Context(), time.Now().Add(time.Minute), 10)
+		requirer.NoError(err)
+
+		history, err := owned.ItemHistory(carol, 1, item.HistoryQuery{})
+		requirer.NoError(err)
+		asserter.Len(history.Entries, 2)
+
+		_, err = owned.ItemHistory(dave, 1, item.HistoryQuery{})
+		asserter.ErrorIs(err, ErrPermissionDenied)
+		_, err = owned.ItemHistory(carol, 404, item.HistoryQuery{})
+		asserter.ErrorIs(err, ErrPermissionDenied)
+	})
+
+	t.Run("tenants", func(_ *testing.T) {
+		acmeAdmin := auth.WithPrincipal(t.Context(), &auth.Principal{
+			Subject: "wile",
+			Method:  auth.MethodJWT,
+			Roles:   []string{"admin"},
+			Tenants: []string{"acme"},
+		})
+		acme := item.WithTenant(acmeAdmin, "acme")
+		_, err := ap.ItemCreateIfNotExists(acme, item.Item{ID: 20, Name: "Anvil"})
+		requirer.NoError(err)
+		_, err = ap.ItemGet(acme, 20, false)
+		asserter.NoError(err)
+
+		// the principal can not access the other tenants, including the default one
+		globex := item.WithTenant(acmeAdmin, "globex")
+		_, err = ap.ItemGet(globex, 20, false)
+		asserter.ErrorIs(err, ErrPermissionDenied)
+		_, err = ap.ItemCreateMany(globex, []item.Item{{ID: 21, Name: "Rocket"}})
+		asserter.ErrorIs(err, ErrPermissionDenied)
+		_, err = ap.ItemList(acmeAdmin, item.ListQuery{})
+		asserter.ErrorIs(err, ErrPermissionDenied)
+
+		// principals without tenants are bound to the default tenant only
+		_, err = ap.ItemGet(item.WithTenant(admin, "acme"), 20, false)
+		asserter.ErrorIs(err, ErrPermissionDenied)
+		_, err = ap.ItemCreateIfNotExists(item.WithTenant(t.Context(), "acme"), item.Item{ID: 22, Name: "Tray"})
+		asserter.ErrorIs(err, ErrPermissionDenied)
+
+		anyTenant := auth.WithPrincipal(t.Context(), &auth.Principal{
+			Subject: "ops",
+			Method:  auth.MethodAPIKey,
+			Roles:   []string{"admin"},
+			Tenants: []string{auth.AnyTenant},
+		})
+		_, err = ap.ItemGet(item.WithTenant(anyTenant, "acme"), 20, false)
+		asserter.NoError(err)
+		_, err = ap.ItemGet(item.WithTenant(anyTenant, "globex"), 20, false)
+		asserter.ErrorIs(err, item.ErrNotFound)
+	})
+
+	t.Run("denials are audited", func(_ *testing.T) {
+		logs.TakeAll()
+		asserter.ErrorIs(ap.ItemDelete(bob, 10, 0), ErrPermissionDenied)
+
+		entries := logs.FilterMessage("permission denied").AllUntimed()
+		requirer.Len(entries, 1)
+		fields := entries[0].ContextMap()
+		asserter.Equal(zapcore.WarnLevel, entries[0].Level)
+		asserter.Equal(true, fields["audit"])
+		asserter.Equal("bob", fields["subject"])
+		asserter.Equal(PermissionItemDelete, fields["permission"])
+		asserter.Equal(int64(10), fields["item_id"])
+		asserter.Equal(item.DefaultTenant, fields["tenant"])
+	})
+
+	t.Run("anonymous tenants", func(_ *testing.T) {
+		anonymous := newTestAPI(t, testPolicy+"anonymousTenants: [\"acme\"]\n")
+		_, err := anonymous.ItemCreateIfNotExists(item.WithTenant(t.Context(), "acme"), item.Item{ID: 1, Name: "Anvil"})
+		asserter.NoError(err)
+		_, err = anonymous.ItemCreateIfNotExists(t.Context(), item.Item{ID: 2, Name: "Rocket"})
+		asserter.ErrorIs(err, ErrPermissionDenied)
+	})
+
+	t.Run("no authorizer", func(_ *testing.T) {
+		store, err := item.NewMemoryPersistentStore()
+		requirer.NoError(err)
+		svc, err := item.NewService(store, nil, nil, nil, &item.Config{UseOutbox: true})
+		requirer.NoError(err)
+		unrestricted := NewService(svc, nil, nil)
+
+		created, err := unrestricted.ItemCreateIfNotExists(viewer, item.Item{ID: 1, Name: "Box"})
+		requirer.NoError(err)
+		asserter.Empty(created.Labels)
+		asserter.NoError(unrestricted.ItemDelete(t.Context(), 1, 0))
+	})
+}
+
+func TestNewAuthorizer(t *testing.T) {
+	asserter := assert.New(t)
+
+	for name, policy := range map[string]*Policy{
+		"unknown permission": {Roles: map[string]Grant{"admin": {Permissions: []string{"item:*"}}}},
+		"owned permissions without owner label": {
+			Roles: map[string]Grant{"editor": {OwnedPermissions: []string{PermissionItemUpdate}}},
+		},
+		"anonymous owned permissions": {
+			OwnerLabel: "owner",
+			Anonymous:  Grant{OwnedPermissions: []string{PermissionItemUpdate}},
+		},
+		"invalid owner label": {OwnerLabel: "Owner.Sub"},
+	} {
+		_, err := NewAuthorizer(policy, item.Limits{})
+		asserter.Error(err, name)
+	}
+
+	_, err := NewAuthorizer(&Policy{Scopes: map[string]Grant{"all": {Permissions: []string{"*"}}}}, item.Limits{})
+	asserter.NoError(err)
+
+	// the owner label is validated with the key pattern of the items
+	_, err = NewAuthorizer(&Policy{OwnerLabel: "Owner"}, item.Limits{KeyPattern: "^[A-Za-z]+$"})
+	asserter.NoError(err)
+
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	asserter.NoError(os.WriteFile(path, []byte("roles: {}\nowner: owner\n"), 0o600))
+	_, err = LoadPolicy(path)
+	asserter.Error(err, "unknown fields are not allowed")
+}
@@ -2,22 +2,155 @@ package api
 
 import (
 	"context"
+	"net/url"
+	"slices"
 
 	"github.com/prashantkr001/template-go/internal/item"
+	"github.com/prashantkr001/template-go/internal/pkg/auth"
+	"github.com/prashantkr001/template-go/internal/pkg/idempotency"
 )
 
+// ItemCreateIfNotExists is idempotent if the context has an idempotency key, i.e. the retries get
+// the originally created item instead of item.ErrDuplicateItem. The keys are per tenant & principal.
 func (ap *API) ItemCreateIfNotExists(ctx context.Context, newItem item.Item) (*item.Item, error) {
-	createdItem, err := ap.itemService.CreateIfNotExist(ctx, newItem)
+	_, err := ap.authorize(ctx, PermissionItemCreate)
+	if err != nil {
+		return nil, err
+	}
+	newItem.Labels = ap.withOwner(ctx, newItem.Labels)
+
+	createdItem, err := idempotency.Do(ctx, ap.idempotency, idempotencyScope(ctx), "item.create", newItem, func(ctx context.Context) (*item.Item, error) {
+		return ap.itemService.CreateIfNotExist(ctx, newItem)
+	})
 	if err != nil {
 		return nil, err
 	}
 	return createdItem, nil
 }
 
-func (ap *API) ItemList(ctx context.Context, limit int) ([]item.Item, error) {
-	list, err := ap.itemService.List(ctx, limit)
+func (ap *API) ItemCreateMany(ctx context.Context, items []item.Item) ([]item.CreateResult, error) {
+	_, err := ap.authorize(ctx, PermissionItemCreate)
+	if err != nil {
+		return nil, err
+	}
+	items = slices.Clone(items)
+	for i := range items {
+		items[i].Labels = ap.withOwner(ctx, items[i].Labels)
+	}
+
+	results, err := ap.itemService.CreateMany(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ItemList lists only the items owned by the caller, if it can list just the owned items
+func (ap *API) ItemList(ctx context.Context, query item.ListQuery) (*item.ListResult, error) {
+	granted, err := ap.authorize(ctx, PermissionItemList)
+	if err != nil {
+		return nil, err
+	}
+	if granted == accessOwned {
+		query.Labels = ap.withOwner(ctx, query.Labels)
+	}
+
+	list, err := ap.itemService.List(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	return list, nil
 }
+
+func (ap *API) ItemGet(ctx context.Context, id int, includeDeleted bool) (*item.Item, error) {
+	_, err := ap.authorizeItem(ctx, PermissionItemRead, id)
+	if err != nil {
+		return nil, err
+	}
+
+	it, err := ap.itemService.Get(ctx, id, includeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	return it, nil
+}
+
+// ItemUpdate keeps the caller as the owner, if it can update just the owned items
+func (ap *API) ItemUpdate(ctx context.Context, it item.Item) (*item.Item, error) {
+	granted, err := ap.authorizeItem(ctx, PermissionItemUpdate, it.ID)
+	if err != nil {
+		return nil, err
+	}
+	if granted == accessOwned {
+		it.Labels = ap.withOwner(ctx, it.Labels)
+	}
+
+	updatedItem, err := ap.itemService.Update(ctx, it)
+	if err != nil {
+		return nil, err
+	}
+	return updatedItem, nil
+}
+
+// ItemPatch keeps the caller as the owner if the labels are replaced, and it can patch just the owned items
+func (ap *API) ItemPatch(ctx context.Context, id int, patch item.Patch) (*item.Item, error) {
+	granted, err := ap.authorizeItem(ctx, PermissionItemUpdate, id)
+	if err != nil {
+		return nil, err
+	}
+	if granted == accessOwned && patch.Labels != nil {
+		patch.Labels = ap.withOwner(ctx, patch.Labels)
+	}
+
+	patchedItem, err := ap.itemService.Patch(ctx, id, patch)
+	if err != nil {
+		return nil, err
+	}
+	return patchedItem, nil
+}
+
+func (ap *API) ItemDelete(ctx context.Context, id int, version int64) error {
+	_, err := ap.authorizeItem(ctx, PermissionItemDelete, id)
+	if err != nil {
+		return err
+	}
+
+	return ap.itemService.Delete(ctx, id, version)
+}
+
+func (ap *API) ItemRestore(ctx context.Context, id int, version int64) (*item.Item, error) {
+	_, err := ap.authorizeItem(ctx, PermissionItemRestore, id)
+	if err != nil {
+		return nil, err
+	}
+
+	restoredItem, err := ap.itemService.Restore(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	return restoredItem, nil
+}
+
+func (ap *API) ItemHistory(ctx context.Context, id int, query item.HistoryQuery) (*item.HistoryResult, error) {
+	_, err := ap.authorizeItem(ctx, PermissionItemHistory, id)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := ap.itemService.History(ctx, id, query)
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// idempotencyScope isolates the idempotency keys by the tenant & the principal (if any), so that a
+// caller can't replay the response of another caller by reusing its key. The subject is escaped, since
+// the scope is part of the key separated by ':'.
+func idempotencyScope(ctx context.Context) string {
+	subject := ""
+	if principal := auth.PrincipalOf(ctx); principal != nil {
+		subject = url.QueryEscape(principal.Subject)
+	}
+	return item.Tenant(ctx) + ":" + subject
+}
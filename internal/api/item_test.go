@@ -0,0 +1,42 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/prashantkr001/template-go/internal/item"
+	"github.com/prashantkr001/template-go/internal/pkg/idempotency"
+)
+
+func TestItemCreateIfNotExists(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+
+	ap := newTestAPI(t, testPolicy)
+	idem, err := idempotency.New(idempotency.NewMemoryStore(), &idempotency.Config{})
+	requirer.NoError(err)
+	ap.idempotency = idem
+
+	alice := idempotency.WithKey(as(t.Context(), "alice", []string{"admin"}, nil), "key-1")
+	bob := idempotency.WithKey(as(t.Context(), "bob", []string{"admin"}, nil), "key-1")
+
+	t.Run("retries of the principal get the created item", func(_ *testing.T) {
+		created, cerr := ap.ItemCreateIfNotExists(alice, item.Item{ID: 1, Name: "Box"})
+		requirer.NoError(cerr)
+		replayed, cerr := ap.ItemCreateIfNotExists(alice, item.Item{ID: 1, Name: "Box"})
+		requirer.NoError(cerr)
+		asserter.Equal(created, replayed)
+	})
+
+	t.Run("idempotency keys are scoped by the principal", func(_ *testing.T) {
+		// the key of another principal is not replayed, hence the item is a duplicate
+		_, cerr := ap.ItemCreateIfNotExists(bob, item.Item{ID: 1, Name: "Box"})
+		asserter.ErrorIs(cerr, item.ErrDuplicateItem)
+
+		anonymous := idempotency.WithKey(t.Context(), "key-1")
+		_, cerr = ap.ItemCreateIfNotExists(anonymous, item.Item{ID: 1, Name: "Box"})
+		asserter.ErrorIs(cerr, item.ErrDuplicateItem)
+	})
+}
@@ -0,0 +1,20 @@
+package item
+
+import "context"
+
+// ActorAnonymous is used as the actor by the APIs, when the caller is not identified
+const ActorAnonymous = "anonymous"
+
+type actorKey struct{}
+
+// WithActor returns a context with the actor, who is recorded as the one making the changes of items
+// using the context. e.g. the user or the service calling the API.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns the actor of the context, or an empty string if there's none
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
@@ -0,0 +1,201 @@
+package item
+
+import (
+	"context"
+	"time"
+
+	"github.com/naughtygopher/errors"
+)
+
+// MaxCreateBatch is the maximum number of items which can be created in a single CreateMany call
+const MaxCreateBatch = 1000
+
+var ErrBatchTooLarge = errors.InputBodyf("at most %d items can be created in a batch", MaxCreateBatch)
+
+type CreateStatus string
+
+const (
+	CreateStatusCreated   CreateStatus = "created"
+	CreateStatusDuplicate CreateStatus = "duplicate"
+	CreateStatusInvalid   CreateStatus = "invalid"
+	// CreateStatusFailed is for any other failure specific to the item, which might succeed upon retry
+	CreateStatusFailed CreateStatus = "failed"
+)
+
+// CreateResult is the outcome of creating a single item of a batch
+type CreateResult struct {
+	ID     int          `json:"id"`
+	Status CreateStatus `json:"status"`
+	// Item is the created item, only if the status is "created"
+	Item *Item `json:"item,omitempty"`
+	// Error is the reason the item was not created
+	Error string `json:"error,omitempty"`
+	// Violations are the validation errors of the item, only if the status is "invalid"
+	Violations []FieldError `json:"errors,omitempty"`
+}
+
+// CreateMany creates all the valid items in bulk, and returns the result of every item in the same
+// order as the input. Failure to create an item does not affect the others, and an event is
+// published for every item created. An error is returned only if the batch as a whole failed.
+func (svc *Service) CreateMany(ctx context.Context, items []Item) ([]CreateResult, error) {
+	tenant, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) > MaxCreateBatch {
+		return nil, errors.Wrapf(ErrBatchTooLarge, ": %d", len(items))
+	}
+
+	results := make([]CreateResult, len(items))
+	valid := make([]Item, 0, len(items))
+	// positions are the indexes of the valid items in the results
+	positions := make([]int, 0, len(items))
+	now := time.Now().UTC()
+	for idx, item := range items {
+		results[idx].ID = item.ID
+		item.Tenant = tenant
+		item.Tags = normalizeTags(item.Tags)
+		verr := item.Validate(svc.validator)
+		if verr == nil {
+			requested := item.Name
+			item.Name = svc.namer.Name(&item)
+			verr = item.validateNamed(svc.validator, requested)
+		}
+		if verr != nil {
+			results[idx].Status = CreateStatusInvalid
+			results[idx].Error = errMessage(verr)
+			details := new(ValidationError)
+			if errors.As(verr, &details) {
+				results[idx].Violations = details.Violations
+			}
+			continue
+		}
+
+		item.Version = 1
+		item.CreatedAt = now
+		item.UpdatedAt = now
+		valid = append(valid, item)
+		positions = append(positions, idx)
+	}
+
+	itemErrs, err := svc.insertMany(ctx, tenant, valid)
+	if err != nil {
+		return nil, err
+	}
+
+	for vidx, itemErr := range itemErrs {
+		result := &results[positions[vidx]]
+		switch {
+		case itemErr == nil:
+			result.Status = CreateStatusCreated
+			result.Item = &valid[vidx]
+		case errors.Is(itemErr, ErrDuplicateItem):
+			result.Status = CreateStatusDuplicate
+			result.Error = errMessage(itemErr)
+		default:
+			result.Status = CreateStatusFailed
+			result.Error = errMessage(itemErr)
+		}
+	}
+
+	return results, nil
+}
+
+// insertMany returns the error of every item, in the same order as the input
+func (svc *Service) insertMany(ctx context.Context, tenant string, items []Item) ([]error, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	if svc.cfg.UseOutbox {
+		// every item is created in its own transaction along with its outbox record, since a failed
+		// write (e.g. duplicate) aborts the whole transaction
+		itemErrs := make([]error, len(items))
+		for idx := range items {
+			_, itemErrs[idx] = svc.applyChange(ctx, EventItemCreated, func(ctx context.Context) (*Item, *Item, error) {
+				created, err := svc.persistentStore.InsertItem(ctx, tenant, items[idx])
+				return nil, created, err
+			})
+		}
+		return itemErrs, nil
+	}
+
+	// slots are reserved in chunks, since a batch larger than the queue can never be reserved at once
+	itemErrs := make([]error, 0, len(items))
+	for start := 0; start < len(items); start += svc.cfg.PublishQueueDepth {
+		end := min(start+svc.cfg.PublishQueueDepth, len(items))
+		chunkErrs, err := svc.insertChunk(ctx, tenant, items[start:end])
+		if err != nil {
+			return nil, err
+		}
+		itemErrs = append(itemErrs, chunkErrs...)
+	}
+
+	return itemErrs, nil
+}
+
+// insertChunk reserves a slot in the publish queue for every item, before inserting them. Similar
+// to applyChange, so that the queue full policy is applied before any change.
+func (svc *Service) insertChunk(ctx context.Context, tenant string, items []Item) ([]error, error) {
+	reserved := 0
+	release := func() {
+		for range reserved {
+			svc.dispatcher.release()
+		}
+	}
+
+	for range items {
+		ok, err := svc.dispatcher.reserve(ctx)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		if !ok {
+			// the queue is full, events of the remaining items are dropped
+			break
+		}
+		reserved++
+	}
+
+	itemErrs, err := svc.persistentStore.InsertItems(ctx, tenant, items)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	history := make([]HistoryEntry, 0, len(items))
+	for idx := range items {
+		if itemErrs[idx] == nil {
+			history = append(history, newHistoryEntry(ctx, EventItemCreated, nil, &items[idx]))
+			svc.cacheSet(ctx, &items[idx])
+			svc.changed(ctx, EventItemCreated, &items[idx])
+		}
+	}
+	svc.recordHistory(ctx, history...)
+
+	for idx := range items {
+		if itemErrs[idx] != nil {
+			continue
+		}
+		event := svc.newEvent(ctx, EventItemCreated, &items[idx])
+		if reserved == 0 {
+			svc.dispatcher.dropped(ctx, event, "publish queue is full")
+			continue
+		}
+		svc.dispatcher.enqueue(event)
+		reserved--
+	}
+	// slots of the items not created
+	release()
+
+	return itemErrs, nil
+}
+
+// errMessage returns the user friendly message of the error
+func errMessage(err error) string {
+	var ierr *errors.Error
+	if errors.As(err, &ierr) {
+		return ierr.Message()
+	}
+	return err.Error()
+}
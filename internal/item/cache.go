@@ -0,0 +1,98 @@
+package item
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/naughtygopher/errors"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/prashantkr001/template-go/internal/pkg/apm"
+	"github.com/prashantkr001/template-go/internal/pkg/logger"
+)
+
+var ErrCacheMiss = errors.NotFound("item is not cached")
+
+// Cache is used for read-through lookups of items by ID. It is best effort, i.e. failures of the
+// cache are logged and the store is used instead. The cached items may be stale for at most the
+// TTL of the cache, e.g. when the item is modified by another instance of the app with an in-memory cache.
+type Cache interface {
+	// Get should return ErrCacheMiss if the item of the tenant is not cached or is expired
+	Get(ctx context.Context, tenant string, id int) (*Item, error)
+	// Set should cache the item for its tenant, unless an item of a higher version is cached. So that
+	// an item looked up before a concurrent change does not replace the changed one. Deleted items are
+	// cached as well, as tombstones of their version. Hence the TTL should be shorter than the retention
+	// of deleted items, else a tombstone would outlive the purge & hide an item re-created with its ID.
+	Set(ctx context.Context, item *Item) error
+}
+
+// noCache is used when the service has no cache, every lookup is a miss
+type noCache struct{}
+
+func (noCache) Get(context.Context, string, int) (*Item, error) {
+	return nil, ErrCacheMiss
+}
+
+func (noCache) Set(context.Context, *Item) error {
+	return nil
+}
+
+// cachedItem looks up the item in the cache, and in the store upon a miss. Concurrent misses of the
+// same item are collapsed into a single store lookup, to protect the store from stampedes. The shared
+// lookup is not cancelled with the context of the caller which started it, since it's shared by other callers.
+func (svc *Service) cachedItem(ctx context.Context, tenant string, id int) (*Item, error) {
+	meter := apm.Global().AppMeter()
+	it, err := svc.cache.Get(ctx, tenant, id)
+	if err == nil {
+		meter.CounterAdd(ctx, "item.cache.hits", 1, attribute.String("tenant", tenant))
+		return it, nil
+	}
+	// the cache is filled only upon a miss, not when it is failing
+	fill := errors.Is(err, ErrCacheMiss)
+	if !fill {
+		svc.cacheFailed(ctx, "get", err)
+	}
+	meter.CounterAdd(ctx, "item.cache.misses", 1, attribute.String("tenant", tenant))
+
+	lookupCtx := context.WithoutCancel(ctx)
+	lookup := svc.lookups.DoChan(tenant+":"+strconv.Itoa(id), func() (any, error) {
+		stored, serr := svc.persistentStore.Item(lookupCtx, tenant, id)
+		if serr != nil {
+			return nil, serr
+		}
+		// deleted items are not filled, they're cached only when deleted. So that they expire from the
+		// cache long before they're purged, and the ID can be reused.
+		if fill && !stored.IsDeleted() {
+			svc.cacheSet(lookupCtx, stored)
+		}
+		return stored, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, errors.Wrap(ctx.Err(), "item lookup cancelled")
+	case result := <-lookup:
+		if result.Err != nil {
+			return nil, result.Err //nolint:wrapcheck // the store errors are already wrapped
+		}
+		// the item is shared by all the callers, hence a copy is returned
+		stored, _ := result.Val.(*Item)
+		copied := stored.clone()
+		return &copied, nil
+	}
+}
+
+// cacheSet caches the item looked up or changed, deleted items are cached when changed. Since the cache does
+// not replace an item with an older version, an item looked up concurrently before a change does not replace
+// the changed one.
+func (svc *Service) cacheSet(ctx context.Context, it *Item) {
+	err := svc.cache.Set(ctx, it)
+	if err != nil {
+		svc.cacheFailed(ctx, "set", err)
+	}
+}
+
+func (svc *Service) cacheFailed(ctx context.Context, operation string, err error) {
+	apm.Global().AppMeter().CounterAdd(ctx, "item.cache.errors", 1, attribute.String("operation", operation))
+	logger.ErrWithStacktrace(errors.Wrapf(err, "item cache %s failed", operation))
+}
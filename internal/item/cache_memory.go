@@ -0,0 +1,94 @@
+package item
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/naughtygopher/errors"
+)
+
+type lruEntry struct {
+	item      Item
+	expiresAt time.Time
+}
+
+// lruCache keeps at most size items in memory, evicting the least recently used one when full.
+// Items expire after the TTL, irrespective of how recently they were used.
+type lruCache struct {
+	locker *sync.Mutex
+	size   int
+	ttl    time.Duration
+	// recency has the most recently used entry at the front
+	recency *list.List
+	entries map[itemKey]*list.Element
+	now     func() time.Time
+}
+
+func NewLRUCache(size int, ttl time.Duration) (*lruCache, error) { //nolint:revive // it is ok to return unexported type in this case, ensures controlled access
+	if size <= 0 {
+		return nil, errors.Validation("cache size should be > 0")
+	}
+	if ttl <= 0 {
+		return nil, errors.Validation("cache TTL should be > 0")
+	}
+
+	return &lruCache{
+		locker:  &sync.Mutex{},
+		size:    size,
+		ttl:     ttl,
+		recency: list.New(),
+		entries: make(map[itemKey]*list.Element, size),
+		now:     time.Now,
+	}, nil
+}
+
+func (lru *lruCache) Get(_ context.Context, tenant string, id int) (*Item, error) {
+	lru.locker.Lock()
+	defer lru.locker.Unlock()
+
+	elem, ok := lru.entries[itemKey{tenant: tenant, id: id}]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+
+	entry, _ := elem.Value.(*lruEntry)
+	if lru.now().After(entry.expiresAt) {
+		lru.remove(elem)
+		return nil, ErrCacheMiss
+	}
+	lru.recency.MoveToFront(elem)
+
+	it := entry.item.clone()
+	return &it, nil
+}
+
+func (lru *lruCache) Set(_ context.Context, item *Item) error {
+	lru.locker.Lock()
+	defer lru.locker.Unlock()
+
+	entry := &lruEntry{item: item.clone(), expiresAt: lru.now().Add(lru.ttl)}
+	key := keyOf(item)
+	if elem, ok := lru.entries[key]; ok {
+		cached, _ := elem.Value.(*lruEntry)
+		if cached.item.Version > item.Version && !lru.now().After(cached.expiresAt) {
+			return nil
+		}
+		elem.Value = entry
+		lru.recency.MoveToFront(elem)
+		return nil
+	}
+
+	lru.entries[key] = lru.recency.PushFront(entry)
+	if lru.recency.Len() > lru.size {
+		lru.remove(lru.recency.Back())
+	}
+
+	return nil
+}
+
+func (lru *lruCache) remove(elem *list.Element) {
+	entry, _ := lru.recency.Remove(elem).(*lruEntry)
+	delete(lru.entries, keyOf(&entry.item))
+}
@@ -0,0 +1,84 @@
+package item
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLRUCache(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+	ctx := t.Context()
+
+	lru, err := NewLRUCache(2, time.Minute)
+	requirer.NoError(err)
+	now := time.Date(2024, 10, 1, 10, 0, 0, 0, time.UTC)
+	lru.now = func() time.Time {
+		return now
+	}
+
+	t.Run("least recently used item is evicted", func(_ *testing.T) {
+		requirer.NoError(lru.Set(ctx, &Item{Tenant: DefaultTenant, ID: 1, Name: "Cup"}))
+		requirer.NoError(lru.Set(ctx, &Item{Tenant: DefaultTenant, ID: 2, Name: "Mug"}))
+		_, gerr := lru.Get(ctx, DefaultTenant, 1)
+		requirer.NoError(gerr)
+
+		requirer.NoError(lru.Set(ctx, &Item{Tenant: DefaultTenant, ID: 3, Name: "Jar"}))
+		_, gerr = lru.Get(ctx, DefaultTenant, 2)
+		requirer.ErrorIs(gerr, ErrCacheMiss)
+
+		it, gerr := lru.Get(ctx, DefaultTenant, 1)
+		requirer.NoError(gerr)
+		asserter.Equal("Cup", it.Name)
+		asserter.Len(lru.entries, 2)
+	})
+
+	t.Run("updated items replace the cached ones", func(_ *testing.T) {
+		requirer.NoError(lru.Set(ctx, &Item{Tenant: DefaultTenant, ID: 1, Name: "Tumbler", Version: 2}))
+		it, gerr := lru.Get(ctx, DefaultTenant, 1)
+		requirer.NoError(gerr)
+		asserter.Equal("Tumbler", it.Name)
+		asserter.Equal(2, lru.recency.Len())
+
+		// older versions do not replace the cached ones
+		requirer.NoError(lru.Set(ctx, &Item{Tenant: DefaultTenant, ID: 1, Name: "Cup", Version: 1}))
+		it, gerr = lru.Get(ctx, DefaultTenant, 1)
+		requirer.NoError(gerr)
+		asserter.Equal("Tumbler", it.Name)
+	})
+
+	t.Run("items expire after the TTL", func(_ *testing.T) {
+		now = now.Add(time.Minute + time.Second)
+		_, gerr := lru.Get(ctx, DefaultTenant, 1)
+		requirer.ErrorIs(gerr, ErrCacheMiss)
+		asserter.Len(lru.entries, 1)
+	})
+
+	t.Run("expired tombstones do not hide re-created items", func(_ *testing.T) {
+		deletedAt := now
+		deleted := &Item{Tenant: DefaultTenant, ID: 4, Name: "Pan", Version: 3, DeletedAt: &deletedAt}
+		requirer.NoError(lru.Set(ctx, deleted))
+		requirer.NoError(lru.Set(ctx, &Item{Tenant: DefaultTenant, ID: 4, Name: "Pan", Version: 1}))
+		it, gerr := lru.Get(ctx, DefaultTenant, 4)
+		requirer.NoError(gerr)
+		asserter.True(it.IsDeleted())
+
+		now = now.Add(time.Minute + time.Second)
+		requirer.NoError(lru.Set(ctx, &Item{Tenant: DefaultTenant, ID: 4, Name: "Pan", Version: 1}))
+		it, gerr = lru.Get(ctx, DefaultTenant, 4)
+		requirer.NoError(gerr)
+		asserter.False(it.IsDeleted())
+	})
+
+	t.Run("items are cached per tenant", func(_ *testing.T) {
+		requirer.NoError(lru.Set(ctx, &Item{Tenant: "acme", ID: 6, Name: "Pot"}))
+		_, gerr := lru.Get(ctx, DefaultTenant, 6)
+		requirer.ErrorIs(gerr, ErrCacheMiss)
+		it, gerr := lru.Get(ctx, "acme", 6)
+		requirer.NoError(gerr)
+		asserter.Equal("Pot", it.Name)
+	})
+}
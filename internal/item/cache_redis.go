@@ -0,0 +1,89 @@
+package item
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/naughtygopher/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// setIfNotOlder sets the item (ARGV[1]) with the TTL in milliseconds (ARGV[3]), unless the cached item has a higher
+// version than ARGV[2]. Cached items which can not be decoded are replaced.
+var setIfNotOlder = redis.NewScript(`
+local cached = redis.call("GET", KEYS[1])
+if cached then
+	local ok, item = pcall(cjson.decode, cached)
+	if ok and type(item) == "table" and tonumber(item.version or 0) > tonumber(ARGV[2]) then
+		return 0
+	end
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[3])
+return 1
+`)
+
+// redisCache keeps the items as JSON, the cache is shared by all the instances of the app
+type redisCache struct {
+	client redis.UniversalClient
+	// keyPrefix is prepended to the item ID, to avoid conflicts with other keys of the Redis database
+	keyPrefix string
+	ttl       time.Duration
+}
+
+func NewRedisCache(client redis.UniversalClient, keyPrefix string, ttl time.Duration) (*redisCache, error) { //nolint:revive // it is ok to return unexported type in this case, ensures controlled access
+	if ttl <= 0 {
+		return nil, errors.Validation("cache TTL should be > 0")
+	}
+
+	return &redisCache{
+		client:    client,
+		keyPrefix: keyPrefix,
+		ttl:       ttl,
+	}, nil
+}
+
+// key is scoped by the tenant, the tenants are validated by the service hence they can't have ':'
+func (rc *redisCache) key(tenant string, id int) string {
+	return rc.keyPrefix + tenant + ":" + strconv.Itoa(id)
+}
+
+func (rc *redisCache) Get(ctx context.Context, tenant string, id int) (*Item, error) {
+	payload, err := rc.client.Get(ctx, rc.key(tenant, id)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrCacheMiss
+		}
+		return nil, errors.Wrap(err, "could not get the cached item")
+	}
+
+	item := new(Item)
+	err = json.Unmarshal(payload, item)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid cached item %d", id)
+	}
+
+	return item, nil
+}
+
+func (rc *redisCache) Set(ctx context.Context, item *Item) error {
+	payload, err := json.Marshal(item)
+	if err != nil {
+		return errors.Wrap(err, "json marshal failed")
+	}
+
+	err = setIfNotOlder.Run(
+		ctx,
+		rc.client,
+		[]string{rc.key(item.Tenant, item.ID)},
+		payload,
+		item.Version,
+		rc.ttl.Milliseconds(),
+	).Err()
+	if err != nil {
+		return errors.Wrap(err, "could not cache the item")
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package item
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisCache(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+	ctx := t.Context()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+
+	rcache, err := NewRedisCache(client, "item:", time.Minute)
+	requirer.NoError(err)
+
+	t.Run("items are cached as JSON with the TTL", func(_ *testing.T) {
+		_, gerr := rcache.Get(ctx, DefaultTenant, 1)
+		requirer.ErrorIs(gerr, ErrCacheMiss)
+
+		requirer.NoError(rcache.Set(ctx, &Item{Tenant: DefaultTenant, ID: 1, Name: "Cup", Version: 3}))
+		it, gerr := rcache.Get(ctx, DefaultTenant, 1)
+		requirer.NoError(gerr)
+		asserter.Equal(Item{Tenant: DefaultTenant, ID: 1, Name: "Cup", Version: 3}, *it)
+		asserter.Equal(time.Minute, server.TTL("item:default:1"))
+
+		server.FastForward(time.Minute)
+		_, gerr = rcache.Get(ctx, DefaultTenant, 1)
+		requirer.ErrorIs(gerr, ErrCacheMiss)
+	})
+
+	t.Run("older versions do not replace the cached ones", func(_ *testing.T) {
+		requirer.NoError(rcache.Set(ctx, &Item{Tenant: DefaultTenant, ID: 4, Name: "Tumbler", Version: 2}))
+		requirer.NoError(rcache.Set(ctx, &Item{Tenant: DefaultTenant, ID: 4, Name: "Cup", Version: 1}))
+		it, gerr := rcache.Get(ctx, DefaultTenant, 4)
+		requirer.NoError(gerr)
+		asserter.Equal("Tumbler", it.Name)
+
+		requirer.NoError(rcache.Set(ctx, &Item{Tenant: DefaultTenant, ID: 4, Name: "Mug", Version: 3}))
+		it, gerr = rcache.Get(ctx, DefaultTenant, 4)
+		requirer.NoError(gerr)
+		asserter.Equal("Mug", it.Name)
+		asserter.Equal(time.Minute, server.TTL("item:default:4"))
+
+		// invalid payloads are replaced
+		requirer.NoError(server.Set("item:default:5", "haha"))
+		requirer.NoError(rcache.Set(ctx, &Item{Tenant: DefaultTenant, ID: 5, Name: "Pan", Version: 1}))
+		it, gerr = rcache.Get(ctx, DefaultTenant, 5)
+		requirer.NoError(gerr)
+		asserter.Equal("Pan", it.Name)
+	})
+
+	t.Run("invalid payloads and failures are errors, not misses", func(_ *testing.T) {
+		requirer.NoError(server.Set("item:default:3", "haha"))
+		_, gerr := rcache.Get(ctx, DefaultTenant, 3)
+		requirer.Error(gerr)
+		asserter.NotErrorIs(gerr, ErrCacheMiss)
+
+		server.SetError("server is down")
+		defer server.SetError("")
+		_, gerr = rcache.Get(ctx, DefaultTenant, 1)
+		requirer.Error(gerr)
+		asserter.NotErrorIs(gerr, ErrCacheMiss)
+	})
+}
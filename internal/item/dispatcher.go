@@ -0,0 +1,177 @@
+package item
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+
+	"github.com/naughtygopher/errors"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/prashantkr001/template-go/internal/pkg/apm"
+	"github.com/prashantkr001/template-go/internal/pkg/logger"
+)
+
+// Policies applied when the publish queue is full
+const (
+	// QueueFullBlock blocks the request until there's room in the queue, or the request context is done
+	QueueFullBlock = "block"
+	// QueueFullDrop skips publishing the event, the request succeeds
+	QueueFullDrop = "drop"
+	// QueueFullFail fails the request with ErrPublishQueueFull, before making any change
+	QueueFullFail = "fail"
+)
+
+var (
+	ErrUnknownQueueFullPolicy = errors.New("unknown publish queue full policy")
+	ErrPublishQueueFull       = errors.MaximumAttempts("too many pending events to publish, try again later")
+	ErrDispatcherClosed       = errors.New("publish dispatcher is closed")
+)
+
+// dispatcher publishes events asynchronously using a fixed number of workers, consuming from a
+// bounded queue. A slot in the queue is reserved *before* the change is made, so that the
+// queue full policy can be applied without leaving a change behind whose event can't be queued.
+// Every worker has its own queue, and the events are routed by their key, so that the events
+// of an item are published in order.
+type dispatcher struct {
+	publisher publisher
+	cfg       Config
+
+	queues []chan *Event
+	// slots limits the total number of events queued, a slot is held from reservation until
+	// the event is picked up by a worker
+	slots chan struct{}
+
+	locker  *sync.RWMutex
+	closed  bool
+	workers *sync.WaitGroup
+}
+
+func newDispatcher(pub publisher, cfg *Config) *dispatcher {
+	dsp := &dispatcher{
+		publisher: pub,
+		cfg:       *cfg,
+		queues:    make([]chan *Event, cfg.PublishWorkers),
+		slots:     make(chan struct{}, cfg.PublishQueueDepth),
+		locker:    &sync.RWMutex{},
+		workers:   &sync.WaitGroup{},
+	}
+
+	apm.Global().AppMeter().Observe("item.publisher.queue_length", func() float64 {
+		return float64(len(dsp.slots))
+	})
+
+	for idx := range dsp.queues {
+		// each queue can hold all the slots, so that enqueuing a reserved event never blocks
+		dsp.queues[idx] = make(chan *Event, cfg.PublishQueueDepth)
+		dsp.workers.Add(1)
+		go dsp.work(dsp.queues[idx])
+	}
+
+	return dsp
+}
+
+// reserve reserves a slot in the queue based on the queue full policy. It returns false if
+// the slot was not reserved, and the event should be dropped.
+func (dsp *dispatcher) reserve(ctx context.Context) (bool, error) {
+	dsp.locker.RLock()
+	closed := dsp.closed
+	dsp.locker.RUnlock()
+	if closed {
+		return false, ErrDispatcherClosed
+	}
+
+	select {
+	case dsp.slots <- struct{}{}:
+		return true, nil
+	default:
+	}
+
+	switch dsp.cfg.PublishQueueFullPolicy {
+	case QueueFullDrop:
+		return false, nil
+	case QueueFullFail:
+		return false, ErrPublishQueueFull
+	}
+
+	select {
+	case dsp.slots <- struct{}{}:
+		return true, nil
+	case <-ctx.Done():
+		return false, errors.Wrap(ctx.Err(), "waiting for room in the publish queue")
+	}
+}
+
+// release releases a reserved slot, if the change failed and there's no event to publish
+func (dsp *dispatcher) release() {
+	<-dsp.slots
+}
+
+// enqueue queues the event in the slot reserved earlier, hence never blocks
+func (dsp *dispatcher) enqueue(event *Event) {
+	dsp.locker.RLock()
+	defer dsp.locker.RUnlock()
+	if dsp.closed {
+		dsp.release()
+		dsp.dropped(context.Background(), event, "publish dispatcher is closed")
+		return
+	}
+
+	hasher := fnv.New32a()
+	_, _ = hasher.Write([]byte(event.Key()))
+	dsp.queues[hasher.Sum32()%uint32(len(dsp.queues))] <- event //nolint:gosec // G115: number of workers is always > 0 & small
+}
+
+func (dsp *dispatcher) dropped(ctx context.Context, event *Event, reason string) {
+	apm.Global().AppMeter().CounterAdd(
+		ctx,
+		"item.publisher.dropped",
+		1,
+		attribute.String("reason", reason),
+		attribute.String("type", event.Type),
+	)
+	logger.WarnCtx(ctx, fmt.Sprintf("event %s of item %d dropped, %s", event.Type, event.Payload.ID, reason))
+}
+
+func (dsp *dispatcher) work(queue <-chan *Event) {
+	defer dsp.workers.Done()
+
+	for event := range queue {
+		<-dsp.slots
+
+		ctx, cancel := context.WithTimeout(context.Background(), dsp.cfg.PublishTimeout)
+		err := dsp.publisher.Publish(ctx, event)
+		cancel()
+		if err != nil {
+			logger.ErrWithStacktrace(err)
+			continue
+		}
+		logger.Info(fmt.Sprintf("published %s event %s of item %d", event.Type, event.ID, event.Payload.ID))
+	}
+}
+
+// drain stops accepting new events, and waits for the queued events to be published.
+func (dsp *dispatcher) drain(ctx context.Context) error {
+	dsp.locker.Lock()
+	if !dsp.closed {
+		dsp.closed = true
+		for _, queue := range dsp.queues {
+			close(queue)
+		}
+	}
+	dsp.locker.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		dsp.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrapf(ctx.Err(), "%d event(s) not published", len(dsp.slots))
+	}
+}
@@ -0,0 +1,69 @@
+package item
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+const (
+	EventItemCreated = "item.created"
+	EventItemUpdated = "item.updated"
+	EventItemDeleted = "item.deleted"
+	// EventItemRestored is published to the topic of updated items
+	EventItemRestored = "item.restored"
+
+	// EventSchemaVersion should be incremented on every backward incompatible change of the
+	// event envelope or its payload
+	EventSchemaVersion = 1
+)
+
+// Event is the envelope of every change of an item published for other services to consume
+type Event struct {
+	// ID is a ULID, unique per event. Consumers can use it for deduplication
+	ID            string    `json:"id" bson:"id"`
+	Type          string    `json:"type" bson:"type"`
+	OccurredAt    time.Time `json:"occurredAt" bson:"occurredAt"`
+	SchemaVersion int       `json:"schemaVersion" bson:"schemaVersion"`
+	// Source is the name & version of the app which made the change
+	Source string `json:"source" bson:"source"`
+	// TraceParent & TraceState are the W3C trace context of the request which made the change
+	TraceParent string `json:"traceparent,omitempty" bson:"traceparent,omitempty"`
+	TraceState  string `json:"tracestate,omitempty" bson:"tracestate,omitempty"`
+	// Payload is the item after the change. For deleted events, it is the whole soft deleted item, with
+	// DeletedAt & DeletedBy set
+	Payload Item `json:"payload" bson:"payload"`
+}
+
+// Key is used for partitioning, so that all the events of an item are ordered
+func (evt *Event) Key() string {
+	return strconv.Itoa(evt.Payload.ID)
+}
+
+// TraceContext returns the context with the trace context of the event, if available
+func (evt *Event) TraceContext(ctx context.Context) context.Context {
+	return propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{
+		"traceparent": evt.TraceParent,
+		"tracestate":  evt.TraceState,
+	})
+}
+
+func (svc *Service) newEvent(ctx context.Context, eventType string, item *Item) *Event {
+	now := time.Now().UTC()
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+
+	return &Event{
+		ID:            ulid.MustNew(ulid.Timestamp(now), ulid.DefaultEntropy()).String(),
+		Type:          eventType,
+		OccurredAt:    now,
+		SchemaVersion: EventSchemaVersion,
+		Source:        svc.cfg.EventSource,
+		TraceParent:   carrier.Get("traceparent"),
+		TraceState:    carrier.Get("tracestate"),
+		Payload:       *item,
+	}
+}
@@ -0,0 +1,212 @@
+package item
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/naughtygopher/errors"
+	"github.com/oklog/ulid/v2"
+)
+
+type HistoryAction string
+
+const (
+	HistoryCreated  HistoryAction = "created"
+	HistoryUpdated  HistoryAction = "updated"
+	HistoryDeleted  HistoryAction = "deleted"
+	HistoryRestored HistoryAction = "restored"
+)
+
+var historyActions = map[string]HistoryAction{
+	EventItemCreated:  HistoryCreated,
+	EventItemUpdated:  HistoryUpdated,
+	EventItemDeleted:  HistoryDeleted,
+	EventItemRestored: HistoryRestored,
+}
+
+// HistoryEntry is an append-only record of a change of an item. The history of an item is
+// retained even after the item is purged.
+type HistoryEntry struct {
+	// ID is a ULID, so the entries are sortable by the time of creation
+	ID     string        `json:"id" bson:"_id"`
+	Tenant string        `json:"-" bson:"tenant"`
+	ItemID int           `json:"itemId" bson:"itemId"`
+	Action HistoryAction `json:"action" bson:"action"`
+	// Version is the version of the item after the change
+	Version int64     `json:"version" bson:"version"`
+	Actor   string    `json:"actor" bson:"actor"`
+	At      time.Time `json:"at" bson:"at"`
+	// Changes are the fields modified, for created items it has all the fields set
+	Changes []FieldChange `json:"changes" bson:"changes"`
+}
+
+// FieldChange is the diff of a single field of an item, the values are formatted as strings
+type FieldChange struct {
+	Field string `json:"field" bson:"field"`
+	From  string `json:"from" bson:"from"`
+	To    string `json:"to" bson:"to"`
+}
+
+type historyStore interface {
+	// InsertHistory should append the entries, entries are never modified once inserted. The
+	// entries are of their respective tenants.
+	InsertHistory(ctx context.Context, entries []HistoryEntry) error
+	// ListHistory should return at most query.Limit entries of the item of the tenant, newest
+	// first and older than the query cursor (if any).
+	ListHistory(ctx context.Context, tenant string, itemID int, query HistoryQuery) ([]HistoryEntry, error)
+}
+
+// HistoryQuery is used to paginate the history of an item, the limits are the same as ListQuery
+type HistoryQuery struct {
+	Limit int
+	// Cursor is the opaque token returned as HistoryResult.NextCursor, to fetch the next page
+	Cursor string
+}
+
+type HistoryResult struct {
+	Entries []HistoryEntry `json:"entries"`
+	// NextCursor is empty if there are no more entries
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+func (hq *HistoryQuery) normalize() error {
+	switch {
+	case hq.Limit < 0:
+		return errors.Wrapf(ErrInvalidLimit, ": %d", hq.Limit)
+	case hq.Limit == 0:
+		hq.Limit = DefaultListLimit
+	case hq.Limit > MaxListLimit:
+		hq.Limit = MaxListLimit
+	}
+
+	if hq.Cursor != "" {
+		_, err := ulid.ParseStrict(hq.Cursor)
+		if err != nil {
+			return errors.Wrap(ErrInvalidCursor, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// History returns a page of the changes of an item, newest first. The history is available even
+// after the item is purged.
+func (svc *Service) History(ctx context.Context, id int, query HistoryQuery) (*HistoryResult, error) {
+	tenant, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = validateID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = query.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	// fetching one extra entry to know if there's a next page
+	pageSize := query.Limit
+	query.Limit++
+	entries, err := svc.persistentStore.ListHistory(ctx, tenant, id, query)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &HistoryResult{Entries: entries}
+	if len(entries) > pageSize {
+		result.Entries = entries[:pageSize]
+		result.NextCursor = result.Entries[pageSize-1].ID
+	}
+
+	return result, nil
+}
+
+// HistoryLabels returns the labels of the item as of its newest change of labels, i.e. the labels of
+// the item even after it is purged. The labels are empty if they were never set, and ErrNotFound is
+// returned if the item has no history.
+func (svc *Service) HistoryLabels(ctx context.Context, id int) (map[string]string, error) {
+	query := HistoryQuery{Limit: MaxListLimit}
+	for {
+		page, err := svc.History(ctx, id, query)
+		if err != nil {
+			return nil, err
+		}
+		if query.Cursor == "" && len(page.Entries) == 0 {
+			return nil, ErrNotFound
+		}
+
+		for _, entry := range page.Entries {
+			for _, change := range entry.Changes {
+				if change.Field != "labels" {
+					continue
+				}
+				labels := map[string]string{}
+				if change.To == "" {
+					return labels, nil
+				}
+				err = json.Unmarshal([]byte(change.To), &labels)
+				if err != nil {
+					return nil, errors.Wrapf(err, "invalid labels in the history of item %d", id)
+				}
+				return labels, nil
+			}
+		}
+
+		if page.NextCursor == "" {
+			return map[string]string{}, nil
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+// newHistoryEntry records the actor of the context as the one who made the change. before is nil
+// for created items.
+func newHistoryEntry(ctx context.Context, eventType string, before, after *Item) HistoryEntry {
+	now := time.Now().UTC()
+	if before == nil {
+		before = &Item{}
+	}
+
+	return HistoryEntry{
+		ID:      ulid.MustNew(ulid.Timestamp(now), ulid.DefaultEntropy()).String(),
+		Tenant:  after.Tenant,
+		ItemID:  after.ID,
+		Action:  historyActions[eventType],
+		Version: after.Version,
+		Actor:   Actor(ctx),
+		At:      now,
+		Changes: diffItems(before, after),
+	}
+}
+
+// diffItems returns the changes of the modifiable fields, the ones maintained by the store
+// (e.g. version, timestamps) are excluded.
+func diffItems(before, after *Item) []FieldChange {
+	formatTime := func(at *time.Time) string {
+		if at == nil {
+			return ""
+		}
+		return at.UTC().Format(time.RFC3339Nano)
+	}
+
+	fields := []FieldChange{
+		{Field: "name", From: before.Name, To: after.Name},
+		{Field: "deletedAt", From: formatTime(before.DeletedAt), To: formatTime(after.DeletedAt)},
+		{Field: "deletedBy", From: before.DeletedBy, To: after.DeletedBy},
+		{Field: "tags", From: formatMetadata(before.Tags), To: formatMetadata(after.Tags)},
+		{Field: "labels", From: formatMetadata(before.Labels), To: formatMetadata(after.Labels)},
+		{Field: "attributes", From: formatMetadata(before.Attributes), To: formatMetadata(after.Attributes)},
+	}
+
+	changes := make([]FieldChange, 0, len(fields))
+	for _, field := range fields {
+		if field.From != field.To {
+			changes = append(changes, field)
+		}
+	}
+
+	return changes
+}
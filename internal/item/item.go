@@ -4,11 +4,13 @@ package item
 import (
 	"context"
 	"fmt"
-	"math/rand/v2"
 	"time"
 
 	"github.com/naughtygopher/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/singleflight"
 
+	"github.com/prashantkr001/template-go/internal/pkg/apm"
 	"github.com/prashantkr001/template-go/internal/pkg/logger"
 )
 
@@ -17,16 +19,82 @@ var (
 
 	ErrNotFound      = errors.NotFound("Item not found")
 	ErrDuplicateItem = errors.Duplicate("Item with the same ID already exists")
+	// ErrVersionConflict is returned when an item was modified by someone else, after the
+	// version the caller is trying to modify was read.
+	ErrVersionConflict = errors.Duplicate("Item was modified concurrently, version mismatch")
+	ErrNotDeleted      = errors.Validation("Item is not deleted")
 )
 
 type Item struct {
-	ID   int    `json:"id,omitempty"`
-	Name string `json:"name,omitempty"`
+	// Tenant is the owner of the item, IDs are unique per tenant. It is always the tenant of the
+	// context the item is created with, irrespective of the value provided.
+	Tenant string `json:"tenant,omitempty" bson:"tenant"`
+	ID     int    `json:"id,omitempty" bson:"id"`
+	Name   string `json:"name,omitempty" bson:"name"`
+	// Version is incremented on every modification of the item, starting from 1.
+	// It is used for optimistic concurrency control of updates.
+	Version   int64     `json:"version,omitempty" bson:"version"`
+	CreatedAt time.Time `json:"createdAt,omitzero" bson:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt,omitzero" bson:"updatedAt"`
+	// DeletedAt is set if the item is (soft) deleted, deleted items are purged after the retention period
+	DeletedAt *time.Time `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"`
+	// DeletedBy is the actor who deleted the item
+	DeletedBy string `json:"deletedBy,omitempty" bson:"deletedBy,omitempty"`
+	// Tags is a set, i.e. the tags are sorted & duplicates are removed
+	Tags       []string             `json:"tags,omitempty" bson:"tags,omitempty"`
+	Labels     map[string]string    `json:"labels,omitempty" bson:"labels,omitempty"`
+	Attributes map[string]Attribute `json:"attributes,omitempty" bson:"attributes,omitempty"`
 }
 
-func (it *Item) Validate() error {
-	if it.ID <= 0 {
-		return errors.Wrap(ErrInvalidID, fmt.Sprintf("'%d'", it.ID))
+func (it *Item) IsDeleted() bool {
+	return it.DeletedAt != nil
+}
+
+// Patch holds the fields of an Item which can be updated partially. Fields which are nil
+// are left untouched.
+type Patch struct {
+	Name *string `json:"name,omitempty"`
+	// Tags, Labels & Attributes if non-nil, replace the existing ones entirely. i.e. an empty
+	// (non-nil) value removes all of them.
+	Tags       []string             `json:"tags,omitempty"`
+	Labels     map[string]string    `json:"labels,omitempty"`
+	Attributes map[string]Attribute `json:"attributes,omitempty"`
+	// Version if > 0, is the version of the item on which the patch is expected to be applied
+	Version int64 `json:"version,omitempty"`
+}
+
+func (pt *Patch) IsEmpty() bool {
+	return pt.Name == nil && pt.Tags == nil && pt.Labels == nil && pt.Attributes == nil
+}
+
+// Validate validates the patch using the validator, the default one is used if nil
+func (pt *Patch) Validate(validator *Validator) error {
+	if validator == nil {
+		validator = defaultValidator
+	}
+	return validator.Patch(pt)
+}
+
+// Validate validates the item using the validator, the default one is used if nil. All the
+// violations are reported at once, as a ValidationError.
+func (it *Item) Validate(validator *Validator) error {
+	if validator == nil {
+		validator = defaultValidator
+	}
+	return validator.Item(it)
+}
+
+// validateNamed validates the item after it's named by the Namer, the default validator is used if nil
+func (it *Item) validateNamed(validator *Validator, requested string) error {
+	if validator == nil {
+		validator = defaultValidator
+	}
+	return validator.NamedItem(requested, it)
+}
+
+func validateID(id int) error {
+	if id <= 0 {
+		return errors.Wrap(ErrInvalidID, fmt.Sprintf("'%d'", id))
 	}
 	return nil
 }
@@ -36,76 +104,423 @@ func (it *Item) Validate() error {
 // And all its usecases as methods(with pointer receiver) of this struct.
 type Service struct {
 	persistentStore persistentStore
+	cache           Cache
 	publisher       publisher
+	namer           Namer
+	cfg             Config
+	validator       *Validator
+	dispatcher      *dispatcher
+	// lookups collapses concurrent store lookups of the same item, upon cache misses
+	lookups *singleflight.Group
+}
+
+type Config struct {
+	// UseOutbox if true, the events are written to the outbox in the same transaction as the
+	// respective change, instead of publishing directly. OutboxRelay is then responsible for publishing.
+	UseOutbox bool
+
+	// PublishWorkers is the number of goroutines publishing events concurrently
+	PublishWorkers int
+	// PublishQueueDepth is the maximum number of events waiting to be published
+	PublishQueueDepth int
+	// PublishQueueFullPolicy is one of the QueueFull* constants, defaults to QueueFullBlock
+	PublishQueueFullPolicy string
+	// PublishTimeout is the time allowed to publish a single event, including retries
+	PublishTimeout time.Duration
+	// EventSource is set as the source of all the events, it should identify the app & its version
+	EventSource string
+	// Limits of the tags, labels & attributes of items, the defaults are used for the ones not set
+	Limits Limits
+	// Rules are the validation rules of items, in addition to the limits
+	Rules Rules
 }
 
 // NewService accepts any external dependencies required for the campaign service.
-// e.g. DB driver.
-func NewService(storage persistentStore, pub publisher) (*Service, error) {
-	return &Service{
+// e.g. DB driver. The cache is optional, items are always looked up in the store if it's nil.
+func NewService(storage persistentStore, itemCache Cache, pub publisher, namer Namer, cfg *Config) (*Service, error) {
+	const (
+		defaultPublishWorkers    = 4
+		defaultPublishQueueDepth = 1024
+		defaultPublishTimeout    = time.Second * 3
+	)
+
+	if namer == nil {
+		namer = RandomSuffixNamer()
+	}
+	if itemCache == nil {
+		itemCache = noCache{}
+	}
+	scfg := Config{}
+	if cfg != nil {
+		scfg = *cfg
+	}
+	if scfg.PublishWorkers <= 0 {
+		scfg.PublishWorkers = defaultPublishWorkers
+	}
+	if scfg.PublishQueueDepth <= 0 {
+		scfg.PublishQueueDepth = defaultPublishQueueDepth
+	}
+	if scfg.PublishTimeout <= 0 {
+		scfg.PublishTimeout = defaultPublishTimeout
+	}
+	switch scfg.PublishQueueFullPolicy {
+	case "":
+		scfg.PublishQueueFullPolicy = QueueFullBlock
+	case QueueFullBlock, QueueFullDrop, QueueFullFail:
+	default:
+		return nil, errors.Wrapf(ErrUnknownQueueFullPolicy, ": %s", scfg.PublishQueueFullPolicy)
+	}
+	validator, err := NewValidator(scfg.Limits, scfg.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	svc := &Service{
 		persistentStore: storage,
+		cache:           itemCache,
 		publisher:       pub,
-	}, nil
+		namer:           namer,
+		cfg:             scfg,
+		validator:       validator,
+		lookups:         &singleflight.Group{},
+	}
+	// with outbox enabled, the events are published by the relay
+	if !scfg.UseOutbox {
+		svc.dispatcher = newDispatcher(pub, &scfg)
+	}
+
+	return svc, nil
+}
+
+// Drain stops publishing of new events, and waits for the pending events to be published. It should
+// be called during shutdown, after the APIs are stopped and before the publisher is closed.
+func (svc *Service) Drain(ctx context.Context) error {
+	if svc.dispatcher == nil {
+		return nil
+	}
+	return svc.dispatcher.drain(ctx)
 }
 
 func (svc *Service) Create(ctx context.Context, item Item) (*Item, error) {
+	tenant, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// do validations of values in item here or other business logic
-	err := item.Validate()
+	item.Tenant = tenant
+	item.Tags = normalizeTags(item.Tags)
+	err = item.Validate(svc.validator)
 	if err != nil {
 		return nil, err
 	}
 
-	// my *business logic* requires Item name to be suffixed with a random number
-	item.Name = fmt.Sprintf("%s-%d", item.Name, rand.Int()) //nolint:gosec // G404: Non-crypto usage, just for naming uniqueness
+	// my *business logic* requires Item name to be suffixed, based on the configured naming strategy
+	requested := item.Name
+	item.Name = svc.namer.Name(&item)
+	err = item.validateNamed(svc.validator, requested)
+	if err != nil {
+		return nil, err
+	}
+	item.Version = 1
+	item.CreatedAt = time.Now().UTC()
+	item.UpdatedAt = item.CreatedAt
 
-	newItem, err := svc.persistentStore.InsertItem(ctx, item)
+	// the newly created item is published for all dependencies to consume, either by the
+	// publish dispatcher or by the outbox relay (if enabled)
+	newItem, err := svc.applyChange(ctx, EventItemCreated, func(ctx context.Context) (*Item, *Item, error) {
+		created, ierr := svc.persistentStore.InsertItem(ctx, tenant, item)
+		return nil, created, ierr
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// publish the newly created items, for all dependencies to consume
-	go func() {
-		// since this is asynchronous, maybe implement some retry logic if required
-		const publishTimeout = time.Second * 3
-		gctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
-		defer cancel()
+	return newItem, nil
+}
 
-		perr := svc.publisher.Publish(gctx, newItem)
-		if perr != nil {
-			logger.ErrWithStacktrace(perr)
-			return
+// itemChange makes a change using the store, and returns the item before (nil if created) and
+// after the change.
+type itemChange func(ctx context.Context) (before *Item, after *Item, err error)
+
+// applyChange makes the change using the store, records it in the history of the item, writes it
+// through to the cache and publishes the respective event, either via the publish dispatcher or
+// the outbox (if enabled).
+func (svc *Service) applyChange(ctx context.Context, eventType string, change itemChange) (*Item, error) {
+	if svc.cfg.UseOutbox {
+		changed, err := svc.applyChangeWithOutbox(ctx, eventType, change)
+		if err != nil {
+			return nil, err
 		}
-		logger.Info(fmt.Sprintf("published to kafka: %v", newItem))
-	}()
+		svc.cacheSet(ctx, changed)
+		svc.changed(ctx, eventType, changed)
+		return changed, nil
+	}
 
-	// if you have a cache, set the cache here. Similar to publish events
-	// you might want to implement retry mechanism for writing to cache.
-	// Also, would be a good idea to do it asynchronously, since the API
-	// need not be (depends on how critical you think this is) blocked.
-	// Remember to log the erorr if you're setting the cache asynchronously
+	// the slot is reserved before the change, so that the queue full policy is applied before any change
+	reserved, err := svc.dispatcher.reserve(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	return newItem, nil
+	before, changed, err := change(ctx)
+	if err != nil {
+		if reserved {
+			svc.dispatcher.release()
+		}
+		return nil, err
+	}
+
+	svc.recordHistory(ctx, newHistoryEntry(ctx, eventType, before, changed))
+	svc.cacheSet(ctx, changed)
+	svc.changed(ctx, eventType, changed)
+
+	event := svc.newEvent(ctx, eventType, changed)
+	if reserved {
+		svc.dispatcher.enqueue(event)
+	} else {
+		svc.dispatcher.dropped(ctx, event, "publish queue is full")
+	}
+
+	return changed, nil
 }
 
-func (svc *Service) CreateIfNotExist(ctx context.Context, item Item) (*Item, error) {
-	_, err := svc.persistentStore.Item(ctx, item.ID)
-	if err != nil && !errors.Is(err, ErrNotFound) {
+// applyChangeWithOutbox makes the change, its history and outbox record atomic
+func (svc *Service) applyChangeWithOutbox(ctx context.Context, eventType string, change itemChange) (*Item, error) {
+	var changed *Item
+	err := svc.persistentStore.Transaction(ctx, func(tctx context.Context) error {
+		before, after, cerr := change(tctx)
+		if cerr != nil {
+			return cerr
+		}
+		changed = after
+
+		cerr = svc.persistentStore.InsertHistory(tctx, []HistoryEntry{newHistoryEntry(tctx, eventType, before, after)})
+		if cerr != nil {
+			return cerr
+		}
+		return svc.persistentStore.InsertOutbox(tctx, newOutboxRecord(svc.newEvent(tctx, eventType, changed)))
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// expected ErrNotFound
-	if err == nil {
-		return nil, errors.Wrapf(ErrDuplicateItem, ": %d", item.ID)
+	return changed, nil
+}
+
+// changed counts the changes of items, per tenant & type of change
+func (svc *Service) changed(ctx context.Context, eventType string, it *Item) {
+	apm.Global().AppMeter().CounterAdd(
+		ctx,
+		"item.changes",
+		1,
+		attribute.String("tenant", it.Tenant),
+		attribute.String("type", eventType),
+	)
+}
+
+// recordHistory is used when the change is already done outside of a transaction, so failing to
+// record the history is only logged, instead of failing the change.
+func (svc *Service) recordHistory(ctx context.Context, entries ...HistoryEntry) {
+	if len(entries) == 0 {
+		return
+	}
+
+	err := svc.persistentStore.InsertHistory(ctx, entries)
+	if err != nil {
+		apm.Global().AppMeter().CounterAdd(ctx, "item.history.failed", float64(len(entries)), attribute.String("tenant", Tenant(ctx)))
+		logger.ErrWithStacktrace(errors.Wrapf(err, "could not record the history of %d item change(s)", len(entries)))
+	}
+}
+
+// maxModifyAttempts is the number of attempts of a modification which does not expect a specific
+// version, when the item is modified concurrently.
+const maxModifyAttempts = 3
+
+// modify applies the change on the current version of the item, so that the history has the exact
+// diff of the change. If version is 0, i.e. the caller does not expect a specific version, the
+// change is retried upon a concurrent modification of the item.
+func (svc *Service) modify(
+	ctx context.Context,
+	eventType string,
+	tenant string,
+	id int,
+	version int64,
+	change func(ctx context.Context, version int64) (*Item, error),
+) (*Item, error) {
+	for attempt := 1; ; attempt++ {
+		changed, err := svc.applyChange(ctx, eventType, func(ctx context.Context) (*Item, *Item, error) {
+			before, ierr := svc.persistentStore.Item(ctx, tenant, id)
+			if ierr != nil {
+				return nil, nil, ierr
+			}
+
+			expected := version
+			if expected == 0 {
+				expected = before.Version
+			}
+			after, ierr := change(ctx, expected)
+			return before, after, ierr
+		})
+		if version == 0 && attempt < maxModifyAttempts && errors.Is(err, ErrVersionConflict) {
+			continue
+		}
+		return changed, err
 	}
+}
 
+// CreateIfNotExist returns ErrDuplicateItem if an item with the same ID exists. The uniqueness is
+// enforced by the store, so concurrent creates of the same ID (e.g. from the HTTP server & the Kafka
+// subscriber) are safe.
+func (svc *Service) CreateIfNotExist(ctx context.Context, item Item) (*Item, error) {
 	return svc.Create(ctx, item)
 }
 
-func (svc *Service) List(ctx context.Context, limit int) ([]Item, error) {
-	list, err := svc.persistentStore.ListItems(ctx, limit)
+// List returns a page of items matching the query. Use ListResult.NextCursor as the query cursor
+// to get the subsequent page.
+func (svc *Service) List(ctx context.Context, query ListQuery) (*ListResult, error) {
+	tenant, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = query.normalize()
+	if err != nil {
+		return nil, err
+	}
+	err = svc.validator.ListQuery(&query)
+	if err != nil {
+		return nil, err
+	}
+
+	// fetching one extra item to know if there's a next page
+	pageSize := query.Limit
+	query.Limit++
+	list, err := svc.persistentStore.ListItems(ctx, tenant, query)
+	if err != nil {
+		return nil, err
+	}
+	query.Limit = pageSize
+
+	result := &ListResult{Items: list}
+	if len(list) > pageSize {
+		result.Items = list[:pageSize]
+		result.NextCursor = cursorOf(&result.Items[pageSize-1], &query).encode()
+	}
+
+	return result, nil
+}
+
+// Get returns ErrNotFound for deleted items, unless includeDeleted is true
+func (svc *Service) Get(ctx context.Context, id int, includeDeleted bool) (*Item, error) {
+	tenant, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = validateID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	it, err := svc.cachedItem(ctx, tenant, id)
+	if err != nil {
+		return nil, err
+	}
+	if it.IsDeleted() && !includeDeleted {
+		return nil, ErrNotFound
+	}
+
+	return it, nil
+}
+
+// Update replaces all the modifiable fields of an existing item with the ones provided.
+// If item.Version is > 0, the update is applied only if the stored item is still of the
+// same version, ErrVersionConflict is returned otherwise.
+func (svc *Service) Update(ctx context.Context, item Item) (*Item, error) {
+	tenant, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Tenant = tenant
+	item.Tags = normalizeTags(item.Tags)
+	err = item.Validate(svc.validator)
+	if err != nil {
+		return nil, err
+	}
+	item.UpdatedAt = time.Now().UTC()
+
+	return svc.modify(ctx, EventItemUpdated, tenant, item.ID, item.Version, func(ctx context.Context, version int64) (*Item, error) {
+		item.Version = version
+		return svc.persistentStore.UpdateItem(ctx, tenant, item)
+	})
+}
+
+// Patch updates only the fields of an existing item which are set in the patch.
+func (svc *Service) Patch(ctx context.Context, id int, patch Patch) (*Item, error) {
+	tenant, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = validateID(id)
+	if err != nil {
+		return nil, err
+	}
+	patch.Tags = normalizeTags(patch.Tags)
+	err = patch.Validate(svc.validator)
+	if err != nil {
+		return nil, err
+	}
+
+	if patch.IsEmpty() {
+		it, gerr := svc.Get(ctx, id, false)
+		if gerr != nil {
+			return nil, gerr
+		}
+		if patch.Version > 0 && it.Version != patch.Version {
+			return nil, errors.Wrapf(ErrVersionConflict, ": %d", id)
+		}
+		return it, nil
+	}
+
+	return svc.modify(ctx, EventItemUpdated, tenant, id, patch.Version, func(ctx context.Context, version int64) (*Item, error) {
+		patch.Version = version
+		return svc.persistentStore.PatchItem(ctx, tenant, id, patch, time.Now().UTC())
+	})
+}
+
+// Delete soft deletes the item, recording the actor of the context as the one who deleted it.
+// If version is > 0, it is deleted only if the stored item is still of the same version.
+// Deleted items are purged by the Purger, after the retention period.
+func (svc *Service) Delete(ctx context.Context, id int, version int64) error {
+	tenant, err := tenantOf(ctx)
+	if err != nil {
+		return err
+	}
+	err = validateID(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = svc.modify(ctx, EventItemDeleted, tenant, id, version, func(ctx context.Context, version int64) (*Item, error) {
+		return svc.persistentStore.DeleteItem(ctx, tenant, id, version, time.Now().UTC(), Actor(ctx))
+	})
+
+	return err
+}
+
+// Restore undoes the deletion of an item which is not purged yet. It returns ErrNotDeleted if
+// the item is not deleted.
+func (svc *Service) Restore(ctx context.Context, id int, version int64) (*Item, error) {
+	tenant, err := tenantOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = validateID(id)
 	if err != nil {
 		return nil, err
 	}
 
-	return list, nil
+	return svc.modify(ctx, EventItemRestored, tenant, id, version, func(ctx context.Context, version int64) (*Item, error) {
+		return svc.persistentStore.RestoreItem(ctx, tenant, id, version, time.Now().UTC())
+	})
 }
@@ -3,21 +3,30 @@ package item
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/naughtygopher/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"github.com/prashantkr001/template-go/internal/pkg/cloudevents"
+	"github.com/prashantkr001/template-go/internal/pkg/resilience"
 )
 
 type pubMocker struct {
 	pipe chan<- []byte
 }
 
-func (pMo *pubMocker) Publish(_ context.Context, item *Item) error {
-	jbytes, err := json.Marshal(item)
+func (pMo *pubMocker) Publish(_ context.Context, event *Event) error {
+	jbytes, err := json.Marshal(event)
 	if err != nil {
 		return errors.Wrap(err, "json marshal failed")
 	}
@@ -32,16 +41,88 @@ func newPubMocker(pipe chan<- []byte) *pubMocker {
 	}
 }
 
+// storeMocker is of a single tenant, the tenant isolation is tested with the memory store
 type storeMocker struct {
-	data map[int]Item
+	data    map[int]Item
+	outbox  []OutboxRecord
+	history []HistoryEntry
+}
+
+func (sMo *storeMocker) InsertHistory(_ context.Context, entries []HistoryEntry) error {
+	sMo.history = append(sMo.history, entries...)
+	return nil
+}
+
+func (sMo *storeMocker) ListHistory(_ context.Context, _ string, itemID int, query HistoryQuery) ([]HistoryEntry, error) {
+	list := make([]HistoryEntry, 0, query.Limit)
+	for _, entry := range slices.Backward(sMo.history) {
+		if entry.ItemID == itemID && (query.Cursor == "" || entry.ID < query.Cursor) && len(list) < query.Limit {
+			list = append(list, entry)
+		}
+	}
+	return list, nil
+}
+
+func (sMo *storeMocker) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
 }
 
-func (sMo *storeMocker) InsertItem(_ context.Context, item Item) (*Item, error) {
+func (sMo *storeMocker) InsertOutbox(_ context.Context, record OutboxRecord) error {
+	sMo.outbox = append(sMo.outbox, record)
+	return nil
+}
+
+func (sMo *storeMocker) PendingOutbox(_ context.Context, limit int) ([]OutboxRecord, error) {
+	list := make([]OutboxRecord, 0, limit)
+	for _, record := range sMo.outbox {
+		if record.SentAt == nil && len(list) < limit {
+			list = append(list, record)
+		}
+	}
+	return list, nil
+}
+
+func (sMo *storeMocker) MarkOutboxSent(_ context.Context, ids []string, sentAt time.Time) error {
+	for idx := range sMo.outbox {
+		if slices.Contains(ids, sMo.outbox[idx].ID) {
+			sMo.outbox[idx].SentAt = &sentAt
+		}
+	}
+	return nil
+}
+
+func (sMo *storeMocker) OutboxStats(_ context.Context) (*OutboxStats, error) {
+	stats := &OutboxStats{}
+	for _, record := range sMo.outbox {
+		if record.SentAt != nil {
+			continue
+		}
+		if stats.Pending == 0 {
+			stats.OldestCreatedAt = record.CreatedAt
+		}
+		stats.Pending++
+	}
+	return stats, nil
+}
+
+func (sMo *storeMocker) InsertItem(_ context.Context, tenant string, item Item) (*Item, error) {
+	if _, ok := sMo.data[item.ID]; ok {
+		return nil, errors.Wrapf(ErrDuplicateItem, ": %d", item.ID)
+	}
+	item.Tenant = tenant
 	sMo.data[item.ID] = item
 	return &item, nil
 }
 
-func (sMo *storeMocker) Item(_ context.Context, id int) (*Item, error) {
+func (sMo *storeMocker) InsertItems(ctx context.Context, tenant string, items []Item) ([]error, error) {
+	itemErrs := make([]error, len(items))
+	for idx, item := range items {
+		_, itemErrs[idx] = sMo.InsertItem(ctx, tenant, item)
+	}
+	return itemErrs, nil
+}
+
+func (sMo *storeMocker) Item(_ context.Context, _ string, id int) (*Item, error) {
 	item, ok := sMo.data[id]
 	if !ok {
 		return nil, ErrNotFound
@@ -49,18 +130,84 @@ func (sMo *storeMocker) Item(_ context.Context, id int) (*Item, error) {
 	return &item, nil
 }
 
-func (sMo *storeMocker) ListItems(_ context.Context, limit int) ([]Item, error) {
-	list := make([]Item, 0, limit)
+func (sMo *storeMocker) ListItems(_ context.Context, _ string, query ListQuery) ([]Item, error) {
+	list := make([]Item, 0, query.Limit)
 	for idx := range sMo.data {
-		list = append(list, sMo.data[idx])
-		limit--
-		if limit == 0 {
-			break
+		item := sMo.data[idx]
+		if query.matches(&item) {
+			list = append(list, item)
 		}
 	}
+	slices.SortFunc(list, func(a, b Item) int {
+		return query.compare(&a, &b)
+	})
+	if len(list) > query.Limit {
+		list = list[:query.Limit]
+	}
 	return list, nil
 }
 
+func (sMo *storeMocker) UpdateItem(_ context.Context, _ string, item Item) (*Item, error) {
+	stored, err := sMo.casItem(item.ID, item.Version, false)
+	if err != nil {
+		return nil, err
+	}
+	item.CreatedAt = stored.CreatedAt
+	item.Version = stored.Version + 1
+	sMo.data[item.ID] = item
+	return &item, nil
+}
+
+func (sMo *storeMocker) PatchItem(_ context.Context, _ string, id int, patch Patch, updatedAt time.Time) (*Item, error) {
+	item, err := sMo.casItem(id, patch.Version, false)
+	if err != nil {
+		return nil, err
+	}
+	if patch.Name != nil {
+		item.Name = *patch.Name
+	}
+	item.Version++
+	item.UpdatedAt = updatedAt
+	sMo.data[id] = item
+	return &item, nil
+}
+
+func (sMo *storeMocker) DeleteItem(_ context.Context, _ string, id int, version int64, deletedAt time.Time, deletedBy string) (*Item, error) {
+	item, err := sMo.casItem(id, version, false)
+	if err != nil {
+		return nil, err
+	}
+	item.DeletedAt = &deletedAt
+	item.DeletedBy = deletedBy
+	item.Version++
+	sMo.data[id] = item
+	return &item, nil
+}
+
+func (sMo *storeMocker) RestoreItem(_ context.Context, _ string, id int, version int64, restoredAt time.Time) (*Item, error) {
+	item, err := sMo.casItem(id, version, true)
+	if err != nil {
+		return nil, err
+	}
+	item.DeletedAt = nil
+	item.DeletedBy = ""
+	item.UpdatedAt = restoredAt
+	item.Version++
+	sMo.data[id] = item
+	return &item, nil
+}
+
+func (sMo *storeMocker) casItem(id int, version int64, deleted bool) (Item, error) {
+	item, ok := sMo.data[id]
+	if !ok {
+		return item, ErrNotFound
+	}
+	if item.IsDeleted() != deleted || (version > 0 && item.Version != version) {
+		return item, casError(&item, nil, id, deleted)
+	}
+	return item, nil
+}
+
 func newStoreMocker() *storeMocker {
 	return &storeMocker{
 		data: make(map[int]Item),
@@ -81,21 +228,25 @@ func TestInsertItem(t *testing.T) {
 	pipe := make(chan []byte, 128)
 	pmo := newPubMocker(pipe)
 	smo := newStoreMocker()
-	svc, err := NewService(smo, pmo)
+	svc, err := NewService(smo, nil, pmo, HashSuffixNamer(), &Config{EventSource: "template-test"})
 	requirer.NoError(err)
 	ctx := t.Context()
 
 	item := Item{
-		ID:   123,
-		Name: "Bottle",
+		// the tenant is always set from the context, irrespective of the one provided
+		Tenant: DefaultTenant,
+		ID:     123,
+		Name:   "Bottle",
 	}
 	insertedItem, err := svc.CreateIfNotExist(ctx, item)
 	requirer.NoError(err)
 
 	t.Run("testing the special number suffix business logic", func(_ *testing.T) {
+		// the hash strategy is deterministic, so the exact name is known
+		asserter.Equal("Bottle-5003431119771845851", insertedItem.Name)
 		parts := strings.Split(insertedItem.Name, "-")
 		if asserter.Len(parts, 2) {
-			_, err = strconv.Atoi(parts[1])
+			_, err = strconv.ParseUint(parts[1], 10, 64)
 			requirer.NoError(err)
 		}
 	})
@@ -107,20 +258,35 @@ func TestInsertItem(t *testing.T) {
 			// while testing equality, the random generated number suffix is removed
 			storedItem.Name = parts[0]
 		}
-		asserter.Equal(item, storedItem)
+		asserter.Equal(int64(1), storedItem.Version)
+		asserter.False(storedItem.CreatedAt.IsZero())
+		asserter.Equal(storedItem.CreatedAt, storedItem.UpdatedAt)
+		asserter.Equal(item, withoutVersion(storedItem))
 	})
 
 	t.Run("check if item was pushed to the publisher", func(_ *testing.T) {
 		pBytes := <-pipe
-		pubItem := Item{}
-		err = json.Unmarshal(pBytes, &pubItem)
+		event := Event{}
+		err = json.Unmarshal(pBytes, &event)
 		requirer.NoError(err)
+		asserter.Equal(EventItemCreated, event.Type)
+		asserter.Equal(EventSchemaVersion, event.SchemaVersion)
+		asserter.Equal("template-test", event.Source)
+		asserter.Equal("123", event.Key())
+		asserter.NotEmpty(event.ID)
+
+		pubItem := event.Payload
 		parts := strings.Split(pubItem.Name, "-")
 		if asserter.Len(parts, 2) {
 			// while testing equality, the random generated number suffix is removed
 			pubItem.Name = parts[0]
 		}
-		asserter.Equal(item, pubItem)
+		asserter.Equal(item, withoutVersion(pubItem))
+	})
+
+	t.Run("duplicates are rejected by the store", func(_ *testing.T) {
+		_, err = svc.CreateIfNotExist(ctx, item)
+		requirer.ErrorIs(err, ErrDuplicateItem)
 	})
 
 	t.Run("testing if the ID validations are in place", func(_ *testing.T) {
@@ -132,3 +298,821 @@ func TestInsertItem(t *testing.T) {
 		requirer.ErrorIs(err, ErrInvalidID)
 	})
 }
+
+// TestUpdateDeleteItem ensures the get, update, patch & delete lifecycle of an item
+func TestUpdateDeleteItem(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+	smo := newStoreMocker()
+	pipe := make(chan []byte, 128)
+	svc, err := NewService(smo, nil, newPubMocker(pipe), NoopNamer(), nil)
+	requirer.NoError(err)
+	ctx := t.Context()
+
+	smo.data[7] = Item{Tenant: DefaultTenant, ID: 7, Name: "Cup", Version: 1}
+
+	t.Run("get an existing item", func(_ *testing.T) {
+		it, gerr := svc.Get(ctx, 7, false)
+		requirer.NoError(gerr)
+		asserter.Equal(Item{Tenant: DefaultTenant, ID: 7, Name: "Cup", Version: 1}, *it)
+	})
+
+	t.Run("full update replaces the item", func(_ *testing.T) {
+		it, uerr := svc.Update(ctx, Item{ID: 7, Name: "Mug"})
+		requirer.NoError(uerr)
+		asserter.Equal("Mug", it.Name)
+		asserter.Equal(int64(2), it.Version)
+		asserter.Equal("Mug", smo.data[7].Name)
+	})
+
+	t.Run("patch updates only the fields provided", func(_ *testing.T) {
+		name := "Tumbler"
+		it, perr := svc.Patch(ctx, 7, Patch{Name: &name, Version: 2})
+		requirer.NoError(perr)
+		asserter.Equal(Item{Tenant: DefaultTenant, ID: 7, Name: "Tumbler", Version: 3}, withoutTimestamps(*it))
+
+		it, perr = svc.Patch(ctx, 7, Patch{})
+		requirer.NoError(perr)
+		asserter.Equal(Item{Tenant: DefaultTenant, ID: 7, Name: "Tumbler", Version: 3}, withoutTimestamps(*it))
+	})
+
+	t.Run("stale versions are rejected", func(_ *testing.T) {
+		_, uerr := svc.Update(ctx, Item{ID: 7, Name: "Glass", Version: 2})
+		requirer.ErrorIs(uerr, ErrVersionConflict)
+
+		name := "Glass"
+		_, perr := svc.Patch(ctx, 7, Patch{Name: &name, Version: 1})
+		requirer.ErrorIs(perr, ErrVersionConflict)
+
+		requirer.ErrorIs(svc.Delete(ctx, 7, 2), ErrVersionConflict)
+		asserter.Equal("Tumbler", smo.data[7].Name)
+	})
+
+	t.Run("delete soft deletes the item", func(_ *testing.T) {
+		requirer.NoError(svc.Delete(WithActor(ctx, "ops@example.com"), 7, 3))
+		_, gerr := svc.Get(ctx, 7, false)
+		requirer.ErrorIs(gerr, ErrNotFound)
+		requirer.ErrorIs(svc.Delete(ctx, 7, 0), ErrNotFound)
+		_, uerr := svc.Update(ctx, Item{ID: 7, Name: "Glass"})
+		requirer.ErrorIs(uerr, ErrNotFound)
+
+		it, gerr := svc.Get(ctx, 7, true)
+		requirer.NoError(gerr)
+		asserter.True(it.IsDeleted())
+		asserter.Equal("ops@example.com", it.DeletedBy)
+		asserter.Equal(int64(4), it.Version)
+
+		result, lerr := svc.List(ctx, ListQuery{})
+		requirer.NoError(lerr)
+		asserter.Empty(result.Items)
+		result, lerr = svc.List(ctx, ListQuery{IncludeDeleted: true})
+		requirer.NoError(lerr)
+		asserter.Len(result.Items, 1)
+	})
+
+	t.Run("restore undoes the deletion", func(_ *testing.T) {
+		_, rerr := svc.Restore(ctx, 7, 3)
+		requirer.ErrorIs(rerr, ErrVersionConflict)
+
+		it, rerr := svc.Restore(ctx, 7, 4)
+		requirer.NoError(rerr)
+		asserter.False(it.IsDeleted())
+		asserter.Empty(it.DeletedBy)
+		asserter.Equal(int64(5), it.Version)
+
+		_, rerr = svc.Restore(ctx, 7, 0)
+		requirer.ErrorIs(rerr, ErrNotDeleted)
+	})
+
+	t.Run("updating a missing item fails", func(_ *testing.T) {
+		_, uerr := svc.Update(ctx, Item{ID: 8, Name: "Plate"})
+		requirer.ErrorIs(uerr, ErrNotFound)
+		_, uerr = svc.Update(ctx, Item{ID: 0, Name: "Plate"})
+		requirer.ErrorIs(uerr, ErrInvalidID)
+	})
+
+	t.Run("an event is published for every successful change", func(_ *testing.T) {
+		requirer.NoError(svc.Drain(ctx))
+		close(pipe)
+
+		published := []string{}
+		for pbytes := range pipe {
+			event := Event{}
+			requirer.NoError(json.Unmarshal(pbytes, &event))
+			asserter.Equal("7", event.Key())
+			published = append(published, fmt.Sprintf("%s:%d", event.Type, event.Payload.Version))
+		}
+		asserter.Equal([]string{"item.updated:2", "item.updated:3", "item.deleted:4", "item.restored:5"}, published)
+	})
+}
+
+func TestItemHistory(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+	smo := newStoreMocker()
+	svc, err := NewService(smo, nil, newPubMocker(make(chan []byte, 128)), NoopNamer(), nil)
+	requirer.NoError(err)
+	ctx := WithActor(t.Context(), "alice")
+
+	_, err = svc.Create(ctx, Item{ID: 3, Name: "Pot"})
+	requirer.NoError(err)
+	_, err = svc.Update(WithActor(ctx, "bob"), Item{ID: 3, Name: "Kettle"})
+	requirer.NoError(err)
+	name := "Kettle"
+	// a patch without any change is recorded as well, since the version is incremented
+	_, err = svc.Patch(ctx, 3, Patch{Name: &name})
+	requirer.NoError(err)
+	requirer.NoError(svc.Delete(ctx, 3, 0))
+	_, err = svc.Restore(ctx, 3, 0)
+	requirer.NoError(err)
+
+	t.Run("every change is recorded with its actor and diff, newest first", func(_ *testing.T) {
+		result, herr := svc.History(ctx, 3, HistoryQuery{})
+		requirer.NoError(herr)
+		requirer.Len(result.Entries, 5)
+		asserter.Empty(result.NextCursor)
+
+		summary := []string{}
+		for _, entry := range result.Entries {
+			asserter.Equal(3, entry.ItemID)
+			summary = append(summary, fmt.Sprintf("%s:%d:%s", entry.Action, entry.Version, entry.Actor))
+		}
+		asserter.Equal([]string{
+			"restored:5:alice",
+			"deleted:4:alice",
+			"updated:3:alice",
+			"updated:2:bob",
+			"created:1:alice",
+		}, summary)
+
+		asserter.Equal([]FieldChange{{Field: "name", From: "", To: "Pot"}}, result.Entries[4].Changes)
+		asserter.Equal([]FieldChange{{Field: "name", From: "Pot", To: "Kettle"}}, result.Entries[3].Changes)
+		asserter.Empty(result.Entries[2].Changes)
+
+		deleted := result.Entries[1].Changes
+		requirer.Len(deleted, 2)
+		asserter.Equal(FieldChange{Field: "deletedBy", From: "", To: "alice"}, deleted[1])
+		asserter.Equal(deleted[0].To, result.Entries[0].Changes[0].From)
+	})
+
+	t.Run("history is paginated", func(_ *testing.T) {
+		first, herr := svc.History(ctx, 3, HistoryQuery{Limit: 3})
+		requirer.NoError(herr)
+		requirer.Len(first.Entries, 3)
+		requirer.NotEmpty(first.NextCursor)
+
+		second, herr := svc.History(ctx, 3, HistoryQuery{Limit: 3, Cursor: first.NextCursor})
+		requirer.NoError(herr)
+		requirer.Len(second.Entries, 2)
+		asserter.Equal(HistoryCreated, second.Entries[1].Action)
+
+		_, herr = svc.History(ctx, 3, HistoryQuery{Cursor: "haha"})
+		requirer.ErrorIs(herr, ErrInvalidCursor)
+	})
+
+	t.Run("labels are of the newest change of labels, even after the item is purged", func(_ *testing.T) {
+		labels, herr := svc.HistoryLabels(ctx, 3)
+		requirer.NoError(herr)
+		asserter.Empty(labels)
+		_, herr = svc.HistoryLabels(ctx, 4)
+		asserter.ErrorIs(herr, ErrNotFound)
+
+		// the store mocker does not modify labels
+		store, herr := NewMemoryPersistentStore()
+		requirer.NoError(herr)
+		msvc, herr := NewService(store, nil, nil, NoopNamer(), &Config{UseOutbox: true})
+		requirer.NoError(herr)
+		_, herr = msvc.Create(ctx, Item{ID: 1, Name: "Pot", Labels: map[string]string{"owner": "bob"}})
+		requirer.NoError(herr)
+		_, herr = msvc.Patch(ctx, 1, Patch{Labels: map[string]string{"owner": "alice"}})
+		requirer.NoError(herr)
+		_, herr = msvc.Patch(ctx, 1, Patch{Name: &name})
+		requirer.NoError(herr)
+		requirer.NoError(msvc.Delete(ctx, 1, 0))
+		_, herr = store.PurgeItems(ctx, time.Now().Add(time.Minute), 10)
+		requirer.NoError(herr)
+		_, herr = msvc.Get(ctx, 1, true)
+		requirer.ErrorIs(herr, ErrNotFound)
+
+		labels, herr = msvc.HistoryLabels(ctx, 1)
+		requirer.NoError(herr)
+		asserter.Equal(map[string]string{"owner": "alice"}, labels)
+	})
+
+	t.Run("with outbox, the history is recorded in the same transaction", func(_ *testing.T) {
+		osmo := newStoreMocker()
+		osvc, oerr := NewService(osmo, nil, newPubMocker(make(chan []byte, 1)), NoopNamer(), &Config{UseOutbox: true})
+		requirer.NoError(oerr)
+
+		_, oerr = osvc.Create(ctx, Item{ID: 4, Name: "Pan"})
+		requirer.NoError(oerr)
+		_, oerr = osvc.CreateMany(ctx, []Item{{ID: 5}, {ID: 4}})
+		requirer.NoError(oerr)
+		asserter.Len(osmo.history, 2)
+		asserter.Len(osmo.outbox, 2)
+	})
+}
+
+// lookupCounter counts the lookups of items in the store, which block until released
+type lookupCounter struct {
+	*storeMocker
+	lookups atomic.Int32
+	release chan struct{}
+}
+
+func (lc *lookupCounter) Item(ctx context.Context, tenant string, id int) (*Item, error) {
+	lc.lookups.Add(1)
+	<-lc.release
+	return lc.storeMocker.Item(ctx, tenant, id)
+}
+
+// missCounter counts the lookups of items in the cache
+type missCounter struct {
+	*lruCache
+	gets atomic.Int32
+}
+
+func (mc *missCounter) Get(ctx context.Context, tenant string, id int) (*Item, error) {
+	defer mc.gets.Add(1)
+	return mc.lruCache.Get(ctx, tenant, id)
+}
+
+func TestItemCache(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+	ctx := t.Context()
+
+	lru, err := NewLRUCache(10, time.Minute)
+	requirer.NoError(err)
+	counter := &lookupCounter{storeMocker: newStoreMocker(), release: make(chan struct{})}
+	misses := &missCounter{lruCache: lru}
+	svc, err := NewService(counter, misses, newPubMocker(make(chan []byte, 128)), NoopNamer(), nil)
+	requirer.NoError(err)
+	counter.data[1] = Item{Tenant: DefaultTenant, ID: 1, Name: "Cup", Version: 1}
+
+	t.Run("concurrent misses look up the store once", func(_ *testing.T) {
+		const callers = 10
+		wgroup := &sync.WaitGroup{}
+		for range callers {
+			wgroup.Add(1)
+			go func() {
+				defer wgroup.Done()
+				it, gerr := svc.Get(ctx, 1, false)
+				asserter.NoError(gerr)
+				asserter.Equal("Cup", it.Name)
+			}()
+		}
+		requirer.Eventually(func() bool {
+			return misses.gets.Load() == callers
+		}, time.Second, time.Millisecond)
+		// allowing the callers to join the ongoing lookup
+		time.Sleep(time.Millisecond * 20)
+		close(counter.release)
+		wgroup.Wait()
+
+		asserter.Equal(int32(1), counter.lookups.Load())
+		_, gerr := svc.Get(ctx, 1, false)
+		requirer.NoError(gerr)
+		asserter.Equal(int32(1), counter.lookups.Load())
+	})
+
+	t.Run("changes are written through", func(_ *testing.T) {
+		created, cerr := svc.Create(ctx, Item{ID: 2, Name: "Jar"})
+		requirer.NoError(cerr)
+		cached, gerr := lru.Get(ctx, DefaultTenant, 2)
+		requirer.NoError(gerr)
+		asserter.Equal(*created, *cached)
+
+		_, uerr := svc.Update(ctx, Item{ID: 1, Name: "Mug"})
+		requirer.NoError(uerr)
+		cached, gerr = lru.Get(ctx, DefaultTenant, 1)
+		requirer.NoError(gerr)
+		asserter.Equal("Mug", cached.Name)
+		asserter.Equal(int64(2), cached.Version)
+	})
+
+	t.Run("deleted items are cached as deleted", func(_ *testing.T) {
+		requirer.NoError(svc.Delete(ctx, 1, 0))
+		cached, gerr := lru.Get(ctx, DefaultTenant, 1)
+		requirer.NoError(gerr)
+		asserter.True(cached.IsDeleted())
+
+		_, gerr = svc.Get(ctx, 1, false)
+		requirer.ErrorIs(gerr, ErrNotFound)
+		it, gerr := svc.Get(ctx, 1, true)
+		requirer.NoError(gerr)
+		asserter.True(it.IsDeleted())
+
+		// deleted items looked up are not cached
+		lru.now = func() time.Time {
+			return time.Now().Add(time.Minute * 2)
+		}
+		_, gerr = svc.Get(ctx, 1, true)
+		requirer.NoError(gerr)
+		_, gerr = lru.Get(ctx, DefaultTenant, 1)
+		requirer.ErrorIs(gerr, ErrCacheMiss)
+	})
+}
+
+// heldLookup holds the next lookup of an item in the store after reading it, until released. The
+// lookup then returns the item read, or the error of its context.
+type heldLookup struct {
+	*storeMocker
+	hold    atomic.Bool
+	read    chan struct{}
+	release chan struct{}
+}
+
+func (hl *heldLookup) Item(ctx context.Context, tenant string, id int) (*Item, error) {
+	if !hl.hold.CompareAndSwap(true, false) {
+		return hl.storeMocker.Item(ctx, tenant, id)
+	}
+
+	it, err := hl.storeMocker.Item(ctx, tenant, id)
+	hl.read <- struct{}{}
+	<-hl.release
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return it, err
+}
+
+func TestItemCacheConcurrency(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+	ctx := t.Context()
+
+	lru, err := NewLRUCache(10, time.Minute)
+	requirer.NoError(err)
+	held := &heldLookup{storeMocker: newStoreMocker(), read: make(chan struct{}), release: make(chan struct{})}
+	misses := &missCounter{lruCache: lru}
+	svc, err := NewService(held, misses, newPubMocker(make(chan []byte, 128)), NoopNamer(), nil)
+	requirer.NoError(err)
+	held.data[1] = Item{Tenant: DefaultTenant, ID: 1, Name: "Cup", Version: 1}
+	held.data[2] = Item{Tenant: DefaultTenant, ID: 2, Name: "Jar", Version: 1}
+
+	t.Run("items looked up before a change do not replace the changed ones", func(_ *testing.T) {
+		held.hold.Store(true)
+		looked := make(chan *Item, 1)
+		go func() {
+			it, gerr := svc.Get(ctx, 1, false)
+			asserter.NoError(gerr)
+			looked <- it
+		}()
+		<-held.read
+
+		updated, uerr := svc.Update(ctx, Item{ID: 1, Name: "Mug"})
+		requirer.NoError(uerr)
+		requirer.Equal(int64(2), updated.Version)
+		held.release <- struct{}{}
+		asserter.Equal("Cup", (<-looked).Name)
+
+		cached, gerr := lru.Get(ctx, DefaultTenant, 1)
+		requirer.NoError(gerr)
+		asserter.Equal("Mug", cached.Name)
+		asserter.Equal(int64(2), cached.Version)
+
+		// the same for deletes, once the cached item expired
+		lru.now = func() time.Time {
+			return time.Now().Add(time.Minute * 2)
+		}
+		held.hold.Store(true)
+		go func() {
+			_, gerr := svc.Get(ctx, 1, false)
+			asserter.NoError(gerr)
+			looked <- nil
+		}()
+		<-held.read
+		requirer.NoError(svc.Delete(ctx, 1, 0))
+		held.release <- struct{}{}
+		<-looked
+
+		_, gerr = svc.Get(ctx, 1, false)
+		asserter.ErrorIs(gerr, ErrNotFound)
+	})
+
+	t.Run("a cancelled caller does not fail the others", func(_ *testing.T) {
+		held.hold.Store(true)
+		cancelCtx, cancel := context.WithCancel(ctx)
+		cancelled := make(chan error, 1)
+		go func() {
+			_, gerr := svc.Get(cancelCtx, 2, false)
+			cancelled <- gerr
+		}()
+		<-held.read
+
+		gets := misses.gets.Load()
+		waited := make(chan *Item, 1)
+		go func() {
+			it, gerr := svc.Get(ctx, 2, false)
+			asserter.NoError(gerr)
+			waited <- it
+		}()
+		requirer.Eventually(func() bool {
+			return misses.gets.Load() == gets+1
+		}, time.Second, time.Millisecond)
+		// allowing the caller to join the ongoing lookup
+		time.Sleep(time.Millisecond * 20)
+
+		cancel()
+		asserter.ErrorIs(<-cancelled, context.Canceled)
+		held.release <- struct{}{}
+		asserter.Equal("Jar", (<-waited).Name)
+
+		cached, gerr := lru.Get(ctx, DefaultTenant, 2)
+		requirer.NoError(gerr)
+		asserter.Equal("Jar", cached.Name)
+	})
+}
+
+func TestCreateMany(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+	ctx := t.Context()
+
+	items := []Item{
+		{ID: 1, Name: "Fork"},
+		{ID: 0, Name: "Knife"},
+		{ID: 2, Name: "Spoon"},
+		{ID: 1, Name: "Fork"},
+		{ID: 3, Name: "Ladle"},
+	}
+	statuses := func(results []CreateResult) []string {
+		list := make([]string, 0, len(results))
+		for _, result := range results {
+			list = append(list, fmt.Sprintf("%d:%s", result.ID, result.Status))
+		}
+		return list
+	}
+	expected := []string{"1:created", "0:invalid", "2:created", "1:duplicate", "3:created"}
+
+	t.Run("every item has its own result, and event", func(_ *testing.T) {
+		pipe := make(chan []byte, 128)
+		smo := newStoreMocker()
+		// the queue is smaller than the batch, so that the slots are reserved in chunks
+		svc, err := NewService(smo, nil, newPubMocker(pipe), NoopNamer(), &Config{PublishQueueDepth: 2})
+		requirer.NoError(err)
+
+		results, err := svc.CreateMany(ctx, items)
+		requirer.NoError(err)
+		asserter.Equal(expected, statuses(results))
+		asserter.Equal(int64(1), results[2].Item.Version)
+		asserter.NotEmpty(results[1].Error)
+		asserter.Len(smo.data, 3)
+
+		requirer.NoError(svc.Drain(ctx))
+		close(pipe)
+		published := []string{}
+		for pbytes := range pipe {
+			event := Event{}
+			requirer.NoError(json.Unmarshal(pbytes, &event))
+			published = append(published, fmt.Sprintf("%s:%d", event.Type, event.Payload.ID))
+		}
+		asserter.ElementsMatch([]string{"item.created:1", "item.created:2", "item.created:3"}, published)
+	})
+
+	t.Run("with outbox, every item created has an outbox record", func(_ *testing.T) {
+		smo := newStoreMocker()
+		svc, err := NewService(smo, nil, newPubMocker(make(chan []byte, 1)), NoopNamer(), &Config{UseOutbox: true})
+		requirer.NoError(err)
+
+		results, err := svc.CreateMany(ctx, items)
+		requirer.NoError(err)
+		asserter.Equal(expected, statuses(results))
+		asserter.Len(smo.outbox, 3)
+	})
+
+	t.Run("batch size is limited", func(_ *testing.T) {
+		svc, err := NewService(newStoreMocker(), nil, newPubMocker(make(chan []byte, 1)), NoopNamer(), nil)
+		requirer.NoError(err)
+		_, err = svc.CreateMany(ctx, make([]Item, MaxCreateBatch+1))
+		requirer.ErrorIs(err, ErrBatchTooLarge)
+	})
+}
+
+// TestOutboxRelay ensures items are published only via the relay, when the outbox is enabled
+func TestOutboxRelay(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+	pipe := make(chan []byte, 128)
+	pmo := newPubMocker(pipe)
+	smo := newStoreMocker()
+	svc, err := NewService(smo, nil, pmo, NoopNamer(), &Config{UseOutbox: true})
+	requirer.NoError(err)
+	ctx := t.Context()
+
+	for _, id := range []int{11, 12, 13} {
+		_, err = svc.Create(ctx, Item{ID: id, Name: "Jar"})
+		requirer.NoError(err)
+	}
+
+	t.Run("records are saved to the outbox instead of publishing", func(_ *testing.T) {
+		asserter.Len(smo.outbox, 3)
+		asserter.Empty(pipe)
+	})
+
+	t.Run("relay publishes all the pending records in order", func(_ *testing.T) {
+		relay, rerr := NewOutboxRelay(smo, pmo, &OutboxRelayConfig{BatchSize: 2})
+		requirer.NoError(rerr)
+		requirer.NoError(relay.relayPending(ctx))
+
+		for _, id := range []int{11, 12, 13} {
+			event := Event{}
+			requirer.NoError(json.Unmarshal(<-pipe, &event))
+			asserter.Equal(id, event.Payload.ID)
+		}
+
+		stats, serr := smo.OutboxStats(ctx)
+		requirer.NoError(serr)
+		asserter.Equal(int64(0), stats.Pending)
+		asserter.Equal(int64(0), relay.backlog.Load())
+	})
+
+	t.Run("relay drains pending records on shutdown", func(_ *testing.T) {
+		relay, rerr := NewOutboxRelay(smo, pmo, &OutboxRelayConfig{PollInterval: time.Hour})
+		requirer.NoError(rerr)
+		go func() {
+			_ = relay.Start(ctx)
+		}()
+
+		_, err = svc.Create(ctx, Item{ID: 14, Name: "Jar"})
+		requirer.NoError(err)
+		requirer.NoError(relay.Shutdown(ctx))
+
+		event := Event{}
+		requirer.NoError(json.Unmarshal(<-pipe, &event))
+		asserter.Equal(14, event.Payload.ID)
+	})
+}
+
+type flakyPubMocker struct {
+	failures int
+	attempts int
+}
+
+func (fpm *flakyPubMocker) Publish(_ context.Context, _ *Event) error {
+	fpm.attempts++
+	if fpm.attempts <= fpm.failures {
+		return errors.New("broker unavailable")
+	}
+	return nil
+}
+
+// TestResilientPublisher ensures failed publishes are retried, and the breaker opens on repeated failures
+func TestResilientPublisher(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+	ctx := t.Context()
+	backoff := resilience.Backoff{Initial: time.Millisecond, Max: time.Millisecond * 5, Multiplier: 2}
+
+	t.Run("succeeds if an attempt within the limit succeeds", func(_ *testing.T) {
+		flaky := &flakyPubMocker{failures: 2}
+		rpub, err := NewResilientPublisher(flaky, &ResilientPublisherConfig{
+			MaxAttempts: 3,
+			Backoff:     backoff,
+			Breaker:     resilience.BreakerConfig{FailureThreshold: 10},
+		})
+		requirer.NoError(err)
+		requirer.NoError(rpub.Publish(ctx, &Event{Payload: Item{ID: 1}}))
+		asserter.Equal(3, flaky.attempts)
+		asserter.Equal(resilience.BreakerClosed, rpub.BreakerState())
+	})
+
+	t.Run("breaker opens and fails fast", func(_ *testing.T) {
+		flaky := &flakyPubMocker{failures: 100}
+		rpub, err := NewResilientPublisher(flaky, &ResilientPublisherConfig{
+			MaxAttempts: 5,
+			Backoff:     backoff,
+			Breaker:     resilience.BreakerConfig{FailureThreshold: 3, OpenTimeout: time.Hour},
+		})
+		requirer.NoError(err)
+		requirer.ErrorIs(rpub.Publish(ctx, &Event{Payload: Item{ID: 1}}), resilience.ErrCircuitOpen)
+		asserter.Equal(3, flaky.attempts)
+		asserter.Equal(resilience.BreakerOpen, rpub.BreakerState())
+
+		requirer.ErrorIs(rpub.Publish(ctx, &Event{Payload: Item{ID: 2}}), resilience.ErrCircuitOpen)
+		asserter.Equal(3, flaky.attempts)
+	})
+}
+
+// blockingPubMocker blocks publishing until unblocked, to simulate a slow broker
+type blockingPubMocker struct {
+	started   chan int
+	unblock   chan struct{}
+	published chan int
+}
+
+func (bpm *blockingPubMocker) Publish(_ context.Context, event *Event) error {
+	bpm.started <- event.Payload.ID
+	<-bpm.unblock
+	bpm.published <- event.Payload.ID
+	return nil
+}
+
+func newBlockingPubMocker() *blockingPubMocker {
+	return &blockingPubMocker{
+		started:   make(chan int, 128),
+		unblock:   make(chan struct{}),
+		published: make(chan int, 128),
+	}
+}
+
+// TestPublishDispatcher ensures the queue full policies are applied, and pending events are drained
+func TestPublishDispatcher(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+	ctx := t.Context()
+
+	for _, policy := range []string{QueueFullFail, QueueFullDrop} {
+		t.Run("queue full policy "+policy, func(_ *testing.T) {
+			bpm := newBlockingPubMocker()
+			smo := newStoreMocker()
+			svc, err := NewService(smo, nil, bpm, NoopNamer(), &Config{
+				PublishWorkers:         1,
+				PublishQueueDepth:      1,
+				PublishQueueFullPolicy: policy,
+			})
+			requirer.NoError(err)
+
+			// the first is being published by the only worker, and the second waits in the queue
+			_, err = svc.Create(ctx, Item{ID: 1, Name: "Pot"})
+			requirer.NoError(err)
+			asserter.Equal(1, <-bpm.started)
+			_, err = svc.Create(ctx, Item{ID: 2, Name: "Pot"})
+			requirer.NoError(err)
+
+			_, err = svc.Create(ctx, Item{ID: 3, Name: "Pot"})
+			if policy == QueueFullFail {
+				requirer.ErrorIs(err, ErrPublishQueueFull)
+				asserter.NotContains(smo.data, 3)
+			} else {
+				requirer.NoError(err)
+				asserter.Contains(smo.data, 3)
+			}
+
+			close(bpm.unblock)
+			requirer.NoError(svc.Drain(ctx))
+			close(bpm.published)
+			published := []int{}
+			for id := range bpm.published {
+				published = append(published, id)
+			}
+			asserter.Equal([]int{1, 2}, published)
+
+			_, err = svc.Create(ctx, Item{ID: 4, Name: "Pot"})
+			requirer.ErrorIs(err, ErrDispatcherClosed)
+		})
+	}
+
+	t.Run("unknown policy is rejected", func(_ *testing.T) {
+		_, err := NewService(newStoreMocker(), nil, newBlockingPubMocker(), nil, &Config{PublishQueueFullPolicy: "maybe"})
+		requirer.ErrorIs(err, ErrUnknownQueueFullPolicy)
+	})
+}
+
+// TestKafkaRecord ensures the events are encoded as per the configured format
+func TestKafkaRecord(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+	svc, err := NewService(newStoreMocker(), nil, newPubMocker(make(chan []byte, 1)), nil, &Config{EventSource: "template-test"})
+	requirer.NoError(err)
+	event := svc.newEvent(t.Context(), EventItemUpdated, &Item{Tenant: "acme", ID: 42, Name: "Kettle", Version: 2})
+
+	t.Run("json", func(_ *testing.T) {
+		record, rerr := kafkaRecord(event, EventFormatJSON)
+		requirer.NoError(rerr)
+		asserter.Equal("42", string(record.Key))
+		asserter.Contains(record.Headers, kgo.RecordHeader{Key: KafkaHeaderTenant, Value: []byte("acme")})
+		decoded := Event{}
+		requirer.NoError(json.Unmarshal(record.Value, &decoded))
+		asserter.Equal(event.ID, decoded.ID)
+		asserter.Equal(event.Payload, decoded.Payload)
+	})
+
+	for _, format := range []string{EventFormatCloudEventsBinary, EventFormatCloudEventsStructured} {
+		t.Run(format, func(_ *testing.T) {
+			record, rerr := kafkaRecord(event, format)
+			requirer.NoError(rerr)
+			asserter.Equal("42", string(record.Key))
+			asserter.Contains(record.Headers, kgo.RecordHeader{Key: KafkaHeaderTenant, Value: []byte("acme")})
+
+			cevent, _, rerr := cloudevents.FromRecord(record)
+			requirer.NoError(rerr)
+			asserter.Equal(event.ID, cevent.ID)
+			asserter.Equal(EventItemUpdated, cevent.Type)
+			asserter.Equal("template-test", cevent.Source)
+			asserter.Equal("1", cevent.Extensions["schemaversion"])
+			payload := Item{}
+			requirer.NoError(json.Unmarshal(cevent.Data, &payload))
+			asserter.Equal(event.Payload, payload)
+		})
+	}
+
+	_, err = NewKafkaItemPublisher(nil, &Topics{}, "xml")
+	requirer.ErrorIs(err, ErrUnknownEventFormat)
+}
+
+func withoutTimestamps(it Item) Item {
+	it.CreatedAt = time.Time{}
+	it.UpdatedAt = time.Time{}
+	return it
+}
+
+func withoutVersion(it Item) Item {
+	it = withoutTimestamps(it)
+	it.Version = 0
+	return it
+}
+
+// TestListItems ensures pagination is stable across pages, and the filters & sorting are applied
+func TestListItems(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+	smo := newStoreMocker()
+	svc, err := NewService(smo, nil, newPubMocker(make(chan []byte, 128)), NoopNamer(), nil)
+	requirer.NoError(err)
+	ctx := t.Context()
+
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	names := []string{"Mug", "Bottle", "Cup", "Bowl", "Mug", "Jug", "Bottle", "Plate"}
+	for i, name := range names {
+		smo.data[i+1] = Item{Tenant: DefaultTenant, ID: i + 1, Name: name, Version: 1, CreatedAt: createdAt.Add(time.Duration(i%3) * time.Hour)}
+	}
+
+	listAll := func(query ListQuery) []int {
+		ids := make([]int, 0, len(names))
+		for {
+			result, lerr := svc.List(ctx, query)
+			requirer.NoError(lerr)
+			requirer.LessOrEqual(len(result.Items), query.Limit)
+			for _, it := range result.Items {
+				ids = append(ids, it.ID)
+			}
+			if result.NextCursor == "" {
+				return ids
+			}
+			query.Cursor = result.NextCursor
+		}
+	}
+
+	t.Run("default sort by ID, across pages", func(_ *testing.T) {
+		asserter.Equal([]int{1, 2, 3, 4, 5, 6, 7, 8}, listAll(ListQuery{Limit: 3}))
+	})
+
+	t.Run("sort by name with ID as the tie-breaker", func(_ *testing.T) {
+		asserter.Equal([]int{2, 7, 4, 3, 6, 1, 5, 8}, listAll(ListQuery{Limit: 2, SortBy: SortByName}))
+		asserter.Equal([]int{8, 5, 1, 6, 3, 4, 7, 2}, listAll(ListQuery{Limit: 3, SortBy: SortByName, Descending: true}))
+	})
+
+	t.Run("sort by created time", func(_ *testing.T) {
+		asserter.Equal([]int{1, 4, 7, 2, 5, 8, 3, 6}, listAll(ListQuery{Limit: 3, SortBy: SortByCreatedAt}))
+	})
+
+	t.Run("filters", func(_ *testing.T) {
+		asserter.Equal([]int{2, 4, 7}, listAll(ListQuery{Limit: 1, NamePrefix: "Bo"}))
+		asserter.Equal([]int{1, 5, 6}, listAll(ListQuery{Limit: 2, NameContains: "ug"}))
+		asserter.Equal([]int{3, 4, 5}, listAll(ListQuery{Limit: 2, IDFrom: 3, IDTo: 5}))
+	})
+
+	t.Run("limit defaults and caps", func(_ *testing.T) {
+		result, lerr := svc.List(ctx, ListQuery{})
+		requirer.NoError(lerr)
+		asserter.Len(result.Items, len(names))
+		asserter.Empty(result.NextCursor)
+
+		_, lerr = svc.List(ctx, ListQuery{Limit: -1})
+		requirer.ErrorIs(lerr, ErrInvalidLimit)
+	})
+
+	t.Run("invalid cursor and sort", func(_ *testing.T) {
+		_, lerr := svc.List(ctx, ListQuery{Cursor: "not-a-cursor"})
+		requirer.ErrorIs(lerr, ErrInvalidCursor)
+
+		result, lerr := svc.List(ctx, ListQuery{Limit: 1})
+		requirer.NoError(lerr)
+		_, lerr = svc.List(ctx, ListQuery{Limit: 1, Cursor: result.NextCursor, SortBy: SortByName})
+		requirer.ErrorIs(lerr, ErrInvalidCursor)
+
+		_, lerr = svc.List(ctx, ListQuery{SortBy: "price"})
+		requirer.ErrorIs(lerr, ErrInvalidSort)
+	})
+}
+
+func TestNamers(t *testing.T) {
+	asserter := assert.New(t)
+	requirer := require.New(t)
+	item := &Item{ID: 42, Name: "Bottle"}
+
+	asserter.Equal("Bottle", NoopNamer().Name(item))
+	asserter.Equal(HashSuffixNamer().Name(item), HashSuffixNamer().Name(&Item{ID: 42, Name: "Bottle"}))
+	asserter.NotEqual(HashSuffixNamer().Name(item), HashSuffixNamer().Name(&Item{ID: 43, Name: "Bottle"}))
+	asserter.Regexp(`^Bottle-\d+$`, RandomSuffixNamer().Name(item))
+	asserter.Regexp(`^Bottle-[0-9A-HJKMNP-TV-Z]{26}$`, ULIDSuffixNamer().Name(item))
+
+	for _, strategy := range []string{"", NamingRandom, NamingULID, NamingHash, NamingNone} {
+		_, err := NewNamer(strategy)
+		requirer.NoError(err)
+	}
+	_, err := NewNamer("uuid")
+	requirer.ErrorIs(err, ErrUnknownNamingStrategy)
+}
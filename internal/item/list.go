@@ -0,0 +1,188 @@
+package item
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/naughtygopher/errors"
+)
+
+const (
+	// DefaultListLimit is used when the list query does not specify a limit
+	DefaultListLimit = 20
+	// MaxListLimit is the maximum number of items returned in a single page, any limit above
+	// this is capped.
+	MaxListLimit = 100
+)
+
+var (
+	ErrInvalidCursor = errors.InputBody("invalid page cursor")
+	ErrInvalidSort   = errors.InputBody("invalid sort field, should be one of 'id', 'name' or 'createdAt'")
+	ErrInvalidLimit  = errors.InputBody("limit should be >= 0")
+)
+
+type SortField string
+
+const (
+	SortByID        SortField = "id"
+	SortByName      SortField = "name"
+	SortByCreatedAt SortField = "createdAt"
+)
+
+// ListQuery is used to filter, sort and paginate items. The pagination is keyset based,
+// so the results are stable even if items are inserted/removed between pages.
+type ListQuery struct {
+	// Limit is capped to MaxListLimit, and defaults to DefaultListLimit if 0
+	Limit int
+	// Cursor is the opaque token returned as ListResult.NextCursor, to fetch the next page.
+	// A cursor can only be used with the same sort field & order it was generated for.
+	Cursor     string
+	SortBy     SortField
+	Descending bool
+
+	NamePrefix   string
+	NameContains string
+	// IDFrom & IDTo are inclusive, 0 means unbounded
+	IDFrom int
+	IDTo   int
+	// IncludeDeleted if true, the soft deleted items are also listed
+	IncludeDeleted bool
+	// Tags & Labels filter the items which have all of them, labels should have the same value
+	Tags   []string
+	Labels map[string]string
+
+	after *pageCursor
+}
+
+type ListResult struct {
+	Items []Item `json:"items"`
+	// NextCursor is empty if there are no more items
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// pageCursor is the position of the last item of a page, based on the sort field.
+// ID is always used as the tie-breaker, since it is unique.
+type pageCursor struct {
+	SortBy     SortField `json:"s"`
+	Descending bool      `json:"d,omitempty"`
+	ID         int       `json:"i"`
+	Name       string    `json:"n,omitempty"`
+	CreatedAt  time.Time `json:"c,omitzero"`
+}
+
+func (pc *pageCursor) encode() string {
+	jbytes, _ := json.Marshal(pc)
+	return base64.RawURLEncoding.EncodeToString(jbytes)
+}
+
+func decodeCursor(token string) (*pageCursor, error) {
+	jbytes, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidCursor, err.Error())
+	}
+
+	pc := new(pageCursor)
+	err = json.Unmarshal(jbytes, pc)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidCursor, err.Error())
+	}
+
+	return pc, nil
+}
+
+func cursorOf(item *Item, query *ListQuery) *pageCursor {
+	return &pageCursor{
+		SortBy:     query.SortBy,
+		Descending: query.Descending,
+		ID:         item.ID,
+		Name:       item.Name,
+		CreatedAt:  item.CreatedAt,
+	}
+}
+
+// normalize validates the query and sets the defaults, it also decodes the cursor if available.
+func (lq *ListQuery) normalize() error {
+	switch {
+	case lq.Limit < 0:
+		return errors.Wrapf(ErrInvalidLimit, ": %d", lq.Limit)
+	case lq.Limit == 0:
+		lq.Limit = DefaultListLimit
+	case lq.Limit > MaxListLimit:
+		lq.Limit = MaxListLimit
+	}
+
+	switch lq.SortBy {
+	case "":
+		lq.SortBy = SortByID
+	case SortByID, SortByName, SortByCreatedAt:
+	default:
+		return errors.Wrapf(ErrInvalidSort, ": %s", lq.SortBy)
+	}
+
+	if lq.Cursor == "" {
+		return nil
+	}
+
+	after, err := decodeCursor(lq.Cursor)
+	if err != nil {
+		return err
+	}
+	if after.SortBy != lq.SortBy || after.Descending != lq.Descending {
+		return errors.Wrap(ErrInvalidCursor, "cursor was generated for a different sort order")
+	}
+	lq.after = after
+
+	return nil
+}
+
+// matches reports if the item satisfies all the filters of the query, including the cursor.
+// It is meant for stores which do not have a native query language.
+func (lq *ListQuery) matches(item *Item) bool {
+	if item.IsDeleted() && !lq.IncludeDeleted {
+		return false
+	}
+	if lq.NamePrefix != "" && !strings.HasPrefix(item.Name, lq.NamePrefix) {
+		return false
+	}
+	if lq.NameContains != "" && !strings.Contains(item.Name, lq.NameContains) {
+		return false
+	}
+	if lq.IDFrom > 0 && item.ID < lq.IDFrom {
+		return false
+	}
+	if lq.IDTo > 0 && item.ID > lq.IDTo {
+		return false
+	}
+	if !hasTags(item.Tags, lq.Tags) || !hasLabels(item.Labels, lq.Labels) {
+		return false
+	}
+	if lq.after != nil {
+		return lq.compare(item, &Item{ID: lq.after.ID, Name: lq.after.Name, CreatedAt: lq.after.CreatedAt}) > 0
+	}
+	return true
+}
+
+// compare compares two items based on the sort field & order of the query, with ID as the tie-breaker.
+// It is meant to be used with slices.SortFunc.
+func (lq *ListQuery) compare(a, b *Item) int {
+	result := 0
+	switch lq.SortBy {
+	case SortByName:
+		result = strings.Compare(a.Name, b.Name)
+	case SortByCreatedAt:
+		result = a.CreatedAt.Compare(b.CreatedAt)
+	case SortByID:
+	}
+
+	if result == 0 {
+		result = cmp.Compare(a.ID, b.ID)
+	}
+
+	if lq.Descending {
+		return -result
+	}
+	return result
+}
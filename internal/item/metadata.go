@@ -0,0 +1,259 @@
+package item
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/naughtygopher/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+const (
+	DefaultMaxTags        = 32
+	DefaultMaxLabels      = 32
+	DefaultMaxAttributes  = 32
+	DefaultMaxValueLength = 1024
+	// DefaultKeyPattern allows lower case alphanumeric keys with '_' or '-', of at most 63 characters
+	DefaultKeyPattern = `^[a-z0-9][a-z0-9_-]{0,62}$`
+)
+
+var (
+	// ErrInvalidTags, ErrInvalidLabels & ErrInvalidAttributes are the causes of the respective field errors
+	ErrInvalidTags       = errors.Validation("invalid tags")
+	ErrInvalidLabels     = errors.Validation("invalid labels")
+	ErrInvalidAttributes = errors.Validation("invalid attributes")
+	ErrInvalidAttribute  = errors.Validation("attribute should be a string, number or boolean")
+	ErrInvalidKeyPattern = errors.Validation("invalid key pattern")
+)
+
+type AttributeType string
+
+const (
+	AttributeString AttributeType = "string"
+	AttributeNumber AttributeType = "number"
+	AttributeBool   AttributeType = "bool"
+)
+
+// Attribute is a typed value of an item. It is represented as a plain string, number or boolean,
+// both in JSON and in MongoDB, so that attributes can be queried natively.
+type Attribute struct {
+	Type        AttributeType
+	StringValue string
+	NumberValue float64
+	BoolValue   bool
+}
+
+func StringAttribute(value string) Attribute {
+	return Attribute{Type: AttributeString, StringValue: value}
+}
+
+func NumberAttribute(value float64) Attribute {
+	return Attribute{Type: AttributeNumber, NumberValue: value}
+}
+
+func BoolAttribute(value bool) Attribute {
+	return Attribute{Type: AttributeBool, BoolValue: value}
+}
+
+// Value returns the value based on the type, nil if the type is unknown
+func (attr Attribute) Value() any {
+	switch attr.Type {
+	case AttributeString:
+		return attr.StringValue
+	case AttributeNumber:
+		return attr.NumberValue
+	case AttributeBool:
+		return attr.BoolValue
+	}
+	return nil
+}
+
+func (attr Attribute) MarshalJSON() ([]byte, error) {
+	value := attr.Value()
+	if value == nil {
+		return nil, errors.Wrapf(ErrInvalidAttribute, ": unknown type '%s'", attr.Type)
+	}
+	return json.Marshal(value) //nolint:wrapcheck // returned as is to the JSON encoder
+}
+
+func (attr *Attribute) UnmarshalJSON(data []byte) error {
+	var value any
+	err := json.Unmarshal(data, &value)
+	if err != nil {
+		return err //nolint:wrapcheck // returned as is to the JSON decoder
+	}
+
+	switch typed := value.(type) {
+	case string:
+		*attr = StringAttribute(typed)
+	case float64:
+		*attr = NumberAttribute(typed)
+	case bool:
+		*attr = BoolAttribute(typed)
+	default:
+		return errors.Wrapf(ErrInvalidAttribute, ": %s", string(data))
+	}
+
+	return nil
+}
+
+func (attr Attribute) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	value := attr.Value()
+	if value == nil {
+		return 0, nil, errors.Wrapf(ErrInvalidAttribute, ": unknown type '%s'", attr.Type)
+	}
+	return bson.MarshalValue(value) //nolint:wrapcheck // returned as is to the BSON encoder
+}
+
+func (attr *Attribute) UnmarshalBSONValue(btype bsontype.Type, data []byte) error {
+	raw := bson.RawValue{Type: btype, Value: data}
+	switch btype {
+	case bson.TypeString:
+		*attr = StringAttribute(raw.StringValue())
+	case bson.TypeDouble:
+		*attr = NumberAttribute(raw.Double())
+	case bson.TypeInt32:
+		*attr = NumberAttribute(float64(raw.Int32()))
+	case bson.TypeInt64:
+		*attr = NumberAttribute(float64(raw.Int64()))
+	case bson.TypeBoolean:
+		*attr = BoolAttribute(raw.Boolean())
+	default:
+		return errors.Wrapf(ErrInvalidAttribute, ": unsupported BSON type %s", btype)
+	}
+
+	return nil
+}
+
+// Limits are applied on the tags, labels and attributes of items
+type Limits struct {
+	MaxTags       int
+	MaxLabels     int
+	MaxAttributes int
+	// MaxValueLength is the maximum length (in bytes) of label values & string attributes
+	MaxValueLength int
+	// KeyPattern is the regular expression which tags, label keys and attribute keys should match.
+	// Irrespective of the pattern, keys can never have '.' or '$', since the stores use them as field names.
+	KeyPattern string
+
+	keyRegexp *regexp.Regexp
+}
+
+// normalize validates the limits and sets the defaults
+func (lim *Limits) normalize() error {
+	if lim.MaxTags <= 0 {
+		lim.MaxTags = DefaultMaxTags
+	}
+	if lim.MaxLabels <= 0 {
+		lim.MaxLabels = DefaultMaxLabels
+	}
+	if lim.MaxAttributes <= 0 {
+		lim.MaxAttributes = DefaultMaxAttributes
+	}
+	if lim.MaxValueLength <= 0 {
+		lim.MaxValueLength = DefaultMaxValueLength
+	}
+	if lim.KeyPattern == "" {
+		lim.KeyPattern = DefaultKeyPattern
+	}
+
+	keyRegexp, err := regexp.Compile(lim.KeyPattern)
+	if err != nil {
+		return errors.Wrapf(ErrInvalidKeyPattern, ": %s", err.Error())
+	}
+	lim.keyRegexp = keyRegexp
+
+	return nil
+}
+
+// ValidKey returns an error if the key is not a valid tag, label key or attribute key as per the limits
+func (lim Limits) ValidKey(key string) error {
+	err := lim.normalize()
+	if err != nil {
+		return err
+	}
+	if !lim.validKey(key) {
+		return errors.Validationf("key '%s' should match %s, and can not have '.' or '$'", key, lim.KeyPattern)
+	}
+
+	return nil
+}
+
+// validKey expects the limits to be normalized
+func (lim *Limits) validKey(key string) bool {
+	return lim.keyRegexp.MatchString(key) && !strings.ContainsAny(key, ".$")
+}
+
+// normalizeTags removes the duplicates and sorts the tags, since they're a set. A nil slice is
+// kept nil, and an empty one is kept empty.
+func normalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+	normalized := slices.Clone(tags)
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
+
+// hasTags reports if all the tags provided are in the (normalized) tags
+func hasTags(tags []string, required []string) bool {
+	for _, tag := range required {
+		if _, found := slices.BinarySearch(tags, tag); !found {
+			return false
+		}
+	}
+	return true
+}
+
+// hasLabels reports if all the labels provided are in the labels, with the same value
+func hasLabels(labels map[string]string, required map[string]string) bool {
+	for key, value := range required {
+		if current, ok := labels[key]; !ok || current != value {
+			return false
+		}
+	}
+	return true
+}
+
+// clone returns a deep copy of the item, so that the copy can be shared safely
+func (it *Item) clone() Item {
+	copied := *it
+	copied.Tags = slices.Clone(it.Tags)
+	copied.Labels = maps.Clone(it.Labels)
+	copied.Attributes = maps.Clone(it.Attributes)
+	if it.DeletedAt != nil {
+		deletedAt := *it.DeletedAt
+		copied.DeletedAt = &deletedAt
+	}
+	return copied
+}
+
+// formatMetadata formats tags, labels or attributes as JSON, for the history of items. Empty ones
+// are formatted as an empty string.
+func formatMetadata(value any) string {
+	switch typed := value.(type) {
+	case []string:
+		if len(typed) == 0 {
+			return ""
+		}
+	case map[string]string:
+		if len(typed) == 0 {
+			return ""
+		}
+	case map[string]Attribute:
+		if len(typed) == 0 {
+			return ""
+		}
+	}
+
+	jbytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(jbytes)
+}
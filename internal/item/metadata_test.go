@@ -0,0 +1,125 @@
+package item
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestItemMetadata(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+	ctx := t.Context()
+
+	mstore, err := NewMemoryPersistentStore()
+	requirer.NoError(err)
+	svc, err := NewService(mstore, nil, newPubMocker(make(chan []byte, 128)), NoopNamer(), &Config{
+		Limits: Limits{MaxTags: 2, MaxLabels: 2, MaxAttributes: 2, MaxValueLength: 8},
+	})
+	requirer.NoError(err)
+
+	t.Run("tags are a set", func(_ *testing.T) {
+		it, cerr := svc.Create(ctx, Item{ID: 1, Name: "Box", Tags: []string{"fragile", "bulk", "fragile"}})
+		requirer.NoError(cerr)
+		asserter.Equal([]string{"bulk", "fragile"}, it.Tags)
+	})
+
+	t.Run("limits are enforced", func(_ *testing.T) {
+		invalid := map[error]Item{
+			ErrInvalidTags:       {ID: 2, Tags: []string{"a", "b", "c"}},
+			ErrInvalidLabels:     {ID: 2, Labels: map[string]string{"color": strings.Repeat("r", 9)}},
+			ErrInvalidAttributes: {ID: 2, Attributes: map[string]Attribute{"Weight": NumberAttribute(1)}},
+		}
+		for expected, it := range invalid {
+			_, cerr := svc.Create(ctx, it)
+			asserter.ErrorIs(cerr, expected)
+		}
+
+		_, cerr := svc.Create(ctx, Item{ID: 2, Labels: map[string]string{"a.b": "c"}})
+		asserter.ErrorIs(cerr, ErrInvalidLabels)
+		_, cerr = svc.Create(ctx, Item{ID: 2, Attributes: map[string]Attribute{"size": {}}})
+		asserter.ErrorIs(cerr, ErrInvalidAttributes)
+
+		_, lerr := svc.List(ctx, ListQuery{Labels: map[string]string{"$where": "1"}})
+		asserter.ErrorIs(lerr, ErrInvalidLabels)
+
+		_, nerr := NewService(mstore, nil, nil, NoopNamer(), &Config{Limits: Limits{KeyPattern: "("}})
+		asserter.ErrorIs(nerr, ErrInvalidKeyPattern)
+
+		asserter.NoError(Limits{}.ValidKey("owner"))
+		asserter.Error(Limits{}.ValidKey("Owner"))
+		asserter.Error(Limits{KeyPattern: "^[A-Za-z.]+$"}.ValidKey("Owner.Sub"))
+		asserter.ErrorIs(Limits{KeyPattern: "("}.ValidKey("owner"), ErrInvalidKeyPattern)
+	})
+
+	t.Run("patch replaces only the ones provided", func(_ *testing.T) {
+		_, cerr := svc.Create(ctx, Item{
+			ID:         3,
+			Name:       "Jar",
+			Tags:       []string{"bulk"},
+			Labels:     map[string]string{"color": "red"},
+			Attributes: map[string]Attribute{"weight": NumberAttribute(2.5)},
+		})
+		requirer.NoError(cerr)
+
+		it, perr := svc.Patch(ctx, 3, Patch{Labels: map[string]string{"color": "blue"}, Tags: []string{}})
+		requirer.NoError(perr)
+		asserter.Empty(it.Tags)
+		asserter.Equal(map[string]string{"color": "blue"}, it.Labels)
+		asserter.Equal(map[string]Attribute{"weight": NumberAttribute(2.5)}, it.Attributes)
+
+		history, herr := svc.History(ctx, 3, HistoryQuery{Limit: 1})
+		requirer.NoError(herr)
+		asserter.Equal([]FieldChange{
+			{Field: "tags", From: `["bulk"]`},
+			{Field: "labels", From: `{"color":"red"}`, To: `{"color":"blue"}`},
+		}, history.Entries[0].Changes)
+	})
+
+	t.Run("list filters by tags and labels", func(_ *testing.T) {
+		_, cerr := svc.Create(ctx, Item{ID: 4, Tags: []string{"bulk", "fragile"}, Labels: map[string]string{"color": "blue"}})
+		requirer.NoError(cerr)
+
+		ids := func(query ListQuery) []int {
+			result, lerr := svc.List(ctx, query)
+			requirer.NoError(lerr)
+			list := []int{}
+			for _, it := range result.Items {
+				list = append(list, it.ID)
+			}
+			return list
+		}
+		asserter.Equal([]int{1, 4}, ids(ListQuery{Tags: []string{"fragile"}}))
+		asserter.Equal([]int{1, 4}, ids(ListQuery{Tags: []string{"fragile", "bulk"}}))
+		asserter.Equal([]int{3, 4}, ids(ListQuery{Labels: map[string]string{"color": "blue"}}))
+		asserter.Equal([]int{4}, ids(ListQuery{Tags: []string{"bulk"}, Labels: map[string]string{"color": "blue"}}))
+		asserter.Empty(ids(ListQuery{Labels: map[string]string{"color": "red"}}))
+	})
+
+	t.Run("attributes are plain JSON and BSON values", func(_ *testing.T) {
+		attributes := map[string]Attribute{
+			"color":   StringAttribute("red"),
+			"weight":  NumberAttribute(1.5),
+			"fragile": BoolAttribute(true),
+		}
+
+		jbytes, merr := json.Marshal(attributes)
+		requirer.NoError(merr)
+		asserter.JSONEq(`{"color":"red","weight":1.5,"fragile":true}`, string(jbytes))
+		decoded := map[string]Attribute{}
+		requirer.NoError(json.Unmarshal(jbytes, &decoded))
+		asserter.Equal(attributes, decoded)
+		requirer.Error(json.Unmarshal([]byte(`{"size":[1]}`), &decoded))
+
+		bbytes, merr := bson.Marshal(Item{ID: 5, Attributes: attributes})
+		requirer.NoError(merr)
+		asserter.Equal("red", bson.Raw(bbytes).Lookup("attributes", "color").StringValue())
+		bdecoded := Item{}
+		requirer.NoError(bson.Unmarshal(bbytes, &bdecoded))
+		asserter.Equal(attributes, bdecoded.Attributes)
+	})
+}
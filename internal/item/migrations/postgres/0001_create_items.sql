@@ -0,0 +1,11 @@
+CREATE TABLE items (
+    id BIGINT PRIMARY KEY,
+    name TEXT NOT NULL,
+    version BIGINT NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+-- for sorting by name & created time, with ID as the tie-breaker
+CREATE INDEX items_name_id_idx ON items (name, id);
+CREATE INDEX items_created_at_id_idx ON items (created_at, id);
@@ -0,0 +1,8 @@
+CREATE TABLE item_outbox (
+    id TEXT PRIMARY KEY,
+    event JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    sent_at TIMESTAMPTZ
+);
+
+CREATE INDEX item_outbox_pending_idx ON item_outbox (id) WHERE sent_at IS NULL;
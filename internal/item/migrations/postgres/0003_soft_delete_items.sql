@@ -0,0 +1,5 @@
+ALTER TABLE items
+    ADD COLUMN deleted_at TIMESTAMPTZ,
+    ADD COLUMN deleted_by TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX items_deleted_at_idx ON items (deleted_at) WHERE deleted_at IS NOT NULL;
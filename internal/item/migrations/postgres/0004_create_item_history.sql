@@ -0,0 +1,13 @@
+-- there is no foreign key to items, since the history is retained after the items are purged
+CREATE TABLE item_history (
+    id TEXT PRIMARY KEY,
+    item_id BIGINT NOT NULL,
+    action TEXT NOT NULL,
+    version BIGINT NOT NULL,
+    actor TEXT NOT NULL DEFAULT '',
+    at TIMESTAMPTZ NOT NULL,
+    changes JSONB NOT NULL
+);
+
+-- listing the history of an item, newest first
+CREATE INDEX item_history_item_id_idx ON item_history (item_id, id DESC);
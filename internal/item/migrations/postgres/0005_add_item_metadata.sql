@@ -0,0 +1,8 @@
+ALTER TABLE items
+    ADD COLUMN tags JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN labels JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN attributes JSONB NOT NULL DEFAULT '{}';
+
+-- filtering by tags & labels uses containment (@>), which is supported by GIN indexes
+CREATE INDEX items_tags_idx ON items USING GIN (tags);
+CREATE INDEX items_labels_idx ON items USING GIN (labels);
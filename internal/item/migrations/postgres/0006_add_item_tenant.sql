@@ -0,0 +1,15 @@
+-- the existing items & history are of the default tenant, IDs are unique per tenant
+ALTER TABLE items
+    ADD COLUMN tenant TEXT NOT NULL DEFAULT 'default',
+    DROP CONSTRAINT items_pkey,
+    ADD PRIMARY KEY (tenant, id);
+
+DROP INDEX items_name_id_idx;
+DROP INDEX items_created_at_id_idx;
+CREATE INDEX items_tenant_name_id_idx ON items (tenant, name, id);
+CREATE INDEX items_tenant_created_at_id_idx ON items (tenant, created_at, id);
+
+ALTER TABLE item_history ADD COLUMN tenant TEXT NOT NULL DEFAULT 'default';
+
+DROP INDEX item_history_item_id_idx;
+CREATE INDEX item_history_tenant_item_id_idx ON item_history (tenant, item_id, id DESC);
@@ -0,0 +1,10 @@
+-- the idempotency records of the item APIs, the response is NULL until the request is complete
+CREATE TABLE item_idempotency (
+    key TEXT PRIMARY KEY,
+    fingerprint TEXT NOT NULL,
+    response JSONB,
+    complete BOOLEAN NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX item_idempotency_expires_at_idx ON item_idempotency (expires_at);
@@ -0,0 +1,81 @@
+package item
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"strconv"
+
+	"github.com/naughtygopher/errors"
+	"github.com/oklog/ulid/v2"
+)
+
+const (
+	NamingRandom = "random"
+	NamingULID   = "ulid"
+	NamingHash   = "hash"
+	NamingNone   = "none"
+)
+
+var ErrUnknownNamingStrategy = errors.New("unknown item naming strategy")
+
+// Namer generates the name of an item being created, based on the item provided by the user.
+type Namer interface {
+	Name(item *Item) string
+}
+
+// NamerFunc is an adapter to use ordinary functions as Namer
+type NamerFunc func(item *Item) string
+
+func (nf NamerFunc) Name(item *Item) string {
+	return nf(item)
+}
+
+// RandomSuffixNamer suffixes the name with a random integer. e.g. Bottle-5577006791947779410
+func RandomSuffixNamer() NamerFunc {
+	return func(item *Item) string {
+		return fmt.Sprintf("%s-%d", item.Name, rand.Int()) //nolint:gosec // G404: Non-crypto usage, just for naming uniqueness
+	}
+}
+
+// ULIDSuffixNamer suffixes the name with a ULID, which are lexicographically sortable by creation time.
+// e.g. Bottle-01ARZ3NDEKTSV4RRFFQ69G5FAV
+func ULIDSuffixNamer() NamerFunc {
+	return func(item *Item) string {
+		return fmt.Sprintf("%s-%s", item.Name, ulid.Make())
+	}
+}
+
+// HashSuffixNamer suffixes the name with a hash of the item ID, so the same ID always gets the same name.
+// e.g. Bottle-12638153115695167455
+func HashSuffixNamer() NamerFunc {
+	return func(item *Item) string {
+		hasher := fnv.New64a()
+		_, _ = hasher.Write([]byte(strconv.Itoa(item.ID)))
+		return fmt.Sprintf("%s-%d", item.Name, hasher.Sum64())
+	}
+}
+
+// NoopNamer retains the name as is.
+func NoopNamer() NamerFunc {
+	return func(item *Item) string {
+		return item.Name
+	}
+}
+
+// NewNamer returns the Namer for the strategy, which should be one of the Naming* constants.
+// Random suffix is used if the strategy is empty.
+func NewNamer(strategy string) (NamerFunc, error) {
+	switch strategy {
+	case "", NamingRandom:
+		return RandomSuffixNamer(), nil
+	case NamingULID:
+		return ULIDSuffixNamer(), nil
+	case NamingHash:
+		return HashSuffixNamer(), nil
+	case NamingNone:
+		return NoopNamer(), nil
+	}
+
+	return nil, errors.Wrapf(ErrUnknownNamingStrategy, ": %s", strategy)
+}
@@ -0,0 +1,208 @@
+package item
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/naughtygopher/errors"
+
+	"github.com/prashantkr001/template-go/internal/pkg/apm"
+	"github.com/prashantkr001/template-go/internal/pkg/logger"
+)
+
+// OutboxRecord is an event persisted along with the respective change of an item, in the same
+// transaction. So that the event is eventually published, even if the app crashes or the
+// message broker is unavailable at the time of the change.
+type OutboxRecord struct {
+	// ID is a ULID (same as the event ID), hence sortable by the time of creation
+	ID        string     `json:"id" bson:"_id"`
+	Event     Event      `json:"event" bson:"event"`
+	CreatedAt time.Time  `json:"createdAt" bson:"createdAt"`
+	SentAt    *time.Time `json:"sentAt,omitempty" bson:"sentAt,omitempty"`
+}
+
+type OutboxStats struct {
+	// Pending is the number of records yet to be published
+	Pending int64
+	// OldestCreatedAt is the creation time of the oldest pending record, zero if there's none
+	OldestCreatedAt time.Time
+}
+
+type outboxStore interface {
+	// PendingOutbox should return the records not sent yet, in the order of creation
+	PendingOutbox(ctx context.Context, limit int) ([]OutboxRecord, error)
+	MarkOutboxSent(ctx context.Context, ids []string, sentAt time.Time) error
+	OutboxStats(ctx context.Context) (*OutboxStats, error)
+}
+
+// newOutboxRecord uses the event ID as the record ID, since both are ULIDs
+func newOutboxRecord(event *Event) OutboxRecord {
+	return OutboxRecord{
+		ID:        event.ID,
+		Event:     *event,
+		CreatedAt: event.OccurredAt,
+	}
+}
+
+type OutboxRelayConfig struct {
+	PollInterval   time.Duration
+	BatchSize      int
+	PublishTimeout time.Duration
+}
+
+// OutboxRelay polls the outbox for pending records, and publishes them in the order of creation.
+// A record is marked as sent only after it's successfully published, so the delivery guarantee
+// is at-least-once.
+type OutboxRelay struct {
+	store     outboxStore
+	publisher publisher
+	cfg       OutboxRelayConfig
+
+	stopOnce *sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+
+	backlog        *atomic.Int64
+	oldestUnixNano *atomic.Int64
+}
+
+func NewOutboxRelay(store outboxStore, pub publisher, cfg *OutboxRelayConfig) (*OutboxRelay, error) {
+	const (
+		defaultPollInterval   = time.Second
+		defaultBatchSize      = 100
+		defaultPublishTimeout = time.Second * 3
+	)
+
+	rly := &OutboxRelay{
+		store:          store,
+		publisher:      pub,
+		cfg:            *cfg,
+		stopOnce:       &sync.Once{},
+		stop:           make(chan struct{}),
+		done:           make(chan struct{}),
+		backlog:        &atomic.Int64{},
+		oldestUnixNano: &atomic.Int64{},
+	}
+	if rly.cfg.PollInterval <= 0 {
+		rly.cfg.PollInterval = defaultPollInterval
+	}
+	if rly.cfg.BatchSize <= 0 {
+		rly.cfg.BatchSize = defaultBatchSize
+	}
+	if rly.cfg.PublishTimeout <= 0 {
+		rly.cfg.PublishTimeout = defaultPublishTimeout
+	}
+
+	meter := apm.Global().AppMeter()
+	meter.Observe("item.outbox.backlog", func() float64 {
+		return float64(rly.backlog.Load())
+	})
+	meter.Observe("item.outbox.oldest_age_seconds", func() float64 {
+		oldest := rly.oldestUnixNano.Load()
+		if oldest == 0 {
+			return 0
+		}
+		return time.Since(time.Unix(0, oldest)).Seconds()
+	})
+
+	return rly, nil
+}
+
+// Start polls & publishes the pending records until Shutdown is called. It is a blocking call.
+func (rly *OutboxRelay) Start(ctx context.Context) error {
+	defer close(rly.done)
+	ticker := time.NewTicker(rly.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-rly.stop:
+			return nil
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "outbox relay stopped")
+		case <-ticker.C:
+			err := rly.relayPending(ctx)
+			if err != nil {
+				logger.ErrWithStacktrace(err)
+			}
+		}
+	}
+}
+
+// Shutdown stops polling, and then drains the outbox by publishing all the pending records
+// or until the context is done.
+func (rly *OutboxRelay) Shutdown(ctx context.Context) error {
+	rly.stopOnce.Do(func() {
+		close(rly.stop)
+	})
+
+	select {
+	case <-rly.done:
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "outbox relay did not stop")
+	}
+
+	return rly.relayPending(ctx)
+}
+
+// relayPending publishes the pending records batch by batch, until there are no more pending records.
+func (rly *OutboxRelay) relayPending(ctx context.Context) error {
+	defer rly.refreshStats(ctx)
+
+	for {
+		sent, err := rly.relayBatch(ctx)
+		if err != nil {
+			return err
+		}
+		if sent < rly.cfg.BatchSize {
+			return nil
+		}
+	}
+}
+
+func (rly *OutboxRelay) relayBatch(ctx context.Context) (int, error) {
+	records, err := rly.store.PendingOutbox(ctx, rly.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := make([]string, 0, len(records))
+	var perr error
+	for i := range records {
+		pctx, cancel := context.WithTimeout(ctx, rly.cfg.PublishTimeout)
+		perr = rly.publisher.Publish(pctx, &records[i].Event)
+		cancel()
+		if perr != nil {
+			// stop at the first failure to preserve the order of events, the rest are retried in the next poll
+			perr = errors.Wrapf(perr, "failed publishing outbox record %s", records[i].ID)
+			break
+		}
+		sent = append(sent, records[i].ID)
+	}
+
+	if len(sent) > 0 {
+		err = rly.store.MarkOutboxSent(ctx, sent, time.Now().UTC())
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(sent), perr
+}
+
+func (rly *OutboxRelay) refreshStats(ctx context.Context) {
+	stats, err := rly.store.OutboxStats(ctx)
+	if err != nil {
+		logger.ErrWithStacktrace(err)
+		return
+	}
+
+	rly.backlog.Store(stats.Pending)
+	if stats.OldestCreatedAt.IsZero() {
+		rly.oldestUnixNano.Store(0)
+		return
+	}
+	rly.oldestUnixNano.Store(stats.OldestCreatedAt.UnixNano())
+}
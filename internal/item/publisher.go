@@ -3,42 +3,226 @@ package item
 import (
 	"context"
 	"encoding/json"
+	"strconv"
 
 	"github.com/naughtygopher/errors"
 	"github.com/twmb/franz-go/pkg/kgo"
 
+	"github.com/prashantkr001/template-go/internal/pkg/apm"
+	"github.com/prashantkr001/template-go/internal/pkg/cloudevents"
 	"github.com/prashantkr001/template-go/internal/pkg/kafka"
+	"github.com/prashantkr001/template-go/internal/pkg/resilience"
+)
+
+// Formats in which the events are published
+const (
+	// EventFormatJSON publishes the Event as is, in JSON
+	EventFormatJSON = "json"
+	// EventFormatCloudEventsBinary publishes CloudEvents in binary mode, attributes as Kafka headers
+	EventFormatCloudEventsBinary = "cloudevents-binary"
+	// EventFormatCloudEventsStructured publishes CloudEvents in structured mode, as a JSON document
+	EventFormatCloudEventsStructured = "cloudevents-structured"
+)
+
+var (
+	ErrNoTopic            = errors.New("no topic configured for the event type")
+	ErrUnknownEventFormat = errors.New("unknown event format")
 )
 
 type publisher interface {
-	Publish(ctx context.Context, item *Item) error
+	Publish(ctx context.Context, event *Event) error
+}
+
+// Topics are the Kafka topics to which the events of respective types are published
+type Topics struct {
+	Created string
+	Updated string
+	Deleted string
 }
 
 type kafkaItemPublisher struct {
-	cli              *kafka.Kafka
-	afterCreateTopic string
+	cli    *kafka.Kafka
+	topics map[string]string
+	format string
 }
 
+// NewKafkaItemPublisher returns a publisher which publishes the events in the format, which should be
+// one of the EventFormat* constants. EventFormatJSON is used if the format is empty.
 func NewKafkaItemPublisher(
 	kcli *kafka.Kafka,
-	pubTopic string,
+	topics *Topics,
+	format string,
 ) (*kafkaItemPublisher, error) { //nolint:revive // it is ok to return unexported type in this case, ensures controlled access
+	switch format {
+	case "":
+		format = EventFormatJSON
+	case EventFormatJSON, EventFormatCloudEventsBinary, EventFormatCloudEventsStructured:
+	default:
+		return nil, errors.Wrapf(ErrUnknownEventFormat, ": %s", format)
+	}
+
 	return &kafkaItemPublisher{
-		cli:              kcli,
-		afterCreateTopic: pubTopic,
+		cli: kcli,
+		topics: map[string]string{
+			EventItemCreated:  topics.Created,
+			EventItemUpdated:  topics.Updated,
+			EventItemDeleted:  topics.Deleted,
+			EventItemRestored: topics.Updated,
+		},
+		format: format,
 	}, nil
 }
 
-func (kip *kafkaItemPublisher) Publish(ctx context.Context, item *Item) error {
-	jbytes, err := json.Marshal(item)
+func (kip *kafkaItemPublisher) Publish(ctx context.Context, event *Event) error {
+	topic := kip.topics[event.Type]
+	if topic == "" {
+		return errors.Wrapf(ErrNoTopic, ": %s", event.Type)
+	}
+
+	record, err := kafkaRecord(event, kip.format)
 	if err != nil {
-		return errors.Wrap(err, "json marshal failed")
+		return err
 	}
+	record.Topic = topic
 
-	err = kip.cli.ProduceSync(ctx, &kgo.Record{Value: jbytes, Topic: kip.afterCreateTopic})
+	// the trace context of the change is used as parent, since publishing is asynchronous
+	err = kip.cli.ProduceSync(event.TraceContext(ctx), record)
 	if err != nil {
 		return errors.Wrap(err, "kafka produce sync failed")
 	}
 
 	return nil
 }
+
+// kafkaRecord encodes the event as per the format, the tenant of the item is set as the
+// KafkaHeaderTenant header irrespective of the format, so that consumers can route without decoding
+func kafkaRecord(event *Event, format string) (*kgo.Record, error) {
+	tenant := event.Payload.Tenant
+	if tenant == "" {
+		// events of the outbox, recorded before multi-tenancy
+		tenant = DefaultTenant
+	}
+	record := &kgo.Record{
+		Key:     []byte(event.Key()),
+		Headers: []kgo.RecordHeader{{Key: KafkaHeaderTenant, Value: []byte(tenant)}},
+	}
+	if format == EventFormatJSON {
+		jbytes, err := json.Marshal(event)
+		if err != nil {
+			return nil, errors.Wrap(err, "json marshal failed")
+		}
+		record.Value = jbytes
+		return record, nil
+	}
+
+	cevent, err := cloudEvent(event)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := cloudevents.ModeBinary
+	if format == EventFormatCloudEventsStructured {
+		mode = cloudevents.ModeStructured
+	}
+	err = cloudevents.ToRecord(cevent, mode, record)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encode cloudevent")
+	}
+
+	return record, nil
+}
+
+// cloudEvent maps the event to a CloudEvent, with the item as data. The trace context & schema
+// version are set as extensions.
+func cloudEvent(event *Event) (*cloudevents.Event, error) {
+	data, err := json.Marshal(event.Payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "json marshal failed")
+	}
+
+	extensions := map[string]string{"schemaversion": strconv.Itoa(event.SchemaVersion)}
+	if event.TraceParent != "" {
+		extensions["traceparent"] = event.TraceParent
+	}
+	if event.TraceState != "" {
+		extensions["tracestate"] = event.TraceState
+	}
+
+	return &cloudevents.Event{
+		ID:              event.ID,
+		Source:          event.Source,
+		Type:            event.Type,
+		Time:            event.OccurredAt,
+		Subject:         event.Key(),
+		DataContentType: cloudevents.ContentTypeJSON,
+		Extensions:      extensions,
+		Data:            data,
+	}, nil
+}
+
+type ResilientPublisherConfig struct {
+	// MaxAttempts is the total number of attempts including the first one, defaults to 1 (no retries)
+	MaxAttempts int
+	Backoff     resilience.Backoff
+	Breaker     resilience.BreakerConfig
+}
+
+// resilientPublisher decorates a publisher with retries & a circuit breaker. Once the breaker
+// is open, publishing fails fast with resilience.ErrCircuitOpen without attempting.
+type resilientPublisher struct {
+	next    publisher
+	cfg     ResilientPublisherConfig
+	breaker *resilience.Breaker
+}
+
+func NewResilientPublisher(
+	pub publisher,
+	cfg *ResilientPublisherConfig,
+) (*resilientPublisher, error) { //nolint:revive // it is ok to return unexported type in this case, ensures controlled access
+	rpub := &resilientPublisher{
+		next:    pub,
+		cfg:     *cfg,
+		breaker: resilience.NewBreaker(&cfg.Breaker),
+	}
+	if rpub.cfg.MaxAttempts <= 0 {
+		rpub.cfg.MaxAttempts = 1
+	}
+
+	// 0: closed, 1: half-open, 2: open
+	apm.Global().AppMeter().Observe("item.publisher.breaker_state", func() float64 {
+		return float64(rpub.breaker.State())
+	})
+
+	return rpub, nil
+}
+
+func (rpub *resilientPublisher) Publish(ctx context.Context, event *Event) error {
+	for attempt := 1; ; attempt++ {
+		err := rpub.breaker.Allow()
+		if err != nil {
+			return errors.Wrapf(err, "publishing event %s", event.ID)
+		}
+
+		err = rpub.next.Publish(ctx, event)
+		if err == nil {
+			rpub.breaker.Success()
+			return nil
+		}
+		rpub.breaker.Failure()
+
+		if attempt >= rpub.cfg.MaxAttempts {
+			return errors.Wrapf(err, "publishing event %s failed after %d attempt(s)", event.ID, attempt)
+		}
+
+		apm.Global().AppMeter().CounterAdd(ctx, "item.publisher.retries", 1)
+		werr := rpub.cfg.Backoff.Wait(ctx, attempt)
+		if werr != nil {
+			return errors.Wrapf(err, "publishing event %s failed after %d attempt(s)", event.ID, attempt)
+		}
+	}
+}
+
+// BreakerState is the current state of the circuit breaker
+func (rpub *resilientPublisher) BreakerState() resilience.BreakerState {
+	return rpub.breaker.State()
+}
@@ -0,0 +1,150 @@
+package item
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/naughtygopher/errors"
+
+	"github.com/prashantkr001/template-go/internal/pkg/apm"
+	"github.com/prashantkr001/template-go/internal/pkg/logger"
+)
+
+type purgeStore interface {
+	// PurgeItems should hard delete at most limit items, which were deleted before the time provided,
+	// and return the number of items purged.
+	PurgeItems(ctx context.Context, deletedBefore time.Time, limit int) (int64, error)
+}
+
+type PurgerConfig struct {
+	// Retention is the duration for which deleted items are kept, before being purged
+	Retention time.Duration
+	// Interval is the time between two consecutive purges
+	Interval time.Duration
+	// BatchSize is the maximum number of items purged at once
+	BatchSize int
+	// OnProgress if set, is called after every purge
+	OnProgress func(progress PurgeProgress)
+}
+
+// PurgeProgress is the outcome of a purge
+type PurgeProgress struct {
+	At            time.Time
+	DeletedBefore time.Time
+	// Purged is the number of items purged in this run
+	Purged int64
+	// TotalPurged is the number of items purged since the purger started
+	TotalPurged int64
+	// Err is set if the purge failed, items might have been purged partially
+	Err error
+}
+
+// Purger periodically hard deletes items which were deleted before the retention period
+type Purger struct {
+	store purgeStore
+	cfg   PurgerConfig
+
+	stopOnce *sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+
+	totalPurged int64
+}
+
+func NewPurger(store purgeStore, cfg *PurgerConfig) (*Purger, error) {
+	const (
+		defaultInterval  = time.Hour
+		defaultBatchSize = 500
+	)
+
+	if cfg.Retention <= 0 {
+		return nil, errors.Validation("purger retention should be > 0")
+	}
+
+	pgr := &Purger{
+		store:    store,
+		cfg:      *cfg,
+		stopOnce: &sync.Once{},
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	if pgr.cfg.Interval <= 0 {
+		pgr.cfg.Interval = defaultInterval
+	}
+	if pgr.cfg.BatchSize <= 0 {
+		pgr.cfg.BatchSize = defaultBatchSize
+	}
+
+	return pgr, nil
+}
+
+// Start purges immediately, and then on every interval until Shutdown is called. It is a blocking call.
+func (pgr *Purger) Start(ctx context.Context) error {
+	defer close(pgr.done)
+	ticker := time.NewTicker(pgr.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		pgr.purge(ctx)
+
+		select {
+		case <-pgr.stop:
+			return nil
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "purger stopped")
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown stops the purger, an ongoing purge is stopped after the current batch.
+func (pgr *Purger) Shutdown(ctx context.Context) error {
+	pgr.stopOnce.Do(func() {
+		close(pgr.stop)
+	})
+
+	select {
+	case <-pgr.done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "purger did not stop")
+	}
+}
+
+// purge deletes the items batch by batch, until there are no more items to purge
+func (pgr *Purger) purge(ctx context.Context) {
+	progress := PurgeProgress{
+		At:            time.Now().UTC(),
+		DeletedBefore: time.Now().UTC().Add(-pgr.cfg.Retention),
+	}
+
+	for {
+		purged, err := pgr.store.PurgeItems(ctx, progress.DeletedBefore, pgr.cfg.BatchSize)
+		progress.Purged += purged
+		if err != nil {
+			progress.Err = err
+			logger.ErrWithStacktrace(err)
+			break
+		}
+		if purged < int64(pgr.cfg.BatchSize) || pgr.stopped() {
+			break
+		}
+	}
+
+	apm.Global().AppMeter().CounterAdd(ctx, "item.purger.purged", float64(progress.Purged))
+	pgr.totalPurged += progress.Purged
+	progress.TotalPurged = pgr.totalPurged
+	if pgr.cfg.OnProgress != nil {
+		pgr.cfg.OnProgress(progress)
+	}
+}
+
+func (pgr *Purger) stopped() bool {
+	select {
+	case <-pgr.stop:
+		return true
+	default:
+		return false
+	}
+}
@@ -2,6 +2,12 @@ package item
 
 import (
 	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/naughtygopher/errors"
 	"go.mongodb.org/mongo-driver/bson"
@@ -9,36 +15,515 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// persistentStore isolates the items of tenants, every item is accessed by its tenant & ID. Items
+// of a tenant are never returned or modified for another tenant.
 type persistentStore interface {
-	InsertItem(ctx context.Context, item Item) (*Item, error)
-	ListItems(ctx context.Context, limit int) ([]Item, error)
-	Item(ctx context.Context, id int) (*Item, error)
+	// InsertItem & InsertItems should set the tenant of the items, IDs are unique per tenant
+	InsertItem(ctx context.Context, tenant string, item Item) (*Item, error)
+	// InsertItems should return the error of every item in the same order as the input, nil if the
+	// item was inserted, and ErrDuplicateItem if an item with the same ID exists. Failure to insert
+	// an item should not stop the insertion of others.
+	InsertItems(ctx context.Context, tenant string, items []Item) ([]error, error)
+	// ListItems should return at most query.Limit items, matching the filters of the query and
+	// positioned after the query cursor (if any), in the order requested.
+	ListItems(ctx context.Context, tenant string, query ListQuery) ([]Item, error)
+	// Item should return the item even if it is deleted
+	Item(ctx context.Context, tenant string, id int) (*Item, error)
+	// UpdateItem, PatchItem, DeleteItem and RestoreItem should increment the version of the item atomically.
+	// If the expected version is > 0, they should do a compare-and-swap based on the version
+	// and return ErrVersionConflict if the stored version does not match.
+	// Deleted items should be treated as not found, except by RestoreItem which should return
+	// ErrNotDeleted for items which are not deleted.
+	UpdateItem(ctx context.Context, tenant string, item Item) (*Item, error)
+	PatchItem(ctx context.Context, tenant string, id int, patch Patch, updatedAt time.Time) (*Item, error)
+	// DeleteItem should soft delete the item, by setting DeletedAt & DeletedBy
+	DeleteItem(ctx context.Context, tenant string, id int, version int64, deletedAt time.Time, deletedBy string) (*Item, error)
+	RestoreItem(ctx context.Context, tenant string, id int, version int64, restoredAt time.Time) (*Item, error)
+
+	// Transaction should execute fn atomically, all the store methods called with the context
+	// provided to fn should be part of the transaction.
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+	InsertOutbox(ctx context.Context, record OutboxRecord) error
+	historyStore
+}
+
+// Store is implemented by all the persistent stores of items, including the outbox
+type Store interface {
+	persistentStore
+	outboxStore
+	purgeStore
+}
+
+// casError returns the error of a compare-and-swap modification which did not match any item, based
+// on the stored item (or the error fetching it). deleted is the state of the item expected by
+// the modification.
+func casError(stored *Item, err error, id int, deleted bool) error {
+	if err != nil {
+		return err
+	}
+	if stored.IsDeleted() != deleted {
+		if deleted {
+			return errors.Wrapf(ErrNotDeleted, ": %d", id)
+		}
+		return ErrNotFound
+	}
+	return errors.Wrapf(ErrVersionConflict, ": %d", id)
+}
+
+const (
+	mongoDuplicateKeyCode  = 11000
+	mongoIndexNotFoundCode = 27
+	// maxReportedDuplicates is the max number of duplicate keys reported, if a unique index can not be built
+	maxReportedDuplicates = 10
+)
+
+// mongoIndex is an index reconciled by EnsureIndexes. Indexes are identified by name and never modified
+// in place. Changing the keys/options of an index requires a new name, with the outdated names in
+// replaces, so that the new index is built before the outdated ones are dropped. e.g. the tenant was
+// prefixed to the keys of the indexes, and the ID was unique throughout the rebuild.
+type mongoIndex struct {
+	mongo.IndexModel
+	// replaces are the names of the outdated indexes superseded by this one
+	replaces []string
+}
+
+// mongoItemIndexes are the indexes of the items collection, reconciled by EnsureIndexes
+var mongoItemIndexes = []mongoIndex{
+	{
+		// uniqueness of items (per tenant) is guaranteed by this index, not by the service
+		IndexModel: mongo.IndexModel{
+			Keys:    bson.D{{Key: "tenant", Value: 1}, {Key: "id", Value: 1}},
+			Options: options.Index().SetName("tenant_id_unique").SetUnique(true),
+		},
+		replaces: []string{"id_unique"},
+	},
+	{
+		// listing sorted by name, and filtering by name prefix
+		IndexModel: mongo.IndexModel{
+			Keys:    bson.D{{Key: "tenant", Value: 1}, {Key: "name", Value: 1}, {Key: "id", Value: 1}},
+			Options: options.Index().SetName("tenant_name_id"),
+		},
+		replaces: []string{"name_id"},
+	},
+	{
+		// listing sorted by creation time
+		IndexModel: mongo.IndexModel{
+			Keys:    bson.D{{Key: "tenant", Value: 1}, {Key: "createdAt", Value: 1}, {Key: "id", Value: 1}},
+			Options: options.Index().SetName("tenant_createdAt_id"),
+		},
+		replaces: []string{"createdAt_id"},
+	},
+	{
+		// purging of deleted items, sparse since most items are not deleted
+		IndexModel: mongo.IndexModel{
+			Keys:    bson.D{{Key: "deletedAt", Value: 1}},
+			Options: options.Index().SetName("deletedAt").SetSparse(true),
+		},
+	},
+	{
+		// filtering by tags, the index is multikey since tags is an array
+		IndexModel: mongo.IndexModel{
+			Keys:    bson.D{{Key: "tags", Value: 1}},
+			Options: options.Index().SetName("tags"),
+		},
+	},
+	{
+		// filtering by labels, a wildcard index since the label keys are arbitrary
+		IndexModel: mongo.IndexModel{
+			Keys:    bson.D{{Key: "labels.$**", Value: 1}},
+			Options: options.Index().SetName("labels_wildcard"),
+		},
+	},
+}
+
+// mongoHistoryIndexes are the indexes of the item_history collection, reconciled by EnsureIndexes
+var mongoHistoryIndexes = []mongoIndex{
+	{
+		// listing the history of an item, newest first
+		IndexModel: mongo.IndexModel{
+			Keys:    bson.D{{Key: "tenant", Value: 1}, {Key: "itemId", Value: 1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("tenant_itemId_id"),
+		},
+		replaces: []string{"itemId_id"},
+	},
 }
 
 type mongoItemStore struct {
-	mongoDriver    *mongo.Database
-	itemCollection *mongo.Collection
+	mongoDriver       *mongo.Database
+	itemCollection    *mongo.Collection
+	outboxCollection  *mongo.Collection
+	historyCollection *mongo.Collection
 }
 
 func NewMongoPersistentStore(client *mongo.Database) (*mongoItemStore, error) { //nolint:revive // it is ok to return unexported type in this case, ensures controlled access
 	istore := &mongoItemStore{
-		mongoDriver:    client,
-		itemCollection: client.Collection("items"),
+		mongoDriver:       client,
+		itemCollection:    client.Collection("items"),
+		outboxCollection:  client.Collection("item_outbox"),
+		historyCollection: client.Collection("item_history"),
 	}
 	return istore, nil
 }
 
-func (istore *mongoItemStore) InsertItem(ctx context.Context, item Item) (*Item, error) {
+// EnsureIndexes creates the missing indexes of the items & item_history collections, and then drops the
+// outdated ones they replace. Indexes created outside of the app are left as is. It is safe to be run
+// concurrently by multiple instances of the app.
+// The items & history without a tenant, i.e. created before multi-tenancy, are assigned to DefaultTenant
+// beforehand, since the uniqueness of items is per tenant.
+func (istore *mongoItemStore) EnsureIndexes(ctx context.Context) error {
+	for _, collection := range []*mongo.Collection{istore.itemCollection, istore.historyCollection} {
+		_, err := collection.UpdateMany(
+			ctx,
+			bson.M{"tenant": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"tenant": DefaultTenant}},
+		)
+		if err != nil {
+			return errors.Wrapf(err, "could not set the default tenant of %s", collection.Name())
+		}
+	}
+
+	err := ensureIndexes(ctx, istore.itemCollection, mongoItemIndexes)
+	if err != nil {
+		return err
+	}
+
+	return ensureIndexes(ctx, istore.historyCollection, mongoHistoryIndexes)
+}
+
+func ensureIndexes(ctx context.Context, collection *mongo.Collection, indexes []mongoIndex) error {
+	existing, err := collection.Indexes().ListSpecifications(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "could not list %s indexes", collection.Name())
+	}
+
+	specs := make(map[string]*mongo.IndexSpecification, len(existing))
+	for _, spec := range existing {
+		specs[spec.Name] = spec
+	}
+
+	outdated := make([]string, 0, len(indexes))
+	for _, index := range indexes {
+		name := *index.Options.Name
+		if spec, ok := specs[name]; ok {
+			if !sameIndex(spec, &index.IndexModel) {
+				return errors.Errorf(
+					"%s index %s differs from the expected, a changed index should be renamed",
+					collection.Name(),
+					name,
+				)
+			}
+			continue
+		}
+
+		replaced := make([]string, 0, len(index.replaces))
+		equivalent := false
+		for _, oldName := range index.replaces {
+			spec, ok := specs[oldName]
+			if !ok {
+				continue
+			}
+			if sameIndex(spec, &index.IndexModel) {
+				// only the name is outdated, the index is retained as is
+				equivalent = true
+				break
+			}
+			replaced = append(replaced, oldName)
+		}
+		if equivalent {
+			continue
+		}
+
+		err = createIndex(ctx, collection, index.IndexModel)
+		if err != nil {
+			return err
+		}
+		outdated = append(outdated, replaced...)
+	}
+
+	for _, name := range outdated {
+		_, err = collection.Indexes().DropOne(ctx, name)
+		if err != nil && !isIndexNotFound(err) {
+			return errors.Wrapf(err, "could not drop the outdated %s index %s", collection.Name(), name)
+		}
+	}
+
+	return nil
+}
+
+// createIndex creates the index, or does nothing if it already exists (e.g. if created concurrently by another
+// instance). If a unique index can not be created due to existing duplicates, some of them are reported in the error.
+func createIndex(ctx context.Context, collection *mongo.Collection, index mongo.IndexModel) error {
+	_, err := collection.Indexes().CreateOne(ctx, index)
+	if err == nil {
+		return nil
+	}
+
+	name := *index.Options.Name
+	if !mongo.IsDuplicateKeyError(err) {
+		return errors.Wrapf(err, "could not create %s index %s", collection.Name(), name)
+	}
+
+	duplicates, derr := duplicateKeys(ctx, collection, index)
+	if derr != nil {
+		return errors.Wrapf(err, "could not create the unique %s index %s, duplicates should be resolved first", collection.Name(), name)
+	}
+
+	return errors.Wrapf(
+		err,
+		"could not create the unique %s index %s, duplicates should be resolved first: %s",
+		collection.Name(),
+		name,
+		strings.Join(duplicates, ", "),
+	)
+}
+
+// duplicateKeys returns at most maxReportedDuplicates keys of the index shared by multiple documents,
+// formatted as "{tenant: acme, id: 1} x 2"
+func duplicateKeys(ctx context.Context, collection *mongo.Collection, index mongo.IndexModel) ([]string, error) {
+	keys, _ := index.Keys.(bson.D)
+	group := make(bson.D, 0, len(keys))
+	for _, key := range keys {
+		group = append(group, bson.E{Key: key.Key, Value: "$" + key.Key})
+	}
+
+	cursor, err := collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{{Key: "_id", Value: group}, {Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}}}}},
+		{{Key: "$match", Value: bson.D{{Key: "count", Value: bson.D{{Key: "$gt", Value: 1}}}}}},
+		{{Key: "$limit", Value: maxReportedDuplicates}},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not find the duplicates of %s", collection.Name())
+	}
+
+	groups := []struct {
+		Keys  bson.M `bson:"_id"`
+		Count int    `bson:"count"`
+	}{}
+	err = cursor.All(ctx, &groups)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read the duplicates of %s", collection.Name())
+	}
+
+	duplicates := make([]string, 0, len(groups))
+	for _, dup := range groups {
+		values := make([]string, 0, len(keys))
+		for _, key := range keys {
+			values = append(values, fmt.Sprintf("%s: %v", key.Key, dup.Keys[key.Key]))
+		}
+		duplicates = append(duplicates, fmt.Sprintf("{%s} x %d", strings.Join(values, ", "), dup.Count))
+	}
+
+	return duplicates, nil
+}
+
+func isIndexNotFound(err error) bool {
+	serverErr := mongo.ServerError(nil)
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(mongoIndexNotFoundCode)
+}
+
+func sameIndex(spec *mongo.IndexSpecification, index *mongo.IndexModel) bool {
+	unique := index.Options.Unique != nil && *index.Options.Unique
+	if unique != (spec.Unique != nil && *spec.Unique) {
+		return false
+	}
+
+	keys, _ := index.Keys.(bson.D)
+	elements, err := spec.KeysDocument.Elements()
+	if err != nil || len(elements) != len(keys) {
+		return false
+	}
+	for i, element := range elements {
+		// the server may return the key direction as int32, int64 or double
+		direction, ok := element.Value().AsInt64OK()
+		expected, _ := keys[i].Value.(int)
+		if !ok || element.Key() != keys[i].Key || direction != int64(expected) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Transaction requires MongoDB to be deployed as a replica set (or sharded cluster), since
+// transactions are not supported by standalone servers.
+func (istore *mongoItemStore) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := istore.mongoDriver.Client().StartSession()
+	if err != nil {
+		return errors.Wrap(err, "could not start mongodb session")
+	}
+	defer session.EndSession(ctx)
+
+	var fnErr error
+	_, err = session.WithTransaction(ctx, func(sctx mongo.SessionContext) (any, error) {
+		fnErr = fn(sctx)
+		return nil, fnErr
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if err != nil {
+		return errors.Wrap(err, "mongodb transaction failed")
+	}
+
+	return nil
+}
+
+func (istore *mongoItemStore) InsertOutbox(ctx context.Context, record OutboxRecord) error {
+	_, err := istore.outboxCollection.InsertOne(ctx, record)
+	if err != nil {
+		return errors.Wrap(err, "could not save the outbox record")
+	}
+	return nil
+}
+
+func (istore *mongoItemStore) InsertHistory(ctx context.Context, entries []HistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	docs := make([]any, 0, len(entries))
+	for _, entry := range entries {
+		docs = append(docs, entry)
+	}
+	_, err := istore.historyCollection.InsertMany(ctx, docs)
+	if err != nil {
+		return errors.Wrap(err, "could not save the item history")
+	}
+
+	return nil
+}
+
+func (istore *mongoItemStore) ListHistory(ctx context.Context, tenant string, itemID int, query HistoryQuery) ([]HistoryEntry, error) {
+	filter := bson.M{"tenant": tenant, "itemId": itemID}
+	if query.Cursor != "" {
+		filter["_id"] = bson.M{"$lt": query.Cursor}
+	}
+
+	result, err := istore.historyCollection.Find(
+		ctx,
+		filter,
+		options.Find().SetLimit(int64(query.Limit)).SetSort(bson.D{{Key: "_id", Value: -1}}),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch the item history")
+	}
+
+	list := make([]HistoryEntry, 0, query.Limit)
+	err = result.All(ctx, &list)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch the item history")
+	}
+
+	return list, nil
+}
+
+func (istore *mongoItemStore) PendingOutbox(ctx context.Context, limit int) ([]OutboxRecord, error) {
+	result, err := istore.outboxCollection.Find(
+		ctx,
+		bson.M{"sentAt": bson.M{"$exists": false}},
+		options.Find().SetLimit(int64(limit)).SetSort(bson.D{{Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch pending outbox records")
+	}
+
+	list := make([]OutboxRecord, 0, limit)
+	err = result.All(ctx, &list)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch pending outbox records")
+	}
+
+	return list, nil
+}
+
+func (istore *mongoItemStore) MarkOutboxSent(ctx context.Context, ids []string, sentAt time.Time) error {
+	_, err := istore.outboxCollection.UpdateMany(
+		ctx,
+		bson.M{"_id": bson.M{"$in": ids}},
+		bson.M{"$set": bson.M{"sentAt": sentAt}},
+	)
+	if err != nil {
+		return errors.Wrap(err, "could not mark outbox records as sent")
+	}
+	return nil
+}
+
+func (istore *mongoItemStore) OutboxStats(ctx context.Context) (*OutboxStats, error) {
+	pendingFilter := bson.M{"sentAt": bson.M{"$exists": false}}
+	count, err := istore.outboxCollection.CountDocuments(ctx, pendingFilter)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not count pending outbox records")
+	}
+
+	stats := &OutboxStats{Pending: count}
+	if count == 0 {
+		return stats, nil
+	}
+
+	oldest := OutboxRecord{}
+	err = istore.outboxCollection.FindOne(
+		ctx,
+		pendingFilter,
+		options.FindOne().SetSort(bson.D{{Key: "_id", Value: 1}}),
+	).Decode(&oldest)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, errors.Wrap(err, "could not fetch the oldest pending outbox record")
+	}
+	stats.OldestCreatedAt = oldest.CreatedAt
+
+	return stats, nil
+}
+
+func (istore *mongoItemStore) InsertItem(ctx context.Context, tenant string, item Item) (*Item, error) {
+	item.Tenant = tenant
 	_, err := istore.itemCollection.InsertOne(ctx, item)
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, errors.Wrapf(ErrDuplicateItem, ": %d", item.ID)
+		}
 		return nil, errors.Wrap(err, "could not save the item")
 	}
 
 	return &item, nil
 }
 
-func (istore *mongoItemStore) Item(ctx context.Context, id int) (*Item, error) {
-	result := istore.itemCollection.FindOne(ctx, bson.M{"id": bson.M{"$eq": id}})
+// InsertItems inserts all the items with a single unordered bulk write
+func (istore *mongoItemStore) InsertItems(ctx context.Context, tenant string, items []Item) ([]error, error) {
+	docs := make([]any, 0, len(items))
+	for _, item := range items {
+		item.Tenant = tenant
+		docs = append(docs, item)
+	}
+
+	itemErrs := make([]error, len(items))
+	_, err := istore.itemCollection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err == nil {
+		return itemErrs, nil
+	}
+
+	bwException := mongo.BulkWriteException{}
+	// with a write concern error, it is unknown which of the items were inserted
+	if !errors.As(err, &bwException) || bwException.WriteConcernError != nil {
+		return nil, errors.Wrap(err, "could not save the items")
+	}
+
+	for _, writeErr := range bwException.WriteErrors {
+		if writeErr.Index < 0 || writeErr.Index >= len(items) {
+			continue
+		}
+		id := items[writeErr.Index].ID
+		if writeErr.HasErrorCode(mongoDuplicateKeyCode) {
+			itemErrs[writeErr.Index] = errors.Wrapf(ErrDuplicateItem, ": %d", id)
+			continue
+		}
+		itemErrs[writeErr.Index] = errors.Wrapf(writeErr, "could not save the item %d", id)
+	}
+
+	return itemErrs, nil
+}
+
+func (istore *mongoItemStore) Item(ctx context.Context, tenant string, id int) (*Item, error) {
+	result := istore.itemCollection.FindOne(ctx, bson.M{"tenant": bson.M{"$eq": tenant}, "id": bson.M{"$eq": id}})
 	item := new(Item)
 	err := result.Decode(item)
 	if err != nil {
@@ -51,13 +536,27 @@ func (istore *mongoItemStore) Item(ctx context.Context, id int) (*Item, error) {
 	return item, nil
 }
 
-func (istore *mongoItemStore) ListItems(ctx context.Context, limit int) ([]Item, error) {
-	result, err := istore.itemCollection.Find(ctx, bson.M{}, options.Find().SetLimit(int64(limit)))
+func (istore *mongoItemStore) ListItems(ctx context.Context, tenant string, query ListQuery) ([]Item, error) {
+	sortKey := mongoSortKey(query.SortBy)
+	direction := 1
+	if query.Descending {
+		direction = -1
+	}
+	sort := bson.D{{Key: sortKey, Value: direction}}
+	if sortKey != "id" {
+		sort = append(sort, bson.E{Key: "id", Value: direction})
+	}
+
+	result, err := istore.itemCollection.Find(
+		ctx,
+		mongoListFilter(tenant, &query),
+		options.Find().SetLimit(int64(query.Limit)).SetSort(sort),
+	)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not fetch items")
 	}
 
-	list := make([]Item, 0, limit)
+	list := make([]Item, 0, query.Limit)
 	err = result.All(ctx, &list)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not fetch items")
@@ -65,3 +564,221 @@ func (istore *mongoItemStore) ListItems(ctx context.Context, limit int) ([]Item,
 
 	return list, nil
 }
+
+func (istore *mongoItemStore) UpdateItem(ctx context.Context, tenant string, item Item) (*Item, error) {
+	result := istore.itemCollection.FindOneAndUpdate(
+		ctx,
+		versionFilter(tenant, item.ID, item.Version, false),
+		bson.M{
+			"$set": bson.M{
+				"name":       item.Name,
+				"tags":       item.Tags,
+				"labels":     item.Labels,
+				"attributes": item.Attributes,
+				"updatedAt":  item.UpdatedAt,
+			},
+			"$inc": bson.M{"version": 1},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+
+	return istore.decodeModified(ctx, result, tenant, item.ID, false, "could not update the item")
+}
+
+func (istore *mongoItemStore) PatchItem(ctx context.Context, tenant string, id int, patch Patch, updatedAt time.Time) (*Item, error) {
+	fields := bson.M{"updatedAt": updatedAt}
+	if patch.Name != nil {
+		fields["name"] = *patch.Name
+	}
+	if patch.Tags != nil {
+		fields["tags"] = patch.Tags
+	}
+	if patch.Labels != nil {
+		fields["labels"] = patch.Labels
+	}
+	if patch.Attributes != nil {
+		fields["attributes"] = patch.Attributes
+	}
+
+	result := istore.itemCollection.FindOneAndUpdate(
+		ctx,
+		versionFilter(tenant, id, patch.Version, false),
+		bson.M{"$set": fields, "$inc": bson.M{"version": 1}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+
+	return istore.decodeModified(ctx, result, tenant, id, false, "could not patch the item")
+}
+
+func (istore *mongoItemStore) DeleteItem(
+	ctx context.Context,
+	tenant string,
+	id int,
+	version int64,
+	deletedAt time.Time,
+	deletedBy string,
+) (*Item, error) {
+	result := istore.itemCollection.FindOneAndUpdate(
+		ctx,
+		versionFilter(tenant, id, version, false),
+		bson.M{
+			"$set": bson.M{"deletedAt": deletedAt, "deletedBy": deletedBy, "updatedAt": deletedAt},
+			"$inc": bson.M{"version": 1},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+
+	return istore.decodeModified(ctx, result, tenant, id, false, "could not delete the item")
+}
+
+func (istore *mongoItemStore) RestoreItem(ctx context.Context, tenant string, id int, version int64, restoredAt time.Time) (*Item, error) {
+	result := istore.itemCollection.FindOneAndUpdate(
+		ctx,
+		versionFilter(tenant, id, version, true),
+		bson.M{
+			"$set":   bson.M{"updatedAt": restoredAt},
+			"$unset": bson.M{"deletedAt": "", "deletedBy": ""},
+			"$inc":   bson.M{"version": 1},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+
+	return istore.decodeModified(ctx, result, tenant, id, true, "could not restore the item")
+}
+
+// PurgeItems hard deletes at most limit items of all the tenants, which were deleted before the
+// time provided
+func (istore *mongoItemStore) PurgeItems(ctx context.Context, deletedBefore time.Time, limit int) (int64, error) {
+	filter := bson.M{"deletedAt": bson.M{"$lt": deletedBefore}}
+	result, err := istore.itemCollection.Find(
+		ctx,
+		filter,
+		options.Find().SetLimit(int64(limit)).SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not fetch items to purge")
+	}
+
+	// the items are purged by the document ID, since the item IDs are unique only per tenant
+	list := make([]bson.Raw, 0, limit)
+	err = result.All(ctx, &list)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not fetch items to purge")
+	}
+	if len(list) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]bson.RawValue, 0, len(list))
+	for _, doc := range list {
+		ids = append(ids, doc.Lookup("_id"))
+	}
+	// the deletion time is checked again, in case any of the items were restored meanwhile
+	filter["_id"] = bson.M{"$in": ids}
+	deleted, err := istore.itemCollection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not purge items")
+	}
+
+	return deleted.DeletedCount, nil
+}
+
+func (istore *mongoItemStore) decodeModified(
+	ctx context.Context,
+	result *mongo.SingleResult,
+	tenant string,
+	id int,
+	deleted bool,
+	failMsg string,
+) (*Item, error) {
+	item := new(Item)
+	err := result.Decode(item)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			stored, serr := istore.Item(ctx, tenant, id)
+			return nil, casError(stored, serr, id, deleted)
+		}
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	return item, nil
+}
+
+func mongoSortKey(field SortField) string {
+	switch field {
+	case SortByName:
+		return "name"
+	case SortByCreatedAt:
+		return "createdAt"
+	case SortByID:
+	}
+	return "id"
+}
+
+// mongoListFilter always filters by the tenant, hence the filter is never empty
+func mongoListFilter(tenant string, query *ListQuery) bson.M {
+	conditions := bson.A{bson.M{"tenant": bson.M{"$eq": tenant}}}
+	if !query.IncludeDeleted {
+		// matches both missing & null
+		conditions = append(conditions, bson.M{"deletedAt": nil})
+	}
+	if query.NamePrefix != "" {
+		conditions = append(conditions, bson.M{"name": bson.M{"$regex": "^" + regexp.QuoteMeta(query.NamePrefix)}})
+	}
+	if query.NameContains != "" {
+		conditions = append(conditions, bson.M{"name": bson.M{"$regex": regexp.QuoteMeta(query.NameContains)}})
+	}
+	if query.IDFrom > 0 {
+		conditions = append(conditions, bson.M{"id": bson.M{"$gte": query.IDFrom}})
+	}
+	if query.IDTo > 0 {
+		conditions = append(conditions, bson.M{"id": bson.M{"$lte": query.IDTo}})
+	}
+	if len(query.Tags) > 0 {
+		conditions = append(conditions, bson.M{"tags": bson.M{"$all": query.Tags}})
+	}
+	// the label keys are validated by the service, so they're safe to be used as field names
+	for _, key := range slices.Sorted(maps.Keys(query.Labels)) {
+		conditions = append(conditions, bson.M{"labels." + key: bson.M{"$eq": query.Labels[key]}})
+	}
+
+	if after := query.after; after != nil {
+		operator := "$gt"
+		if query.Descending {
+			operator = "$lt"
+		}
+		var afterValue any
+		switch query.SortBy {
+		case SortByName:
+			afterValue = after.Name
+		case SortByCreatedAt:
+			afterValue = after.CreatedAt
+		case SortByID:
+		}
+
+		if afterValue == nil {
+			conditions = append(conditions, bson.M{"id": bson.M{operator: after.ID}})
+		} else {
+			key := mongoSortKey(query.SortBy)
+			conditions = append(conditions, bson.M{"$or": bson.A{
+				bson.M{key: bson.M{operator: afterValue}},
+				bson.M{key: bson.M{"$eq": afterValue}, "id": bson.M{operator: after.ID}},
+			}})
+		}
+	}
+
+	return bson.M{"$and": conditions}
+}
+
+// versionFilter matches the item by tenant & ID, and by version if > 0. deleted is the expected deleted
+// state of the item.
+func versionFilter(tenant string, id int, version int64, deleted bool) bson.M {
+	filter := bson.M{"tenant": bson.M{"$eq": tenant}, "id": bson.M{"$eq": id}, "deletedAt": nil}
+	if deleted {
+		filter["deletedAt"] = bson.M{"$ne": nil}
+	}
+	if version > 0 {
+		filter["version"] = bson.M{"$eq": version}
+	}
+	return filter
+}
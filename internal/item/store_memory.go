@@ -0,0 +1,341 @@
+package item
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/naughtygopher/errors"
+)
+
+type memoryTxnKey struct{}
+
+// memoryItemStore keeps everything in memory, and is meant for local development & tests. It is
+// goroutine safe, and transactions are serialized with all the other operations. The items are copied
+// when stored & returned, so that the callers never share their tags, labels or attributes with the store.
+type memoryItemStore struct {
+	locker  *sync.RWMutex
+	items   map[itemKey]Item
+	outbox  []OutboxRecord
+	history []HistoryEntry
+}
+
+func NewMemoryPersistentStore() (*memoryItemStore, error) { //nolint:revive // it is ok to return unexported type in this case, ensures controlled access
+	return &memoryItemStore{
+		locker: &sync.RWMutex{},
+		items:  make(map[itemKey]Item),
+	}, nil
+}
+
+// lock acquires the write lock, unless the context is of an ongoing transaction which already holds it.
+// The returned function should be called to release the lock.
+func (mstore *memoryItemStore) lock(ctx context.Context) func() {
+	if ctx.Value(memoryTxnKey{}) == mstore {
+		return func() {}
+	}
+	mstore.locker.Lock()
+	return mstore.locker.Unlock
+}
+
+func (mstore *memoryItemStore) rlock(ctx context.Context) func() {
+	if ctx.Value(memoryTxnKey{}) == mstore {
+		return func() {}
+	}
+	mstore.locker.RLock()
+	return mstore.locker.RUnlock
+}
+
+// Transaction holds the lock for the whole duration of fn, and restores the state if fn fails.
+func (mstore *memoryItemStore) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	unlock := mstore.lock(ctx)
+	defer unlock()
+
+	items := maps.Clone(mstore.items)
+	outbox := slices.Clone(mstore.outbox)
+	history := slices.Clone(mstore.history)
+
+	err := fn(context.WithValue(ctx, memoryTxnKey{}, mstore))
+	if err != nil {
+		mstore.items = items
+		mstore.outbox = outbox
+		mstore.history = history
+		return err
+	}
+
+	return nil
+}
+
+func (mstore *memoryItemStore) InsertItem(ctx context.Context, tenant string, item Item) (*Item, error) {
+	unlock := mstore.lock(ctx)
+	defer unlock()
+
+	item.Tenant = tenant
+	if _, ok := mstore.items[keyOf(&item)]; ok {
+		return nil, errors.Wrapf(ErrDuplicateItem, ": %d", item.ID)
+	}
+
+	return mstore.save(&item), nil
+}
+
+func (mstore *memoryItemStore) InsertItems(ctx context.Context, tenant string, items []Item) ([]error, error) {
+	unlock := mstore.lock(ctx)
+	defer unlock()
+
+	itemErrs := make([]error, len(items))
+	for idx, item := range items {
+		item.Tenant = tenant
+		if _, ok := mstore.items[keyOf(&item)]; ok {
+			itemErrs[idx] = errors.Wrapf(ErrDuplicateItem, ": %d", item.ID)
+			continue
+		}
+		mstore.save(&item)
+	}
+
+	return itemErrs, nil
+}
+
+func (mstore *memoryItemStore) Item(ctx context.Context, tenant string, id int) (*Item, error) {
+	unlock := mstore.rlock(ctx)
+	defer unlock()
+
+	item, ok := mstore.items[itemKey{tenant: tenant, id: id}]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	copied := item.clone()
+	return &copied, nil
+}
+
+// ListItems scans & sorts all the items of the tenant for every page, i.e. it is O(n log n) per page
+// and is fine only for the small number of items of development & tests.
+func (mstore *memoryItemStore) ListItems(ctx context.Context, tenant string, query ListQuery) ([]Item, error) {
+	unlock := mstore.rlock(ctx)
+	defer unlock()
+
+	list := make([]Item, 0, query.Limit)
+	for _, item := range mstore.items {
+		if item.Tenant == tenant && query.matches(&item) {
+			list = append(list, item)
+		}
+	}
+	slices.SortFunc(list, func(a, b Item) int {
+		return query.compare(&a, &b)
+	})
+	if len(list) > query.Limit {
+		list = list[:query.Limit]
+	}
+	for idx := range list {
+		list[idx] = list[idx].clone()
+	}
+
+	return list, nil
+}
+
+func (mstore *memoryItemStore) UpdateItem(ctx context.Context, tenant string, item Item) (*Item, error) {
+	unlock := mstore.lock(ctx)
+	defer unlock()
+
+	stored, err := mstore.casItem(tenant, item.ID, item.Version, false)
+	if err != nil {
+		return nil, err
+	}
+	stored.Name = item.Name
+	stored.Tags = item.Tags
+	stored.Labels = item.Labels
+	stored.Attributes = item.Attributes
+	stored.UpdatedAt = item.UpdatedAt
+	stored.Version++
+
+	return mstore.save(&stored), nil
+}
+
+func (mstore *memoryItemStore) PatchItem(ctx context.Context, tenant string, id int, patch Patch, updatedAt time.Time) (*Item, error) {
+	unlock := mstore.lock(ctx)
+	defer unlock()
+
+	stored, err := mstore.casItem(tenant, id, patch.Version, false)
+	if err != nil {
+		return nil, err
+	}
+	if patch.Name != nil {
+		stored.Name = *patch.Name
+	}
+	if patch.Tags != nil {
+		stored.Tags = patch.Tags
+	}
+	if patch.Labels != nil {
+		stored.Labels = patch.Labels
+	}
+	if patch.Attributes != nil {
+		stored.Attributes = patch.Attributes
+	}
+	stored.UpdatedAt = updatedAt
+	stored.Version++
+
+	return mstore.save(&stored), nil
+}
+
+func (mstore *memoryItemStore) DeleteItem(
+	ctx context.Context,
+	tenant string,
+	id int,
+	version int64,
+	deletedAt time.Time,
+	deletedBy string,
+) (*Item, error) {
+	unlock := mstore.lock(ctx)
+	defer unlock()
+
+	stored, err := mstore.casItem(tenant, id, version, false)
+	if err != nil {
+		return nil, err
+	}
+	stored.DeletedAt = &deletedAt
+	stored.DeletedBy = deletedBy
+	stored.UpdatedAt = deletedAt
+	stored.Version++
+
+	return mstore.save(&stored), nil
+}
+
+func (mstore *memoryItemStore) RestoreItem(ctx context.Context, tenant string, id int, version int64, restoredAt time.Time) (*Item, error) {
+	unlock := mstore.lock(ctx)
+	defer unlock()
+
+	stored, err := mstore.casItem(tenant, id, version, true)
+	if err != nil {
+		return nil, err
+	}
+	stored.DeletedAt = nil
+	stored.DeletedBy = ""
+	stored.UpdatedAt = restoredAt
+	stored.Version++
+
+	return mstore.save(&stored), nil
+}
+
+func (mstore *memoryItemStore) PurgeItems(ctx context.Context, deletedBefore time.Time, limit int) (int64, error) {
+	unlock := mstore.lock(ctx)
+	defer unlock()
+
+	purged := int64(0)
+	for key, item := range mstore.items {
+		if purged == int64(limit) {
+			break
+		}
+		if item.IsDeleted() && item.DeletedAt.Before(deletedBefore) {
+			delete(mstore.items, key)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// save stores a copy of the item, and returns another copy. So that neither the maps & slices of the
+// caller nor the ones returned are shared with the stored item. It should be called while holding the lock.
+func (mstore *memoryItemStore) save(item *Item) *Item {
+	mstore.items[keyOf(item)] = item.clone()
+	copied := item.clone()
+	return &copied
+}
+
+// casItem returns the stored item if the version and the deleted state match, it should be called
+// while holding the lock
+func (mstore *memoryItemStore) casItem(tenant string, id int, version int64, deleted bool) (Item, error) {
+	stored, ok := mstore.items[itemKey{tenant: tenant, id: id}]
+	if !ok {
+		return stored, ErrNotFound
+	}
+	if stored.IsDeleted() != deleted || (version > 0 && stored.Version != version) {
+		return stored, casError(&stored, nil, id, deleted)
+	}
+	return stored, nil
+}
+
+func (mstore *memoryItemStore) InsertOutbox(ctx context.Context, record OutboxRecord) error {
+	unlock := mstore.lock(ctx)
+	defer unlock()
+
+	mstore.outbox = append(mstore.outbox, record)
+	return nil
+}
+
+func (mstore *memoryItemStore) InsertHistory(ctx context.Context, entries []HistoryEntry) error {
+	unlock := mstore.lock(ctx)
+	defer unlock()
+
+	mstore.history = append(mstore.history, entries...)
+	return nil
+}
+
+func (mstore *memoryItemStore) ListHistory(ctx context.Context, tenant string, itemID int, query HistoryQuery) ([]HistoryEntry, error) {
+	unlock := mstore.rlock(ctx)
+	defer unlock()
+
+	list := make([]HistoryEntry, 0, query.Limit)
+	for _, entry := range mstore.history {
+		if entry.Tenant == tenant && entry.ItemID == itemID && (query.Cursor == "" || entry.ID < query.Cursor) {
+			list = append(list, entry)
+		}
+	}
+	slices.SortFunc(list, func(a, b HistoryEntry) int {
+		return strings.Compare(b.ID, a.ID)
+	})
+	if len(list) > query.Limit {
+		list = list[:query.Limit]
+	}
+
+	return list, nil
+}
+
+func (mstore *memoryItemStore) PendingOutbox(ctx context.Context, limit int) ([]OutboxRecord, error) {
+	unlock := mstore.rlock(ctx)
+	defer unlock()
+
+	list := make([]OutboxRecord, 0, limit)
+	for _, record := range mstore.outbox {
+		if len(list) == limit {
+			break
+		}
+		if record.SentAt == nil {
+			list = append(list, record)
+		}
+	}
+
+	return list, nil
+}
+
+// MarkOutboxSent removes the sent records, since there's no use keeping them in memory
+func (mstore *memoryItemStore) MarkOutboxSent(ctx context.Context, ids []string, _ time.Time) error {
+	unlock := mstore.lock(ctx)
+	defer unlock()
+
+	mstore.outbox = slices.DeleteFunc(mstore.outbox, func(record OutboxRecord) bool {
+		return slices.Contains(ids, record.ID)
+	})
+
+	return nil
+}
+
+func (mstore *memoryItemStore) OutboxStats(ctx context.Context) (*OutboxStats, error) {
+	unlock := mstore.rlock(ctx)
+	defer unlock()
+
+	stats := &OutboxStats{}
+	for _, record := range mstore.outbox {
+		if record.SentAt != nil {
+			continue
+		}
+		if stats.Pending == 0 {
+			stats.OldestCreatedAt = record.CreatedAt
+		}
+		stats.Pending++
+	}
+
+	return stats, nil
+}
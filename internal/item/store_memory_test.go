@@ -0,0 +1,182 @@
+package item
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/naughtygopher/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+	ctx := t.Context()
+
+	mstore, err := NewMemoryPersistentStore()
+	requirer.NoError(err)
+	svc, err := NewService(mstore, nil, newPubMocker(make(chan []byte, 1024)), NoopNamer(), nil)
+	requirer.NoError(err)
+
+	t.Run("concurrent creates are all persisted", func(_ *testing.T) {
+		wgroup := &sync.WaitGroup{}
+		for id := 1; id <= 50; id++ {
+			wgroup.Add(1)
+			go func() {
+				defer wgroup.Done()
+				_, cerr := svc.Create(ctx, Item{ID: id, Name: "Box"})
+				asserter.NoError(cerr)
+				_, lerr := svc.List(ctx, ListQuery{Limit: 5})
+				asserter.NoError(lerr)
+			}()
+		}
+		wgroup.Wait()
+		asserter.Len(mstore.items, 50)
+	})
+
+	t.Run("duplicates are rejected", func(_ *testing.T) {
+		_, cerr := mstore.InsertItem(ctx, DefaultTenant, Item{ID: 1, Name: "Box"})
+		requirer.ErrorIs(cerr, ErrDuplicateItem)
+	})
+
+	t.Run("items are isolated per tenant", func(_ *testing.T) {
+		actx := WithTenant(ctx, "acme")
+		it, cerr := svc.Create(actx, Item{ID: 1, Name: "Anvil"})
+		requirer.NoError(cerr)
+		asserter.Equal("acme", it.Tenant)
+
+		got, gerr := svc.Get(ctx, 1, false)
+		requirer.NoError(gerr)
+		asserter.Equal("Box", got.Name)
+		asserter.Equal(DefaultTenant, got.Tenant)
+
+		result, lerr := svc.List(actx, ListQuery{Limit: 10})
+		requirer.NoError(lerr)
+		requirer.Len(result.Items, 1)
+		asserter.Equal("Anvil", result.Items[0].Name)
+
+		_, gerr = svc.Get(WithTenant(ctx, "globex"), 1, false)
+		requirer.ErrorIs(gerr, ErrNotFound)
+		_, gerr = svc.Get(WithTenant(ctx, "Not Valid"), 1, false)
+		requirer.ErrorIs(gerr, ErrInvalidTenant)
+	})
+
+	t.Run("items are listed in order, within the limit", func(_ *testing.T) {
+		result, lerr := svc.List(ctx, ListQuery{Limit: 3, Descending: true})
+		requirer.NoError(lerr)
+		ids := []int{}
+		for _, it := range result.Items {
+			ids = append(ids, it.ID)
+		}
+		asserter.Equal([]int{50, 49, 48}, ids)
+		asserter.NotEmpty(result.NextCursor)
+	})
+
+	t.Run("the items stored & returned are not shared with the callers", func(_ *testing.T) {
+		labels := map[string]string{"color": "red"}
+		tags := []string{"fragile"}
+		inserted, ierr := mstore.InsertItem(ctx, "globex", Item{ID: 1, Name: "Vase", Tags: tags, Labels: labels})
+		requirer.NoError(ierr)
+		labels["color"] = "blue"
+		tags[0] = "sturdy"
+		inserted.Labels["size"] = "large"
+
+		got, gerr := mstore.Item(ctx, "globex", 1)
+		requirer.NoError(gerr)
+		asserter.Equal(map[string]string{"color": "red"}, got.Labels)
+		asserter.Equal([]string{"fragile"}, got.Tags)
+		got.Labels["color"] = "green"
+
+		patchLabels := map[string]string{"color": "white"}
+		patched, perr := mstore.PatchItem(ctx, "globex", 1, Patch{Labels: patchLabels}, time.Now())
+		requirer.NoError(perr)
+		patchLabels["color"] = "black"
+		patched.Labels["color"] = "grey"
+
+		listed, lerr := mstore.ListItems(ctx, "globex", ListQuery{Limit: 10})
+		requirer.NoError(lerr)
+		requirer.Len(listed, 1)
+		asserter.Equal(map[string]string{"color": "white"}, listed[0].Labels)
+		listed[0].Labels["color"] = "pink"
+
+		got, gerr = mstore.Item(ctx, "globex", 1)
+		requirer.NoError(gerr)
+		asserter.Equal(map[string]string{"color": "white"}, got.Labels)
+	})
+
+	t.Run("modifications are compare-and-swap", func(_ *testing.T) {
+		it, uerr := svc.Update(ctx, Item{ID: 2, Name: "Crate", Version: 1})
+		requirer.NoError(uerr)
+		asserter.Equal(int64(2), it.Version)
+		asserter.False(it.CreatedAt.IsZero())
+
+		_, uerr = svc.Update(ctx, Item{ID: 2, Name: "Bin", Version: 1})
+		requirer.ErrorIs(uerr, ErrVersionConflict)
+		requirer.ErrorIs(svc.Delete(ctx, 2, 1), ErrVersionConflict)
+		requirer.NoError(svc.Delete(ctx, 2, 2))
+
+		_, gerr := svc.Get(ctx, 2, false)
+		requirer.ErrorIs(gerr, ErrNotFound)
+		_, perr := mstore.PatchItem(ctx, DefaultTenant, 2, Patch{}, time.Now())
+		requirer.ErrorIs(perr, ErrNotFound)
+	})
+
+	t.Run("deleted items are restored", func(_ *testing.T) {
+		it, rerr := svc.Restore(ctx, 2, 3)
+		requirer.NoError(rerr)
+		asserter.Nil(it.DeletedAt)
+		asserter.Equal(int64(4), it.Version)
+
+		_, rerr = svc.Restore(ctx, 2, 0)
+		requirer.ErrorIs(rerr, ErrNotDeleted)
+	})
+
+	t.Run("deleted items are purged after retention", func(_ *testing.T) {
+		requirer.NoError(svc.Delete(ctx, 3, 0))
+		requirer.NoError(svc.Delete(ctx, 4, 0))
+
+		progressed := make(chan PurgeProgress, 1)
+		purger, perr := NewPurger(mstore, &PurgerConfig{
+			Retention: time.Nanosecond,
+			BatchSize: 1,
+			OnProgress: func(progress PurgeProgress) {
+				progressed <- progress
+			},
+		})
+		requirer.NoError(perr)
+		go func() {
+			_ = purger.Start(ctx)
+		}()
+
+		progress := <-progressed
+		requirer.NoError(purger.Shutdown(ctx))
+		requirer.NoError(progress.Err)
+		asserter.Equal(int64(2), progress.Purged)
+		asserter.Equal(int64(2), progress.TotalPurged)
+
+		_, gerr := mstore.Item(ctx, DefaultTenant, 3)
+		requirer.ErrorIs(gerr, ErrNotFound)
+		_, gerr = svc.Get(ctx, 2, false)
+		requirer.NoError(gerr)
+	})
+
+	t.Run("failed transactions are rolled back", func(_ *testing.T) {
+		errAbort := errors.New("abort")
+		terr := mstore.Transaction(ctx, func(tctx context.Context) error {
+			_, ierr := mstore.InsertItem(tctx, DefaultTenant, Item{ID: 100, Name: "Tray"})
+			requirer.NoError(ierr)
+			requirer.NoError(mstore.InsertOutbox(tctx, OutboxRecord{ID: "1"}))
+			return errAbort
+		})
+		requirer.ErrorIs(terr, errAbort)
+
+		_, gerr := mstore.Item(ctx, DefaultTenant, 100)
+		requirer.ErrorIs(gerr, ErrNotFound)
+		stats, serr := mstore.OutboxStats(ctx)
+		requirer.NoError(serr)
+		asserter.Equal(int64(0), stats.Pending)
+	})
+}
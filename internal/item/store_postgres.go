@@ -0,0 +1,696 @@
+package item
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/naughtygopher/errors"
+
+	"github.com/prashantkr001/template-go/internal/pkg/migrate"
+)
+
+const (
+	postgresMigrationsDir   = "migrations/postgres"
+	postgresMigrationsTable = "item_schema_migrations"
+	// postgresUniqueViolation is the error code of unique constraint violations
+	postgresUniqueViolation = "23505"
+
+	postgresInsertColumns = "tenant, id, name, version, created_at, updated_at, tags, labels, attributes"
+	postgresItemColumns   = postgresInsertColumns + ", deleted_at, deleted_by"
+)
+
+//go:embed migrations/postgres/*.sql
+var postgresMigrations embed.FS
+
+type postgresTxnKey struct{}
+
+// sqlQuerier is implemented by both *sql.DB & *sql.Tx
+type sqlQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type postgresItemStore struct {
+	db *sql.DB
+}
+
+// NewPostgresPersistentStore expects the schema to be migrated already, using MigratePostgres
+func NewPostgresPersistentStore(db *sql.DB) (*postgresItemStore, error) { //nolint:revive // it is ok to return unexported type in this case, ensures controlled access
+	return &postgresItemStore{db: db}, nil
+}
+
+// MigratePostgres applies the pending schema migrations of the item store, and returns the
+// versions applied.
+func MigratePostgres(ctx context.Context, db *sql.DB) ([]int, error) {
+	applied, err := migrate.Up(ctx, db, postgresMigrations, postgresMigrationsDir, postgresMigrationsTable)
+	versions := make([]int, 0, len(applied))
+	for _, migration := range applied {
+		versions = append(versions, migration.Version)
+	}
+	if err != nil {
+		return versions, errors.Wrap(err, "item store migration failed")
+	}
+	return versions, nil
+}
+
+// querier returns the transaction if the context is of an ongoing transaction
+func (pstore *postgresItemStore) querier(ctx context.Context) sqlQuerier { //nolint:ireturn // either the DB or the transaction
+	txn, _ := ctx.Value(postgresTxnKey{}).(*sql.Tx)
+	if txn != nil {
+		return txn
+	}
+	return pstore.db
+}
+
+func (pstore *postgresItemStore) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(postgresTxnKey{}).(*sql.Tx); ok {
+		// already in a transaction
+		return fn(ctx)
+	}
+
+	txn, err := pstore.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+
+	err = fn(context.WithValue(ctx, postgresTxnKey{}, txn))
+	if err != nil {
+		_ = txn.Rollback()
+		return err
+	}
+
+	err = txn.Commit()
+	if err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+
+	return nil
+}
+
+func (pstore *postgresItemStore) InsertItem(ctx context.Context, tenant string, item Item) (*Item, error) {
+	metadata, err := postgresMetadata(item.Tags, item.Labels, item.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Tenant = tenant
+	_, err = pstore.querier(ctx).ExecContext(
+		ctx,
+		`INSERT INTO items (`+postgresInsertColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		append([]any{item.Tenant, item.ID, item.Name, item.Version, item.CreatedAt, item.UpdatedAt}, metadata...)...,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, errors.Wrapf(ErrDuplicateItem, ": %d", item.ID)
+		}
+		return nil, errors.Wrap(err, "could not save the item")
+	}
+
+	return &item, nil
+}
+
+// InsertItems inserts all the items with a single statement, items which conflict with an existing ID
+// (or a preceding item of the batch) are skipped & reported as duplicates.
+func (pstore *postgresItemStore) InsertItems(ctx context.Context, tenant string, items []Item) ([]error, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	const columns = 9
+	values := make([]string, 0, len(items))
+	args := make([]any, 0, len(items)*columns)
+	for idx, item := range items {
+		metadata, err := postgresMetadata(item.Tags, item.Labels, item.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, "("+postgresPlaceholders(idx*columns+1, columns)+")")
+		args = append(args, tenant, item.ID, item.Name, item.Version, item.CreatedAt, item.UpdatedAt)
+		args = append(args, metadata...)
+	}
+
+	rows, err := pstore.querier(ctx).QueryContext(
+		ctx,
+		`INSERT INTO items (`+postgresInsertColumns+`) VALUES `+strings.Join(values, ", ")+` ON CONFLICT (tenant, id) DO NOTHING RETURNING id`,
+		args...,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not save the items")
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	inserted := make(map[int]bool, len(items))
+	for rows.Next() {
+		id := 0
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not save the items")
+		}
+		inserted[id] = true
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not save the items")
+	}
+
+	itemErrs := make([]error, len(items))
+	for idx, item := range items {
+		if !inserted[item.ID] {
+			itemErrs[idx] = errors.Wrapf(ErrDuplicateItem, ": %d", item.ID)
+			continue
+		}
+		// a repeated ID in the batch is inserted only once
+		delete(inserted, item.ID)
+	}
+
+	return itemErrs, nil
+}
+
+func (pstore *postgresItemStore) Item(ctx context.Context, tenant string, id int) (*Item, error) {
+	row := pstore.querier(ctx).QueryRowContext(
+		ctx,
+		`SELECT `+postgresItemColumns+` FROM items WHERE tenant = $1 AND id = $2`,
+		tenant, id,
+	)
+
+	item, err := scanItem(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, errors.Wrap(err, "failed getting item")
+	}
+
+	return item, nil
+}
+
+func (pstore *postgresItemStore) ListItems(ctx context.Context, tenant string, query ListQuery) ([]Item, error) {
+	where, args := postgresListFilter(tenant, &query)
+	direction := "ASC"
+	if query.Descending {
+		direction = "DESC"
+	}
+	orderBy := "id " + direction
+	if sortColumn := postgresSortColumn(query.SortBy); sortColumn != "id" {
+		orderBy = fmt.Sprintf("%s %s, id %s", sortColumn, direction, direction)
+	}
+
+	args = append(args, query.Limit)
+	rows, err := pstore.querier(ctx).QueryContext(
+		ctx,
+		fmt.Sprintf(
+			`SELECT %s FROM items %s ORDER BY %s LIMIT $%d`,
+			postgresItemColumns, where, orderBy, len(args),
+		),
+		args...,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch items")
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	list := make([]Item, 0, query.Limit)
+	for rows.Next() {
+		item, serr := scanItem(rows)
+		if serr != nil {
+			return nil, errors.Wrap(serr, "could not fetch items")
+		}
+		list = append(list, *item)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch items")
+	}
+
+	return list, nil
+}
+
+func (pstore *postgresItemStore) UpdateItem(ctx context.Context, tenant string, item Item) (*Item, error) {
+	metadata, err := postgresMetadata(item.Tags, item.Labels, item.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	where, args := postgresVersionFilter(tenant, item.ID, item.Version, false, 6)
+	row := pstore.querier(ctx).QueryRowContext(
+		ctx,
+		`UPDATE items SET name = $1, tags = $2, labels = $3, attributes = $4, updated_at = $5, version = version + 1 `+
+			where+` RETURNING `+postgresItemColumns,
+		slices.Concat([]any{item.Name}, metadata, []any{item.UpdatedAt}, args)...,
+	)
+
+	return pstore.scanModified(ctx, row, tenant, item.ID, false, "could not update the item")
+}
+
+func (pstore *postgresItemStore) PatchItem(ctx context.Context, tenant string, id int, patch Patch, updatedAt time.Time) (*Item, error) {
+	// the fields which are not patched are NULL, so that the existing values are retained
+	metadata := []any{nil, nil, nil}
+	patched := []struct {
+		set   bool
+		value any
+	}{
+		{set: patch.Tags != nil, value: patch.Tags},
+		{set: patch.Labels != nil, value: patch.Labels},
+		{set: patch.Attributes != nil, value: patch.Attributes},
+	}
+	for idx, field := range patched {
+		if !field.set {
+			continue
+		}
+		jbytes, err := json.Marshal(field.value)
+		if err != nil {
+			return nil, errors.Wrap(err, "json marshal failed")
+		}
+		metadata[idx] = jbytes
+	}
+
+	where, args := postgresVersionFilter(tenant, id, patch.Version, false, 6)
+	row := pstore.querier(ctx).QueryRowContext(
+		ctx,
+		`UPDATE items SET name = COALESCE($1, name), tags = COALESCE($2, tags), labels = COALESCE($3, labels), `+
+			`attributes = COALESCE($4, attributes), updated_at = $5, version = version + 1 `+where+` RETURNING `+postgresItemColumns,
+		slices.Concat([]any{patch.Name}, metadata, []any{updatedAt}, args)...,
+	)
+
+	return pstore.scanModified(ctx, row, tenant, id, false, "could not patch the item")
+}
+
+func (pstore *postgresItemStore) DeleteItem(
+	ctx context.Context,
+	tenant string,
+	id int,
+	version int64,
+	deletedAt time.Time,
+	deletedBy string,
+) (*Item, error) {
+	where, args := postgresVersionFilter(tenant, id, version, false, 3)
+	row := pstore.querier(ctx).QueryRowContext(
+		ctx,
+		`UPDATE items SET deleted_at = $1, deleted_by = $2, updated_at = $1, version = version + 1 `+where+` RETURNING `+postgresItemColumns,
+		append([]any{deletedAt, deletedBy}, args...)...,
+	)
+
+	return pstore.scanModified(ctx, row, tenant, id, false, "could not delete the item")
+}
+
+func (pstore *postgresItemStore) RestoreItem(ctx context.Context, tenant string, id int, version int64, restoredAt time.Time) (*Item, error) {
+	where, args := postgresVersionFilter(tenant, id, version, true, 2)
+	row := pstore.querier(ctx).QueryRowContext(
+		ctx,
+		`UPDATE items SET deleted_at = NULL, deleted_by = '', updated_at = $1, version = version + 1 `+where+` RETURNING `+postgresItemColumns,
+		append([]any{restoredAt}, args...)...,
+	)
+
+	return pstore.scanModified(ctx, row, tenant, id, true, "could not restore the item")
+}
+
+// PurgeItems hard deletes at most limit items of all the tenants, which were deleted before the
+// time provided
+func (pstore *postgresItemStore) PurgeItems(ctx context.Context, deletedBefore time.Time, limit int) (int64, error) {
+	result, err := pstore.querier(ctx).ExecContext(
+		ctx,
+		`DELETE FROM items WHERE (tenant, id) IN (SELECT tenant, id FROM items WHERE deleted_at < $1 LIMIT $2)`,
+		deletedBefore, limit,
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not purge items")
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.Wrap(err, "could not purge items")
+	}
+
+	return purged, nil
+}
+
+func (pstore *postgresItemStore) scanModified(
+	ctx context.Context,
+	row *sql.Row,
+	tenant string,
+	id int,
+	deleted bool,
+	failMsg string,
+) (*Item, error) {
+	item, err := scanItem(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			stored, serr := pstore.Item(ctx, tenant, id)
+			return nil, casError(stored, serr, id, deleted)
+		}
+		return nil, errors.Wrap(err, failMsg)
+	}
+
+	return item, nil
+}
+
+func (pstore *postgresItemStore) InsertOutbox(ctx context.Context, record OutboxRecord) error {
+	event, err := json.Marshal(record.Event)
+	if err != nil {
+		return errors.Wrap(err, "json marshal failed")
+	}
+
+	_, err = pstore.querier(ctx).ExecContext(
+		ctx,
+		`INSERT INTO item_outbox (id, event, created_at) VALUES ($1, $2, $3)`,
+		record.ID, event, record.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "could not save the outbox record")
+	}
+
+	return nil
+}
+
+func (pstore *postgresItemStore) InsertHistory(ctx context.Context, entries []HistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	const columns = 8
+	values := make([]string, 0, len(entries))
+	args := make([]any, 0, len(entries)*columns)
+	for idx, entry := range entries {
+		changes, err := json.Marshal(entry.Changes)
+		if err != nil {
+			return errors.Wrap(err, "json marshal failed")
+		}
+		values = append(values, "("+postgresPlaceholders(idx*columns+1, columns)+")")
+		args = append(args, entry.ID, entry.Tenant, entry.ItemID, entry.Action, entry.Version, entry.Actor, entry.At, changes)
+	}
+
+	_, err := pstore.querier(ctx).ExecContext(
+		ctx,
+		`INSERT INTO item_history (id, tenant, item_id, action, version, actor, at, changes) VALUES `+strings.Join(values, ", "),
+		args...,
+	)
+	if err != nil {
+		return errors.Wrap(err, "could not save the item history")
+	}
+
+	return nil
+}
+
+func (pstore *postgresItemStore) ListHistory(ctx context.Context, tenant string, itemID int, query HistoryQuery) ([]HistoryEntry, error) {
+	where := "WHERE tenant = $1 AND item_id = $2"
+	args := []any{tenant, itemID}
+	if query.Cursor != "" {
+		args = append(args, query.Cursor)
+		where += " AND id < $3"
+	}
+	args = append(args, query.Limit)
+
+	rows, err := pstore.querier(ctx).QueryContext(
+		ctx,
+		fmt.Sprintf(
+			`SELECT id, tenant, item_id, action, version, actor, at, changes FROM item_history %s ORDER BY id DESC LIMIT $%d`,
+			where, len(args),
+		),
+		args...,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch the item history")
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	list := make([]HistoryEntry, 0, query.Limit)
+	for rows.Next() {
+		entry := HistoryEntry{}
+		changes := []byte{}
+		err = rows.Scan(&entry.ID, &entry.Tenant, &entry.ItemID, &entry.Action, &entry.Version, &entry.Actor, &entry.At, &changes)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not fetch the item history")
+		}
+		err = json.Unmarshal(changes, &entry.Changes)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid changes in item history %s", entry.ID)
+		}
+		entry.At = entry.At.UTC()
+		list = append(list, entry)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch the item history")
+	}
+
+	return list, nil
+}
+
+func (pstore *postgresItemStore) PendingOutbox(ctx context.Context, limit int) ([]OutboxRecord, error) {
+	rows, err := pstore.querier(ctx).QueryContext(
+		ctx,
+		`SELECT id, event, created_at FROM item_outbox WHERE sent_at IS NULL ORDER BY id LIMIT $1`,
+		limit,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch pending outbox records")
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	list := make([]OutboxRecord, 0, limit)
+	for rows.Next() {
+		record := OutboxRecord{}
+		event := []byte{}
+		err = rows.Scan(&record.ID, &event, &record.CreatedAt)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not fetch pending outbox records")
+		}
+		err = json.Unmarshal(event, &record.Event)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid event in outbox record %s", record.ID)
+		}
+		list = append(list, record)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch pending outbox records")
+	}
+
+	return list, nil
+}
+
+func (pstore *postgresItemStore) MarkOutboxSent(ctx context.Context, ids []string, sentAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	args := make([]any, 0, len(ids)+1)
+	args = append(args, sentAt)
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	_, err := pstore.querier(ctx).ExecContext(
+		ctx,
+		`UPDATE item_outbox SET sent_at = $1 WHERE id IN (`+postgresPlaceholders(2, len(ids))+`)`,
+		args...,
+	)
+	if err != nil {
+		return errors.Wrap(err, "could not mark outbox records as sent")
+	}
+
+	return nil
+}
+
+func (pstore *postgresItemStore) OutboxStats(ctx context.Context) (*OutboxStats, error) {
+	stats := &OutboxStats{}
+	oldest := sql.NullTime{}
+	err := pstore.querier(ctx).QueryRowContext(
+		ctx,
+		`SELECT COUNT(*), MIN(created_at) FROM item_outbox WHERE sent_at IS NULL`,
+	).Scan(&stats.Pending, &oldest)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch outbox stats")
+	}
+	stats.OldestCreatedAt = oldest.Time
+
+	return stats, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanItem(row rowScanner) (*Item, error) {
+	item := new(Item)
+	deletedAt := sql.NullTime{}
+	tags, labels, attributes := []byte{}, []byte{}, []byte{}
+	err := row.Scan(
+		&item.Tenant, &item.ID, &item.Name, &item.Version, &item.CreatedAt, &item.UpdatedAt,
+		&tags, &labels, &attributes,
+		&deletedAt, &item.DeletedBy,
+	)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // wrapped by the callers, which also check for sql.ErrNoRows
+	}
+
+	metadata := []struct {
+		jbytes []byte
+		target any
+	}{
+		{jbytes: tags, target: &item.Tags},
+		{jbytes: labels, target: &item.Labels},
+		{jbytes: attributes, target: &item.Attributes},
+	}
+	for _, field := range metadata {
+		err = json.Unmarshal(field.jbytes, field.target)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid metadata of item %d", item.ID)
+		}
+	}
+	// empty ones are nil, same as the other stores
+	if len(item.Tags) == 0 {
+		item.Tags = nil
+	}
+	if len(item.Labels) == 0 {
+		item.Labels = nil
+	}
+	if len(item.Attributes) == 0 {
+		item.Attributes = nil
+	}
+	item.CreatedAt = item.CreatedAt.UTC()
+	item.UpdatedAt = item.UpdatedAt.UTC()
+	if deletedAt.Valid {
+		deleted := deletedAt.Time.UTC()
+		item.DeletedAt = &deleted
+	}
+
+	return item, nil
+}
+
+func isUniqueViolation(err error) bool {
+	pgErr := new(pgconn.PgError)
+	return errors.As(err, &pgErr) && pgErr.Code == postgresUniqueViolation
+}
+
+func postgresSortColumn(field SortField) string {
+	switch field {
+	case SortByName:
+		return "name"
+	case SortByCreatedAt:
+		return "created_at"
+	case SortByID:
+	}
+	return "id"
+}
+
+// postgresListFilter returns the WHERE clause and its arguments, it always filters by the tenant
+func postgresListFilter(tenant string, query *ListQuery) (string, []any) {
+	conditions := []string{}
+	args := []any{}
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions = append(conditions, "tenant = "+arg(tenant))
+
+	if !query.IncludeDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+
+	if query.NamePrefix != "" {
+		conditions = append(conditions, fmt.Sprintf(`name LIKE %s ESCAPE '\'`, arg(escapeLike(query.NamePrefix)+"%")))
+	}
+	if query.NameContains != "" {
+		conditions = append(conditions, fmt.Sprintf(`name LIKE %s ESCAPE '\'`, arg("%"+escapeLike(query.NameContains)+"%")))
+	}
+	if query.IDFrom > 0 {
+		conditions = append(conditions, "id >= "+arg(query.IDFrom))
+	}
+	if query.IDTo > 0 {
+		conditions = append(conditions, "id <= "+arg(query.IDTo))
+	}
+	if len(query.Tags) > 0 {
+		tags, _ := json.Marshal(query.Tags)
+		conditions = append(conditions, "tags @> "+arg(tags))
+	}
+	if len(query.Labels) > 0 {
+		labels, _ := json.Marshal(query.Labels)
+		conditions = append(conditions, "labels @> "+arg(labels))
+	}
+
+	if after := query.after; after != nil {
+		operator := ">"
+		if query.Descending {
+			operator = "<"
+		}
+		switch query.SortBy {
+		case SortByName:
+			conditions = append(conditions, fmt.Sprintf("(name, id) %s (%s, %s)", operator, arg(after.Name), arg(after.ID)))
+		case SortByCreatedAt:
+			conditions = append(conditions, fmt.Sprintf("(created_at, id) %s (%s, %s)", operator, arg(after.CreatedAt), arg(after.ID)))
+		case SortByID:
+			conditions = append(conditions, fmt.Sprintf("id %s %s", operator, arg(after.ID)))
+		}
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// postgresVersionFilter returns the WHERE clause matching the tenant & ID, the expected deleted state
+// and the version if > 0. The placeholders start from the position provided.
+func postgresVersionFilter(tenant string, id int, version int64, deleted bool, position int) (string, []any) {
+	where := fmt.Sprintf("WHERE tenant = $%d AND id = $%d AND deleted_at IS NULL", position, position+1)
+	if deleted {
+		where = fmt.Sprintf("WHERE tenant = $%d AND id = $%d AND deleted_at IS NOT NULL", position, position+1)
+	}
+	if version > 0 {
+		return fmt.Sprintf("%s AND version = $%d", where, position+2), []any{tenant, id, version}
+	}
+	return where, []any{tenant, id}
+}
+
+// postgresMetadata returns the tags, labels & attributes as JSON arguments, nil ones are stored
+// as an empty array/object.
+func postgresMetadata(tags []string, labels map[string]string, attributes map[string]Attribute) ([]any, error) {
+	if tags == nil {
+		tags = []string{}
+	}
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	if attributes == nil {
+		attributes = map[string]Attribute{}
+	}
+
+	metadata := make([]any, 0, 3)
+	for _, value := range []any{tags, labels, attributes} {
+		jbytes, err := json.Marshal(value)
+		if err != nil {
+			return nil, errors.Wrap(err, "json marshal failed")
+		}
+		metadata = append(metadata, jbytes)
+	}
+
+	return metadata, nil
+}
+
+// postgresPlaceholders returns n comma separated placeholders, starting from the position provided
+func postgresPlaceholders(position, n int) string {
+	placeholders := make([]string, 0, n)
+	for i := range n {
+		placeholders = append(placeholders, fmt.Sprintf("$%d", position+i))
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,287 @@
+package item
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/naughtygopher/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresStore(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+	ctx := t.Context()
+
+	db, mock, err := sqlmock.New()
+	requirer.NoError(err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	pstore, err := NewPostgresPersistentStore(db)
+	requirer.NoError(err)
+
+	now := time.Date(2024, 10, 1, 10, 0, 0, 0, time.UTC)
+	itemRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{
+			"tenant", "id", "name", "version", "created_at", "updated_at", "tags", "labels", "attributes", "deleted_at", "deleted_by",
+		})
+	}
+
+	t.Run("migrations are applied in order", func(_ *testing.T) {
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS item_schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT COALESCE").WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+		mock.ExpectBegin()
+		mock.ExpectExec("CREATE TABLE item_outbox").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO item_schema_migrations").
+			WithArgs(2, "create_item_outbox").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectExec("ALTER TABLE items").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO item_schema_migrations").
+			WithArgs(3, "soft_delete_items").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectExec("CREATE TABLE item_history").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO item_schema_migrations").
+			WithArgs(4, "create_item_history").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectExec("ALTER TABLE items").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO item_schema_migrations").
+			WithArgs(5, "add_item_metadata").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectExec("ALTER TABLE items").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO item_schema_migrations").
+			WithArgs(6, "add_item_tenant").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectExec("CREATE TABLE item_idempotency").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO item_schema_migrations").
+			WithArgs(7, "create_item_idempotency").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		versions, merr := MigratePostgres(ctx, db)
+		requirer.NoError(merr)
+		asserter.Equal([]int{2, 3, 4, 5, 6, 7}, versions)
+		requirer.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("duplicates are rejected", func(_ *testing.T) {
+		mock.ExpectExec("INSERT INTO items").
+			WithArgs(DefaultTenant, 1, "Box", 1, now, now, []byte("[]"), []byte("{}"), []byte("{}")).
+			WillReturnError(&pgconn.PgError{Code: postgresUniqueViolation})
+
+		_, ierr := pstore.InsertItem(ctx, DefaultTenant, Item{ID: 1, Name: "Box", Version: 1, CreatedAt: now, UpdatedAt: now})
+		requirer.ErrorIs(ierr, ErrDuplicateItem)
+		requirer.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("bulk insert skips duplicates", func(_ *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(
+			`INSERT INTO items (tenant, id, name, version, created_at, updated_at, tags, labels, attributes) VALUES `+
+				`($1, $2, $3, $4, $5, $6, $7, $8, $9), ($10, $11, $12, $13, $14, $15, $16, $17, $18) `+
+				`ON CONFLICT (tenant, id) DO NOTHING RETURNING id`,
+		)).
+			WithArgs(
+				"acme", 1, "Box", 1, now, now, []byte("[]"), []byte("{}"), []byte("{}"),
+				"acme", 2, "Bin", 1, now, now, []byte(`["new"]`), []byte("{}"), []byte("{}"),
+			).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+
+		itemErrs, ierr := pstore.InsertItems(ctx, "acme", []Item{
+			{ID: 1, Name: "Box", Version: 1, CreatedAt: now, UpdatedAt: now},
+			{ID: 2, Name: "Bin", Version: 1, CreatedAt: now, UpdatedAt: now, Tags: []string{"new"}},
+		})
+		requirer.NoError(ierr)
+		requirer.Len(itemErrs, 2)
+		asserter.ErrorIs(itemErrs[0], ErrDuplicateItem)
+		asserter.NoError(itemErrs[1])
+		requirer.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("missing item is not found", func(_ *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta("FROM items WHERE tenant = $1 AND id = $2")).WithArgs("acme", 2).WillReturnRows(itemRows())
+
+		_, gerr := pstore.Item(ctx, "acme", 2)
+		requirer.ErrorIs(gerr, ErrNotFound)
+		requirer.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("list filters, sorts and continues after the cursor", func(_ *testing.T) {
+		query := ListQuery{
+			Limit:      2,
+			SortBy:     SortByName,
+			Descending: true,
+			NamePrefix: "a_b",
+			IDFrom:     10,
+			Tags:       []string{"fragile"},
+			Labels:     map[string]string{"color": "red"},
+			after:      &pageCursor{SortBy: SortByName, ID: 12, Name: "a_bc"},
+		}
+		mock.ExpectQuery(regexp.QuoteMeta(
+			`FROM items WHERE tenant = $1 AND deleted_at IS NULL AND name LIKE $2 ESCAPE '\' AND id >= $3 AND tags @> $4 `+
+				`AND labels @> $5 AND (name, id) < ($6, $7) ORDER BY name DESC, id DESC LIMIT $8`,
+		)).
+			WithArgs(DefaultTenant, `a\_b%`, 10, []byte(`["fragile"]`), []byte(`{"color":"red"}`), "a_bc", 12, 2).
+			WillReturnRows(
+				itemRows().
+					AddRow(DefaultTenant, 11, "a_bb", 1, now, now, "[]", "{}", "{}", nil, "").
+					AddRow(DefaultTenant, 10, "a_ba", 3, now, now, "[]", "{}", "{}", nil, ""),
+			)
+
+		list, lerr := pstore.ListItems(ctx, DefaultTenant, query)
+		requirer.NoError(lerr)
+		requirer.Len(list, 2)
+		asserter.Equal(Item{Tenant: DefaultTenant, ID: 11, Name: "a_bb", Version: 1, CreatedAt: now, UpdatedAt: now}, list[0])
+		asserter.Equal(int64(3), list[1].Version)
+		requirer.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("modifications are compare-and-swap", func(_ *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(
+			`UPDATE items SET name = $1, tags = $2, labels = $3, attributes = $4, updated_at = $5, version = version + 1 `+
+				`WHERE tenant = $6 AND id = $7 AND deleted_at IS NULL AND version = $8`,
+		)).
+			WithArgs("Crate", []byte("[]"), []byte(`{"color":"red"}`), []byte("{}"), now, DefaultTenant, 3, int64(1)).
+			WillReturnRows(itemRows())
+		mock.ExpectQuery("SELECT .+ FROM items WHERE tenant = ").
+			WithArgs(DefaultTenant, 3).
+			WillReturnRows(itemRows().AddRow(DefaultTenant, 3, "Bin", 2, now, now, "[]", "{}", "{}", nil, ""))
+
+		_, uerr := pstore.UpdateItem(ctx, DefaultTenant, Item{ID: 3, Name: "Crate", Version: 1, UpdatedAt: now, Labels: map[string]string{"color": "red"}})
+		requirer.ErrorIs(uerr, ErrVersionConflict)
+
+		name := "Crate"
+		mock.ExpectQuery(regexp.QuoteMeta(`UPDATE items SET name = COALESCE($1, name), tags = COALESCE($2, tags)`)).
+			WithArgs(&name, nil, nil, []byte(`{"weight":1.5}`), now, DefaultTenant, 3, int64(2)).
+			WillReturnRows(itemRows().AddRow(DefaultTenant, 3, "Crate", 3, now, now, "[]", "{}", `{"weight":1.5}`, nil, ""))
+
+		patched, perr := pstore.PatchItem(ctx, DefaultTenant, 3, Patch{
+			Name:       &name,
+			Attributes: map[string]Attribute{"weight": NumberAttribute(1.5)},
+			Version:    2,
+		}, now)
+		requirer.NoError(perr)
+		asserter.Equal(int64(3), patched.Version)
+		asserter.Nil(patched.Tags)
+		asserter.Equal(map[string]Attribute{"weight": NumberAttribute(1.5)}, patched.Attributes)
+
+		mock.ExpectQuery(regexp.QuoteMeta(`UPDATE items SET deleted_at = $1, deleted_by = $2, updated_at = $1, version = version + 1 WHERE tenant = $3 AND id = $4 AND deleted_at IS NULL`)).
+			WithArgs(now, "ops", DefaultTenant, 4).
+			WillReturnRows(itemRows())
+		mock.ExpectQuery("SELECT .+ FROM items WHERE tenant = ").
+			WithArgs(DefaultTenant, 4).
+			WillReturnRows(itemRows().AddRow(DefaultTenant, 4, "Pan", 2, now, now, "[]", "{}", "{}", now, "ops"))
+		_, derr := pstore.DeleteItem(ctx, DefaultTenant, 4, 0, now, "ops")
+		requirer.ErrorIs(derr, ErrNotFound)
+
+		mock.ExpectQuery(regexp.QuoteMeta(`UPDATE items SET deleted_at = NULL, deleted_by = '', updated_at = $1, version = version + 1 WHERE tenant = $2 AND id = $3 AND deleted_at IS NOT NULL`)).
+			WithArgs(now, DefaultTenant, 4).
+			WillReturnRows(itemRows().AddRow(DefaultTenant, 4, "Pan", 3, now, now, "[]", "{}", "{}", nil, ""))
+		restored, rerr := pstore.RestoreItem(ctx, DefaultTenant, 4, 0, now)
+		requirer.NoError(rerr)
+		asserter.Nil(restored.DeletedAt)
+		asserter.Equal(int64(3), restored.Version)
+
+		requirer.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("history is appended and listed newest first", func(_ *testing.T) {
+		entries := []HistoryEntry{
+			{ID: "01J9ZV0000000000000000000A", Tenant: DefaultTenant, ItemID: 4, Action: HistoryCreated, Version: 1, Actor: "ops", At: now},
+			{
+				ID: "01J9ZV0000000000000000000B", Tenant: DefaultTenant, ItemID: 4, Action: HistoryUpdated, Version: 2, Actor: "ops", At: now,
+				Changes: []FieldChange{{Field: "name", From: "Pan", To: "Pot"}},
+			},
+		}
+		mock.ExpectExec(regexp.QuoteMeta(
+			`INSERT INTO item_history (id, tenant, item_id, action, version, actor, at, changes) VALUES ` +
+				`($1, $2, $3, $4, $5, $6, $7, $8), ($9, $10, $11, $12, $13, $14, $15, $16)`,
+		)).WillReturnResult(sqlmock.NewResult(0, 2))
+		requirer.NoError(pstore.InsertHistory(ctx, entries))
+
+		mock.ExpectQuery(regexp.QuoteMeta(
+			`SELECT id, tenant, item_id, action, version, actor, at, changes FROM item_history `+
+				`WHERE tenant = $1 AND item_id = $2 AND id < $3 ORDER BY id DESC LIMIT $4`,
+		)).
+			WithArgs(DefaultTenant, 4, "01J9ZV0000000000000000000C", 10).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "tenant", "item_id", "action", "version", "actor", "at", "changes"}).
+				AddRow(entries[1].ID, DefaultTenant, 4, "updated", 2, "ops", now, []byte(`[{"field":"name","from":"Pan","to":"Pot"}]`)))
+
+		list, lerr := pstore.ListHistory(ctx, DefaultTenant, 4, HistoryQuery{Limit: 10, Cursor: "01J9ZV0000000000000000000C"})
+		requirer.NoError(lerr)
+		asserter.Equal(entries[1:], list)
+		requirer.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("deleted items are purged in batches", func(_ *testing.T) {
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM items WHERE (tenant, id) IN (SELECT tenant, id FROM items WHERE deleted_at < $1 LIMIT $2)`)).
+			WithArgs(now, 10).
+			WillReturnResult(sqlmock.NewResult(0, 7))
+
+		purged, perr := pstore.PurgeItems(ctx, now, 10)
+		requirer.NoError(perr)
+		asserter.Equal(int64(7), purged)
+		requirer.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed transactions are rolled back", func(_ *testing.T) {
+		errAbort := errors.New("abort")
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO items").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO item_outbox").
+			WithArgs("1", sqlmock.AnyArg(), now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectRollback()
+
+		terr := pstore.Transaction(ctx, func(tctx context.Context) error {
+			_, ierr := pstore.InsertItem(tctx, DefaultTenant, Item{ID: 100, Name: "Tray"})
+			requirer.NoError(ierr)
+			requirer.NoError(pstore.InsertOutbox(tctx, OutboxRecord{ID: "1", CreatedAt: now}))
+			return errAbort
+		})
+		requirer.ErrorIs(terr, errAbort)
+		requirer.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("outbox records are relayed", func(_ *testing.T) {
+		mock.ExpectQuery("SELECT id, event, created_at FROM item_outbox WHERE sent_at IS NULL").
+			WithArgs(10).
+			WillReturnRows(
+				sqlmock.NewRows([]string{"id", "event", "created_at"}).
+					AddRow("1", []byte(`{"id":"1","type":"item.created","payload":{"id":5}}`), now),
+			)
+		records, perr := pstore.PendingOutbox(ctx, 10)
+		requirer.NoError(perr)
+		requirer.Len(records, 1)
+		asserter.Equal(EventItemCreated, records[0].Event.Type)
+		asserter.Equal(5, records[0].Event.Payload.ID)
+
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE item_outbox SET sent_at = $1 WHERE id IN ($2, $3)`)).
+			WithArgs(now, "1", "2").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		requirer.NoError(pstore.MarkOutboxSent(ctx, []string{"1", "2"}, now))
+
+		mock.ExpectQuery("SELECT COUNT").WillReturnRows(sqlmock.NewRows([]string{"count", "min"}).AddRow(0, nil))
+		stats, serr := pstore.OutboxStats(ctx)
+		requirer.NoError(serr)
+		asserter.Equal(int64(0), stats.Pending)
+		asserter.True(stats.OldestCreatedAt.IsZero())
+
+		requirer.NoError(mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,259 @@
+package item
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestMongoStore(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("duplicate key errors are mapped to duplicate item", func(mt *mtest.T) {
+		requirer := require.New(mt)
+		istore, err := NewMongoPersistentStore(mt.DB)
+		requirer.NoError(err)
+
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   0,
+			Code:    11000,
+			Message: "E11000 duplicate key error collection: items index: id_unique dup key: { id: 1 }",
+		}))
+		_, err = istore.InsertItem(mt.Context(), DefaultTenant, Item{ID: 1, Name: "Box"})
+		requirer.ErrorIs(err, ErrDuplicateItem)
+	})
+
+	mt.Run("bulk insert reports duplicates per item", func(mt *mtest.T) {
+		requirer := require.New(mt)
+		asserter := assert.New(mt)
+		istore, err := NewMongoPersistentStore(mt.DB)
+		requirer.NoError(err)
+
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   1,
+			Code:    11000,
+			Message: "E11000 duplicate key error collection: items index: id_unique dup key: { id: 2 }",
+		}))
+		itemErrs, err := istore.InsertItems(mt.Context(), "acme", []Item{{ID: 1}, {ID: 2}, {ID: 3}})
+		requirer.NoError(err)
+		requirer.Len(itemErrs, 3)
+		asserter.NoError(itemErrs[0])
+		asserter.ErrorIs(itemErrs[1], ErrDuplicateItem)
+		asserter.NoError(itemErrs[2])
+
+		started := mt.GetStartedEvent()
+		requirer.NotNil(started)
+		asserter.False(started.Command.Lookup("ordered").Boolean())
+		asserter.Equal("acme", started.Command.Lookup("documents", "0", "tenant").StringValue())
+	})
+
+	mt.Run("items are looked up and modified within the tenant", func(mt *mtest.T) {
+		requirer := require.New(mt)
+		asserter := assert.New(mt)
+		istore, err := NewMongoPersistentStore(mt.DB)
+		requirer.NoError(err)
+
+		namespace := mt.DB.Name() + ".items"
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, namespace, mtest.FirstBatch),
+			mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil}),
+			mtest.CreateCursorResponse(0, namespace, mtest.FirstBatch),
+		)
+		_, err = istore.Item(mt.Context(), "acme", 1)
+		requirer.ErrorIs(err, ErrNotFound)
+		started := mt.GetStartedEvent()
+		requirer.NotNil(started)
+		asserter.Equal("acme", started.Command.Lookup("filter", "tenant", "$eq").StringValue())
+
+		_, err = istore.DeleteItem(mt.Context(), "acme", 1, 0, time.Now(), "ops")
+		requirer.ErrorIs(err, ErrNotFound)
+		started = mt.GetStartedEvent()
+		requirer.NotNil(started)
+		asserter.Equal("acme", started.Command.Lookup("query", "tenant", "$eq").StringValue())
+	})
+
+	mt.Run("history is listed newest first, before the cursor", func(mt *mtest.T) {
+		requirer := require.New(mt)
+		asserter := assert.New(mt)
+		istore, err := NewMongoPersistentStore(mt.DB)
+		requirer.NoError(err)
+
+		namespace := mt.DB.Name() + ".item_history"
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, namespace, mtest.FirstBatch,
+			bson.D{
+				{Key: "_id", Value: "01J9ZV0000000000000000000A"},
+				{Key: "itemId", Value: 4},
+				{Key: "action", Value: "created"},
+				{Key: "version", Value: 1},
+				{Key: "changes", Value: bson.A{bson.D{{Key: "field", Value: "name"}, {Key: "to", Value: "Pan"}}}},
+			},
+		))
+		list, err := istore.ListHistory(mt.Context(), "acme", 4, HistoryQuery{Limit: 2, Cursor: "01J9ZV0000000000000000000B"})
+		requirer.NoError(err)
+		requirer.Len(list, 1)
+		asserter.Equal(HistoryCreated, list[0].Action)
+		asserter.Equal([]FieldChange{{Field: "name", To: "Pan"}}, list[0].Changes)
+
+		started := mt.GetStartedEvent()
+		requirer.NotNil(started)
+		filter := started.Command.Lookup("filter").Document()
+		asserter.Equal("acme", filter.Lookup("tenant").StringValue())
+		asserter.Equal(int32(4), filter.Lookup("itemId").Int32())
+		asserter.Equal("01J9ZV0000000000000000000B", filter.Lookup("_id", "$lt").StringValue())
+		asserter.Equal(int32(-1), started.Command.Lookup("sort", "_id").Int32())
+	})
+
+	mt.Run("list filters by tags and labels", func(mt *mtest.T) {
+		requirer := require.New(mt)
+		asserter := assert.New(mt)
+		istore, err := NewMongoPersistentStore(mt.DB)
+		requirer.NoError(err)
+
+		namespace := mt.DB.Name() + ".items"
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, namespace, mtest.FirstBatch,
+			bson.D{
+				{Key: "id", Value: 1},
+				{Key: "tags", Value: bson.A{"bulk", "fragile"}},
+				{Key: "labels", Value: bson.D{{Key: "color", Value: "red"}}},
+				{Key: "attributes", Value: bson.D{{Key: "weight", Value: int32(2)}, {Key: "fragile", Value: true}}},
+			},
+		))
+		list, err := istore.ListItems(mt.Context(), "acme", ListQuery{
+			Limit:  1,
+			Tags:   []string{"fragile"},
+			Labels: map[string]string{"color": "red", "size": "xl"},
+		})
+		requirer.NoError(err)
+		requirer.Len(list, 1)
+		asserter.Equal([]string{"bulk", "fragile"}, list[0].Tags)
+		asserter.Equal(map[string]Attribute{"weight": NumberAttribute(2), "fragile": BoolAttribute(true)}, list[0].Attributes)
+
+		started := mt.GetStartedEvent()
+		requirer.NotNil(started)
+		conditions, _ := started.Command.Lookup("filter", "$and").Array().Values()
+		requirer.Len(conditions, 5)
+		asserter.Equal("acme", conditions[0].Document().Lookup("tenant", "$eq").StringValue())
+		asserter.Equal("fragile", conditions[2].Document().Lookup("tags", "$all", "0").StringValue())
+		asserter.Equal("red", conditions[3].Document().Lookup("labels.color", "$eq").StringValue())
+		asserter.Equal("xl", conditions[4].Document().Lookup("labels.size", "$eq").StringValue())
+	})
+
+	mt.Run("indexes are reconciled", func(mt *mtest.T) {
+		requirer := require.New(mt)
+		asserter := assert.New(mt)
+		istore, err := NewMongoPersistentStore(mt.DB)
+		requirer.NoError(err)
+
+		namespace := mt.DB.Name() + "." + mt.Coll.Name()
+		mt.AddMockResponses(
+			// the default tenant is set on items & history
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateCursorResponse(0, namespace, mtest.FirstBatch,
+				bson.D{{Key: "v", Value: 2}, {Key: "name", Value: "_id_"}, {Key: "key", Value: bson.D{{Key: "_id", Value: 1}}}},
+				// outdated name, but the same keys, returned as double
+				bson.D{
+					{Key: "v", Value: 2},
+					{Key: "name", Value: "id_unique"},
+					{Key: "key", Value: bson.D{{Key: "tenant", Value: 1.0}, {Key: "id", Value: 1.0}}},
+					{Key: "unique", Value: true},
+				},
+				// outdated
+				bson.D{{Key: "v", Value: 2}, {Key: "name", Value: "name_id"}, {Key: "key", Value: bson.D{{Key: "name", Value: 1}}}},
+				// up to date
+				bson.D{
+					{Key: "v", Value: 2},
+					{Key: "name", Value: "tenant_createdAt_id"},
+					{Key: "key", Value: bson.D{{Key: "tenant", Value: 1}, {Key: "createdAt", Value: 1}, {Key: "id", Value: 1}}},
+				},
+			),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(),
+			// already dropped by another instance
+			mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 27, Name: "IndexNotFound", Message: "index not found with name [name_id]"}),
+			mtest.CreateCursorResponse(0, namespace, mtest.FirstBatch,
+				bson.D{{Key: "v", Value: 2}, {Key: "name", Value: "_id_"}, {Key: "key", Value: bson.D{{Key: "_id", Value: 1}}}},
+				bson.D{
+					{Key: "v", Value: 2},
+					{Key: "name", Value: "itemId_id"},
+					{Key: "key", Value: bson.D{{Key: "itemId", Value: 1}, {Key: "_id", Value: -1}}},
+				},
+			),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(),
+		)
+		requirer.NoError(istore.EnsureIndexes(mt.Context()))
+
+		started := []string{}
+		changed := []string{}
+		for event := mt.GetStartedEvent(); event != nil; event = mt.GetStartedEvent() {
+			started = append(started, event.CommandName)
+			switch event.CommandName {
+			case "createIndexes":
+				indexes, _ := event.Command.Lookup("indexes").Array().Values()
+				for _, index := range indexes {
+					changed = append(changed, "+"+index.Document().Lookup("name").StringValue())
+				}
+			case "dropIndexes":
+				changed = append(changed, "-"+event.Command.Lookup("index").StringValue())
+			}
+		}
+		asserter.Equal([]string{
+			"update", "update",
+			"listIndexes", "createIndexes", "createIndexes", "createIndexes", "createIndexes", "dropIndexes",
+			"listIndexes", "createIndexes", "dropIndexes",
+		}, started)
+		// the outdated indexes are dropped only after the new ones are created
+		asserter.Equal([]string{
+			"+tenant_name_id", "+deletedAt", "+tags", "+labels_wildcard", "-name_id",
+			"+tenant_itemId_id", "-itemId_id",
+		}, changed)
+	})
+
+	mt.Run("duplicates of unique indexes are reported", func(mt *mtest.T) {
+		requirer := require.New(mt)
+		asserter := assert.New(mt)
+		istore, err := NewMongoPersistentStore(mt.DB)
+		requirer.NoError(err)
+
+		namespace := mt.DB.Name() + "." + mt.Coll.Name()
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateCursorResponse(0, namespace, mtest.FirstBatch,
+				bson.D{{Key: "v", Value: 2}, {Key: "name", Value: "_id_"}, {Key: "key", Value: bson.D{{Key: "_id", Value: 1}}}},
+				bson.D{
+					{Key: "v", Value: 2},
+					{Key: "name", Value: "id_unique"},
+					{Key: "key", Value: bson.D{{Key: "id", Value: 1}}},
+					{Key: "unique", Value: true},
+				},
+			),
+			mtest.CreateCommandErrorResponse(mtest.CommandError{
+				Code:    11000,
+				Message: "E11000 duplicate key error collection: items index: tenant_id_unique dup key: { tenant: \"acme\", id: 1 }",
+			}),
+			mtest.CreateCursorResponse(0, namespace, mtest.FirstBatch,
+				bson.D{
+					{Key: "_id", Value: bson.D{{Key: "tenant", Value: "acme"}, {Key: "id", Value: 1}}},
+					{Key: "count", Value: 2},
+				},
+			),
+		)
+		err = istore.EnsureIndexes(mt.Context())
+		requirer.Error(err)
+		asserter.Contains(err.Error(), "could not create the unique items index tenant_id_unique")
+		asserter.Contains(err.Error(), "{tenant: acme, id: 1} x 2")
+
+		// the outdated unique index is not dropped
+		for event := mt.GetStartedEvent(); event != nil; event = mt.GetStartedEvent() {
+			asserter.NotEqual("dropIndexes", event.CommandName)
+		}
+	})
+}
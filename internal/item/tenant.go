@@ -0,0 +1,63 @@
+package item
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/naughtygopher/errors"
+)
+
+const (
+	// DefaultTenant is used when the caller does not specify a tenant, and for the items created
+	// before multi-tenancy
+	DefaultTenant = "default"
+	// KafkaHeaderTenant is the Kafka record header with the tenant, of both the published & consumed records
+	KafkaHeaderTenant = "tenant"
+)
+
+var (
+	ErrInvalidTenant = errors.Validation("tenant should be lower case alphanumeric, with '_' or '-', of at most 63 characters")
+
+	tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+)
+
+type tenantKey struct{}
+
+// WithTenant returns a context with the tenant, all the items accessed using the context are of the tenant
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// Tenant returns the tenant of the context, or DefaultTenant if there's none
+func Tenant(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	if tenant == "" {
+		return DefaultTenant
+	}
+	return tenant
+}
+
+// ValidTenant returns true if the tenant can be used, e.g. in store queries or as a label of metrics
+func ValidTenant(tenant string) bool {
+	return tenantPattern.MatchString(tenant)
+}
+
+// tenantOf returns the tenant of the context, after validating it. Since the tenant is provided by
+// the callers, it is validated before it's used in store queries or cache keys.
+func tenantOf(ctx context.Context) (string, error) {
+	tenant := Tenant(ctx)
+	if !ValidTenant(tenant) {
+		return "", errors.Wrapf(ErrInvalidTenant, ": '%s'", tenant)
+	}
+	return tenant, nil
+}
+
+// itemKey identifies an item across all the tenants
+type itemKey struct {
+	tenant string
+	id     int
+}
+
+func keyOf(it *Item) itemKey {
+	return itemKey{tenant: it.Tenant, id: it.ID}
+}
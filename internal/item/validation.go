@@ -0,0 +1,336 @@
+package item
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/naughtygopher/errors"
+)
+
+const (
+	DefaultNameMaxLength = 256
+	// DefaultNamePattern allows letters, marks, numbers, punctuation, symbols and spaces, i.e. no
+	// control characters (e.g. new lines or tabs)
+	DefaultNamePattern = `^[\p{L}\p{M}\p{N}\p{P}\p{S}\p{Zs}]*$`
+)
+
+// Codes of the field errors, identifying the rule violated
+const (
+	CodeRequired     = "required"
+	CodeInvalid      = "invalid"
+	CodeTooShort     = "too_short"
+	CodeTooLong      = "too_long"
+	CodeTooMany      = "too_many"
+	CodeInvalidChars = "invalid_chars"
+	CodeInvalidKey   = "invalid_key"
+	CodeInvalidType  = "invalid_type"
+	CodeReserved     = "reserved"
+	CodeOutOfRange   = "out_of_range"
+	CodeNotAllowed   = "not_allowed"
+)
+
+var ErrInvalidNamePattern = errors.Validation("invalid name pattern")
+
+// FieldError is a violation of a validation rule by a field. Field is the path of the field, as in
+// the JSON representation of the item. e.g. "name", "tags[1]" or "attributes.weight".
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// cause is the sentinel error of the violation (if any), so that it can be checked with errors.Is
+	cause error
+}
+
+// ValidationError has all the violations found by a validation, instead of just the first one.
+// It is returned wrapped as a validation error, use errors.As to get the violations.
+type ValidationError struct {
+	Violations []FieldError
+}
+
+func (ve *ValidationError) Error() string {
+	messages := make([]string, 0, len(ve.Violations))
+	for _, violation := range ve.Violations {
+		messages = append(messages, violation.Field+": "+violation.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the sentinel errors of the violations
+func (ve *ValidationError) Unwrap() []error {
+	causes := make([]error, 0, len(ve.Violations))
+	for _, violation := range ve.Violations {
+		if violation.cause != nil {
+			causes = append(causes, violation.cause)
+		}
+	}
+	return causes
+}
+
+type violations []FieldError
+
+func (vs *violations) add(cause error, field, code, format string, args ...any) {
+	*vs = append(*vs, FieldError{Field: field, Code: code, Message: fmt.Sprintf(format, args...), cause: cause})
+}
+
+// err returns nil if there are no violations
+func (vs violations) err(message string) error {
+	if len(vs) == 0 {
+		return nil
+	}
+	return errors.ValidationErr(&ValidationError{Violations: vs}, message)
+}
+
+// Rules are the validation rules of items, in addition to the Limits of tags, labels & attributes
+type Rules struct {
+	// NameMinLength & NameMaxLength are in characters, the name is optional if NameMinLength is 0
+	NameMinLength int
+	NameMaxLength int
+	// NamePattern is the regular expression which the name should match, i.e. its charset
+	NamePattern string
+	// ReservedNames can not be used as names of items, they're compared case insensitively
+	ReservedNames []string
+	// Attributes are the rules of specific attributes, by key
+	Attributes map[string]AttributeRule
+}
+
+// AttributeRule constrains an attribute, the constraints not applicable to its type are ignored
+type AttributeRule struct {
+	// Type if set, is the only type allowed for the attribute
+	Type     AttributeType `json:"type,omitempty"`
+	Required bool          `json:"required,omitempty"`
+	// Min & Max are the inclusive bounds of number attributes
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+	// MaxLength is the maximum length (in characters) of string attributes
+	MaxLength int `json:"maxLength,omitempty"`
+	// Enum is the list of values allowed for string attributes
+	Enum []string `json:"enum,omitempty"`
+}
+
+// Validator validates items, patches & list queries based on the limits & rules. It reports all
+// the violations at once.
+type Validator struct {
+	limits      Limits
+	rules       Rules
+	namePattern *regexp.Regexp
+	// reserved has the reserved names in lower case
+	reserved map[string]bool
+}
+
+func NewValidator(limits Limits, rules Rules) (*Validator, error) {
+	err := limits.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	if rules.NameMaxLength <= 0 {
+		rules.NameMaxLength = DefaultNameMaxLength
+	}
+	if rules.NamePattern == "" {
+		rules.NamePattern = DefaultNamePattern
+	}
+	namePattern, err := regexp.Compile(rules.NamePattern)
+	if err != nil {
+		return nil, errors.Wrapf(ErrInvalidNamePattern, ": %s", err.Error())
+	}
+
+	reserved := make(map[string]bool, len(rules.ReservedNames))
+	for _, name := range rules.ReservedNames {
+		reserved[strings.ToLower(name)] = true
+	}
+
+	return &Validator{
+		limits:      limits,
+		rules:       rules,
+		namePattern: namePattern,
+		reserved:    reserved,
+	}, nil
+}
+
+// defaultValidator is used when validating without any limits or rules configured
+var defaultValidator, _ = NewValidator(Limits{}, Rules{})
+
+func (v *Validator) Item(it *Item) error {
+	vs := violations{}
+	if it.ID <= 0 {
+		vs.add(ErrInvalidID, "id", CodeInvalid, "should be > 0")
+	}
+	v.checkName(it.Name, &vs)
+	v.checkTags(it.Tags, &vs)
+	v.checkLabels(it.Labels, &vs)
+	v.checkAttributes(it.Attributes, &vs)
+
+	return vs.err("item is invalid")
+}
+
+// NamedItem validates the name of the item named by the Namer, since the stored item should still be valid
+// when it's updated as is. requested is the name before it was named, which is already validated.
+func (v *Validator) NamedItem(requested string, it *Item) error {
+	vs := violations{}
+	length := utf8.RuneCountInString(it.Name)
+	if length > v.rules.NameMaxLength {
+		suffix := length - utf8.RuneCountInString(requested)
+		vs.add(
+			nil,
+			"name",
+			CodeTooLong,
+			"should be at most %d characters, since %d characters are added to it",
+			max(v.rules.NameMaxLength-suffix, 0),
+			suffix,
+		)
+	}
+
+	return vs.err("item is invalid")
+}
+
+// Patch validates only the fields which are patched
+func (v *Validator) Patch(pt *Patch) error {
+	vs := violations{}
+	if pt.Name != nil {
+		v.checkName(*pt.Name, &vs)
+	}
+	if pt.Tags != nil {
+		v.checkTags(pt.Tags, &vs)
+	}
+	if pt.Labels != nil {
+		v.checkLabels(pt.Labels, &vs)
+	}
+	if pt.Attributes != nil {
+		v.checkAttributes(pt.Attributes, &vs)
+	}
+
+	return vs.err("patch is invalid")
+}
+
+// ListQuery validates the tag & label filters of the query, since the label keys are used as
+// field names by the stores.
+func (v *Validator) ListQuery(query *ListQuery) error {
+	vs := violations{}
+	for idx, tag := range query.Tags {
+		if !v.validKey(tag) {
+			vs.add(ErrInvalidTags, fmt.Sprintf("tags[%d]", idx), CodeInvalidKey, "should match %s", v.limits.KeyPattern)
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(query.Labels)) {
+		if !v.validKey(key) {
+			vs.add(ErrInvalidLabels, "labels."+key, CodeInvalidKey, "key should match %s", v.limits.KeyPattern)
+		}
+	}
+
+	return vs.err("list query is invalid")
+}
+
+func (v *Validator) checkName(name string, vs *violations) {
+	length := utf8.RuneCountInString(name)
+	switch {
+	case length < v.rules.NameMinLength && length == 0:
+		vs.add(nil, "name", CodeRequired, "is required")
+	case length < v.rules.NameMinLength:
+		vs.add(nil, "name", CodeTooShort, "should be at least %d characters", v.rules.NameMinLength)
+	case length > v.rules.NameMaxLength:
+		vs.add(nil, "name", CodeTooLong, "should be at most %d characters", v.rules.NameMaxLength)
+	}
+
+	if !utf8.ValidString(name) || !v.namePattern.MatchString(name) {
+		vs.add(nil, "name", CodeInvalidChars, "should match %s", v.rules.NamePattern)
+	}
+	if v.reserved[strings.ToLower(name)] {
+		vs.add(nil, "name", CodeReserved, "'%s' is reserved", name)
+	}
+}
+
+func (v *Validator) validKey(key string) bool {
+	return v.limits.validKey(key)
+}
+
+func (v *Validator) checkTags(tags []string, vs *violations) {
+	if len(tags) > v.limits.MaxTags {
+		vs.add(ErrInvalidTags, "tags", CodeTooMany, "at most %d tags are allowed", v.limits.MaxTags)
+	}
+	for idx, tag := range tags {
+		if !v.validKey(tag) {
+			vs.add(ErrInvalidTags, fmt.Sprintf("tags[%d]", idx), CodeInvalidKey, "should match %s", v.limits.KeyPattern)
+		}
+	}
+}
+
+func (v *Validator) checkLabels(labels map[string]string, vs *violations) {
+	if len(labels) > v.limits.MaxLabels {
+		vs.add(ErrInvalidLabels, "labels", CodeTooMany, "at most %d labels are allowed", v.limits.MaxLabels)
+	}
+	// sorted, so that the violations are in a stable order
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		field := "labels." + key
+		if !v.validKey(key) {
+			vs.add(ErrInvalidLabels, field, CodeInvalidKey, "key should match %s", v.limits.KeyPattern)
+		}
+		if len(labels[key]) > v.limits.MaxValueLength {
+			vs.add(ErrInvalidLabels, field, CodeTooLong, "should be at most %d bytes", v.limits.MaxValueLength)
+		}
+	}
+}
+
+func (v *Validator) checkAttributes(attributes map[string]Attribute, vs *violations) {
+	if len(attributes) > v.limits.MaxAttributes {
+		vs.add(ErrInvalidAttributes, "attributes", CodeTooMany, "at most %d attributes are allowed", v.limits.MaxAttributes)
+	}
+	for _, key := range slices.Sorted(maps.Keys(attributes)) {
+		field := "attributes." + key
+		attr := attributes[key]
+		if !v.validKey(key) {
+			vs.add(ErrInvalidAttributes, field, CodeInvalidKey, "key should match %s", v.limits.KeyPattern)
+		}
+		if attr.Value() == nil {
+			vs.add(ErrInvalidAttributes, field, CodeInvalidType, "should be a string, number or boolean")
+			continue
+		}
+		if len(attr.StringValue) > v.limits.MaxValueLength {
+			vs.add(ErrInvalidAttributes, field, CodeTooLong, "should be at most %d bytes", v.limits.MaxValueLength)
+		}
+		if rule, ok := v.rules.Attributes[key]; ok {
+			checkAttributeRule(field, attr, &rule, vs)
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(v.rules.Attributes)) {
+		if _, ok := attributes[key]; !ok && v.rules.Attributes[key].Required {
+			vs.add(ErrInvalidAttributes, "attributes."+key, CodeRequired, "is required")
+		}
+	}
+}
+
+func checkAttributeRule(field string, attr Attribute, rule *AttributeRule, vs *violations) {
+	if rule.Type != "" && attr.Type != rule.Type {
+		vs.add(ErrInvalidAttributes, field, CodeInvalidType, "should be a %s", rule.Type)
+		return
+	}
+
+	switch attr.Type {
+	case AttributeNumber:
+		if (rule.Min != nil && attr.NumberValue < *rule.Min) || (rule.Max != nil && attr.NumberValue > *rule.Max) {
+			vs.add(ErrInvalidAttributes, field, CodeOutOfRange, "should be within %s", formatRange(rule.Min, rule.Max))
+		}
+	case AttributeString:
+		if rule.MaxLength > 0 && utf8.RuneCountInString(attr.StringValue) > rule.MaxLength {
+			vs.add(ErrInvalidAttributes, field, CodeTooLong, "should be at most %d characters", rule.MaxLength)
+		}
+		if len(rule.Enum) > 0 && !slices.Contains(rule.Enum, attr.StringValue) {
+			vs.add(ErrInvalidAttributes, field, CodeNotAllowed, "should be one of %s", strings.Join(rule.Enum, ", "))
+		}
+	case AttributeBool:
+	}
+}
+
+func formatRange(lower, upper *float64) string {
+	format := func(bound *float64, unbounded string) string {
+		if bound == nil {
+			return unbounded
+		}
+		return fmt.Sprintf("%g", *bound)
+	}
+	return fmt.Sprintf("[%s, %s]", format(lower, "-inf"), format(upper, "+inf"))
+}
@@ -0,0 +1,141 @@
+package item
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/naughtygopher/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidator(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+
+	minWeight, maxWeight := 0.0, 100.0
+	validator, err := NewValidator(Limits{MaxTags: 2}, Rules{
+		NameMinLength: 1,
+		NameMaxLength: 8,
+		ReservedNames: []string{"Admin"},
+		Attributes: map[string]AttributeRule{
+			"color":  {Type: AttributeString, Enum: []string{"red", "blue"}},
+			"weight": {Type: AttributeNumber, Min: &minWeight, Max: &maxWeight},
+			"sku":    {Required: true, MaxLength: 4},
+		},
+	})
+	requirer.NoError(err)
+
+	codes := func(verr error) map[string]string {
+		valErr := new(ValidationError)
+		requirer.ErrorAs(verr, &valErr)
+		fields := map[string]string{}
+		for _, violation := range valErr.Violations {
+			fields[violation.Field] = violation.Code
+		}
+		return fields
+	}
+
+	t.Run("valid item", func(_ *testing.T) {
+		asserter.NoError(validator.Item(&Item{
+			ID:         1,
+			Name:       "Box",
+			Attributes: map[string]Attribute{"sku": StringAttribute("B1"), "color": StringAttribute("red")},
+		}))
+	})
+
+	t.Run("all violations are reported at once", func(_ *testing.T) {
+		verr := validator.Item(&Item{
+			ID:   0,
+			Name: "Box\nCrate",
+			Tags: []string{"a", "B", "c"},
+			Attributes: map[string]Attribute{
+				"color":  StringAttribute("green"),
+				"weight": NumberAttribute(101),
+			},
+		})
+		requirer.Error(verr)
+		asserter.True(errors.Is(verr, ErrInvalidID))
+		asserter.True(errors.Is(verr, ErrInvalidTags))
+		asserter.True(errors.Is(verr, ErrInvalidAttributes))
+
+		status, _, _ := errors.HTTPStatusCodeMessage(verr)
+		asserter.Equal(422, status)
+		asserter.Equal(map[string]string{
+			"id":                CodeInvalid,
+			"name":              CodeInvalidChars,
+			"tags":              CodeTooMany,
+			"tags[1]":           CodeInvalidKey,
+			"attributes.color":  CodeNotAllowed,
+			"attributes.weight": CodeOutOfRange,
+			"attributes.sku":    CodeRequired,
+		}, codes(verr))
+	})
+
+	t.Run("name rules", func(_ *testing.T) {
+		sku := map[string]Attribute{"sku": StringAttribute("B1")}
+		asserter.Equal(map[string]string{"name": CodeRequired}, codes(validator.Item(&Item{ID: 1, Attributes: sku})))
+		asserter.Equal(
+			map[string]string{"name": CodeTooLong},
+			codes(validator.Item(&Item{ID: 1, Name: strings.Repeat("é", 9), Attributes: sku})),
+		)
+		asserter.Equal(map[string]string{"name": CodeReserved}, codes(validator.Item(&Item{ID: 1, Name: "ADMIN", Attributes: sku})))
+	})
+
+	t.Run("attribute types", func(_ *testing.T) {
+		verr := validator.Item(&Item{ID: 1, Name: "Box", Attributes: map[string]Attribute{
+			"sku":    StringAttribute("B12345"),
+			"weight": StringAttribute("heavy"),
+		}})
+		asserter.Equal(map[string]string{
+			"attributes.sku":    CodeTooLong,
+			"attributes.weight": CodeInvalidType,
+		}, codes(verr))
+	})
+
+	t.Run("patch validates only the fields provided", func(_ *testing.T) {
+		asserter.NoError(validator.Patch(&Patch{Labels: map[string]string{"color": "red"}}))
+
+		name := ""
+		asserter.Equal(map[string]string{"name": CodeRequired}, codes(validator.Patch(&Patch{Name: &name})))
+	})
+
+	t.Run("invalid name pattern", func(_ *testing.T) {
+		_, nerr := NewValidator(Limits{}, Rules{NamePattern: "("})
+		asserter.ErrorIs(nerr, ErrInvalidNamePattern)
+	})
+}
+
+func TestNamedItemValidation(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+	ctx := t.Context()
+
+	store, err := NewMemoryPersistentStore()
+	requirer.NoError(err)
+	svc, err := NewService(store, nil, nil, RandomSuffixNamer(), &Config{UseOutbox: true})
+	requirer.NoError(err)
+
+	t.Run("names too long once suffixed are rejected", func(_ *testing.T) {
+		_, cerr := svc.Create(ctx, Item{ID: 1, Name: strings.Repeat("a", 250)})
+		valErr := new(ValidationError)
+		requirer.ErrorAs(cerr, &valErr)
+		requirer.Len(valErr.Violations, 1)
+		asserter.Equal("name", valErr.Violations[0].Field)
+		asserter.Equal(CodeTooLong, valErr.Violations[0].Code)
+
+		results, cerr := svc.CreateMany(ctx, []Item{{ID: 2, Name: strings.Repeat("b", 250)}})
+		requirer.NoError(cerr)
+		asserter.Equal(CreateStatusInvalid, results[0].Status)
+		asserter.Equal(CodeTooLong, results[0].Violations[0].Code)
+	})
+
+	t.Run("the stored items can be updated as is", func(_ *testing.T) {
+		created, cerr := svc.Create(ctx, Item{ID: 3, Name: strings.Repeat("c", 200)})
+		requirer.NoError(cerr)
+		asserter.LessOrEqual(len(created.Name), DefaultNameMaxLength)
+
+		_, uerr := svc.Update(ctx, *created)
+		asserter.NoError(uerr)
+	})
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+
+	"github.com/naughtygopher/errors"
+)
+
+// apiKeyAuthenticator authenticates static API keys. Only the hashes of the keys are kept, and the
+// hash of the API key of a request is compared with all of them in constant time.
+type apiKeyAuthenticator struct {
+	hashes     [][]byte
+	principals []Principal
+}
+
+func newAPIKeyAuthenticator(keys []APIKey) (*apiKeyAuthenticator, error) {
+	ak := &apiKeyAuthenticator{
+		hashes:     make([][]byte, 0, len(keys)),
+		principals: make([]Principal, 0, len(keys)),
+	}
+
+	for i, key := range keys {
+		if strings.TrimSpace(key.Subject) == "" {
+			return nil, errors.Validationf("API key %d should have a subject", i)
+		}
+
+		var hash []byte
+		switch {
+		case key.Key != "" && key.SHA256 != "":
+			return nil, errors.Validationf("API key of '%s' should be either the key or its SHA-256, not both", key.Subject)
+		case key.Key != "":
+			sum := sha256.Sum256([]byte(key.Key))
+			hash = sum[:]
+		default:
+			decoded, err := hex.DecodeString(key.SHA256)
+			if err != nil || len(decoded) != sha256.Size {
+				return nil, errors.Validationf("API key of '%s' should have a hex encoded SHA-256", key.Subject)
+			}
+			hash = decoded
+		}
+
+		ak.hashes = append(ak.hashes, hash)
+		ak.principals = append(ak.principals, Principal{
+			Subject: key.Subject,
+			Method:  MethodAPIKey,
+			Roles:   key.Roles,
+			Scopes:  key.Scopes,
+			Tenants: key.Tenants,
+		})
+	}
+
+	return ak, nil
+}
+
+func (ak *apiKeyAuthenticator) authenticate(apiKey string) (*Principal, error) {
+	sum := sha256.Sum256([]byte(apiKey))
+
+	match := -1
+	for i, hash := range ak.hashes {
+		if subtle.ConstantTimeCompare(sum[:], hash) == 1 && match < 0 {
+			match = i
+		}
+	}
+	if match < 0 {
+		return nil, ErrInvalidAPIKey
+	}
+
+	principal := ak.principals[match]
+	return &principal, nil
+}
@@ -0,0 +1,166 @@
+// Package auth authenticates the callers of the APIs. Callers are authenticated with bearer JWTs,
+// verified using the keys of a JWKS (JSON Web Key Set), or with static API keys. The authenticated
+// caller is the Principal, which is set in the context of the request.
+package auth
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/naughtygopher/errors"
+)
+
+var (
+	// ErrNoCredentials is returned if the request has neither a bearer token nor an API key
+	ErrNoCredentials = errors.Unauthenticated("credentials are required, as a bearer token or an API key")
+	// ErrInvalidAPIKey is returned for API keys which are not configured
+	ErrInvalidAPIKey = errors.Unauthenticated("invalid API key")
+	// ErrTokensNotAccepted is returned for bearer tokens, if there's no JWKS to verify them with
+	ErrTokensNotAccepted = errors.Unauthenticated("bearer tokens are not accepted, use an API key")
+	// ErrAPIKeysNotAccepted is returned for API keys, if none are configured
+	ErrAPIKeysNotAccepted = errors.Unauthenticated("API keys are not accepted, use a bearer token")
+)
+
+// Methods of authentication of the principals
+const (
+	MethodJWT    = "jwt"
+	MethodAPIKey = "api_key"
+	// MethodService is of the principals of the app itself, e.g. the Kafka subscriber. They're
+	// configured, rather than authenticated.
+	MethodService = "service"
+)
+
+// Principal is the authenticated caller, i.e. a user or a service
+type Principal struct {
+	// Subject identifies the caller, it is the "sub" claim of JWTs or the subject of the API key
+	Subject string `json:"subject"`
+	// Method is how the principal was authenticated, one of MethodJWT, MethodAPIKey or MethodService
+	Method string   `json:"method"`
+	Roles  []string `json:"roles,omitempty"`
+	Scopes []string `json:"scopes,omitempty"`
+	// Tenants are the tenants the principal is bound to, AnyTenant for all of them
+	Tenants []string `json:"tenants,omitempty"`
+}
+
+// AnyTenant in the tenants of a principal binds it to all the tenants
+const AnyTenant = "*"
+
+// Credentials are the credentials of a request, at most one of them is expected
+type Credentials struct {
+	BearerToken string
+	APIKey      string
+}
+
+// BearerToken returns the token of an "Authorization: Bearer <token>" header value, or an empty
+// string if it is not a bearer token.
+func BearerToken(authorization string) string {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(authorization), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// Authenticator authenticates the credentials of requests, and returns the principal. Invalid or
+// missing credentials should be errors of type Unauthenticated.
+type Authenticator interface {
+	Authenticate(ctx context.Context, creds Credentials) (*Principal, error)
+}
+
+type principalCtx struct{}
+
+// WithPrincipal returns a context with the authenticated principal of the request
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalCtx{}, principal)
+}
+
+// PrincipalOf returns the authenticated principal of the context, or nil if the request is not authenticated
+func PrincipalOf(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalCtx{}).(*Principal)
+	return principal
+}
+
+type Config struct {
+	// JWKSURL & JWKSFile are the sources of the keys to verify the JWTs with, only one of them is
+	// expected. The JWKS of the URL is refreshed periodically, while the file is read only once.
+	JWKSURL  string
+	JWKSFile string
+	// JWKSRefreshInterval is the interval at which the JWKS is fetched from the URL, defaults to an hour
+	JWKSRefreshInterval time.Duration
+	// Issuer & Audience are the expected "iss" & "aud" claims of the JWTs, they're not verified if empty
+	Issuer   string
+	Audience string
+	// RolesClaim is the claim of the JWTs with the roles of the principal, defaults to "roles"
+	RolesClaim string
+	// TenantsClaim is the claim of the JWTs with the tenants of the principal, defaults to "tenants"
+	TenantsClaim string
+	// Leeway is the allowed clock skew when verifying the expiry & validity of the JWTs
+	Leeway time.Duration
+	// APIKeys are the static API keys, and the respective principals
+	APIKeys []APIKey
+}
+
+// APIKey is a static API key, the key is configured either as is or as its SHA-256 hash. The hash is
+// preferred, since the keys are secrets.
+type APIKey struct {
+	Key string `json:"key,omitempty"`
+	// SHA256 is the hex encoded SHA-256 hash of the key
+	SHA256  string   `json:"sha256,omitempty"`
+	Subject string   `json:"subject"`
+	Roles   []string `json:"roles,omitempty"`
+	Scopes  []string `json:"scopes,omitempty"`
+	Tenants []string `json:"tenants,omitempty"`
+}
+
+type Auth struct {
+	jwt     *jwtAuthenticator
+	apiKeys *apiKeyAuthenticator
+}
+
+// Authenticate authenticates the bearer token if there's one, or the API key otherwise
+func (au *Auth) Authenticate(ctx context.Context, creds Credentials) (*Principal, error) {
+	switch {
+	case creds.BearerToken != "":
+		if au.jwt == nil {
+			return nil, ErrTokensNotAccepted
+		}
+		return au.jwt.authenticate(ctx, creds.BearerToken)
+	case creds.APIKey != "":
+		if au.apiKeys == nil {
+			return nil, ErrAPIKeysNotAccepted
+		}
+		return au.apiKeys.authenticate(creds.APIKey)
+	}
+
+	return nil, ErrNoCredentials
+}
+
+// New returns an authenticator of JWTs if there's a JWKS configured, and of API keys if there are
+// any configured. At least one of them is required. The context ends the refreshing of the JWKS
+// fetched from its URL.
+func New(ctx context.Context, cfg *Config) (*Auth, error) {
+	au := &Auth{}
+
+	if cfg.JWKSURL != "" || cfg.JWKSFile != "" {
+		jwtAuth, err := newJWTAuthenticator(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		au.jwt = jwtAuth
+	}
+
+	if len(cfg.APIKeys) > 0 {
+		apiKeys, err := newAPIKeyAuthenticator(cfg.APIKeys)
+		if err != nil {
+			return nil, err
+		}
+		au.apiKeys = apiKeys
+	}
+
+	if au.jwt == nil && au.apiKeys == nil {
+		return nil, errors.Validation("authentication requires a JWKS URL or file, or API keys")
+	}
+
+	return au, nil
+}
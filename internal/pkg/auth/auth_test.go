@@ -0,0 +1,259 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/naughtygopher/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signer signs JWTs with a locally generated key, and has the JWKS with its public key
+type signer struct {
+	kid  string
+	key  *ecdsa.PrivateKey
+	jwks []byte
+}
+
+func newSigner(t *testing.T, kid string) *signer {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	const coordinateSize = 32
+	encode := func(coordinate []byte) string {
+		return base64.RawURLEncoding.EncodeToString(coordinate)
+	}
+	jwks, err := json.Marshal(map[string]any{
+		"keys": []map[string]string{{
+			"kty": "EC",
+			"crv": "P-256",
+			"use": "sig",
+			"alg": jwt.SigningMethodES256.Alg(),
+			"kid": kid,
+			"x":   encode(key.X.FillBytes(make([]byte, coordinateSize))),
+			"y":   encode(key.Y.FillBytes(make([]byte, coordinateSize))),
+		}},
+	})
+	require.NoError(t, err)
+
+	return &signer{kid: kid, key: key, jwks: jwks}
+}
+
+func (s *signer) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = s.kid
+	signed, err := token.SignedString(s.key)
+	require.NoError(t, err)
+	return signed
+}
+
+func (s *signer) jwksFile(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, s.jwks, 0o600))
+	return path
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":     "alice",
+		"iss":     "https://issuer.test",
+		"aud":     "items",
+		"exp":     time.Now().Add(time.Hour).Unix(),
+		"roles":   []string{"editor"},
+		"scope":   "items:read items:write",
+		"tenants": []string{"acme"},
+	}
+}
+
+func TestJWT(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+
+	keys := newSigner(t, "key-1")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(keys.jwks)
+	}))
+	t.Cleanup(server.Close)
+
+	sources := map[string]Config{
+		"JWKS file": {JWKSFile: keys.jwksFile(t)},
+		"JWKS URL":  {JWKSURL: server.URL},
+	}
+	for name, cfg := range sources {
+		t.Run(name, func(_ *testing.T) {
+			cfg.Issuer = "https://issuer.test"
+			cfg.Audience = "items"
+			au, err := New(t.Context(), &cfg)
+			requirer.NoError(err)
+
+			principal, err := au.Authenticate(t.Context(), Credentials{BearerToken: keys.sign(t, validClaims())})
+			requirer.NoError(err)
+			asserter.Equal(&Principal{
+				Subject: "alice",
+				Method:  MethodJWT,
+				Roles:   []string{"editor"},
+				Scopes:  []string{"items:read", "items:write"},
+				Tenants: []string{"acme"},
+			}, principal)
+		})
+	}
+
+	au, err := New(t.Context(), &Config{
+		JWKSFile:     keys.jwksFile(t),
+		Issuer:       "https://issuer.test",
+		Audience:     "items",
+		RolesClaim:   "groups",
+		TenantsClaim: "orgs",
+	})
+	requirer.NoError(err)
+
+	t.Run("custom roles & tenants claims, scp", func(_ *testing.T) {
+		claims := validClaims()
+		claims["groups"] = []string{"admin", "auditor"}
+		claims["orgs"] = "acme globex"
+		claims["scp"] = []string{"items:delete"}
+		delete(claims, "scope")
+
+		principal, err := au.Authenticate(t.Context(), Credentials{BearerToken: keys.sign(t, claims)})
+		requirer.NoError(err)
+		asserter.Equal([]string{"admin", "auditor"}, principal.Roles)
+		asserter.Equal([]string{"items:delete"}, principal.Scopes)
+		asserter.Equal([]string{"acme", "globex"}, principal.Tenants)
+	})
+
+	t.Run("invalid tokens", func(_ *testing.T) {
+		invalid := map[string]string{
+			"malformed":         "not-a-jwt",
+			"other key":         newSigner(t, "key-1").sign(t, validClaims()),
+			"unknown key":       newSigner(t, "key-2").sign(t, validClaims()),
+			"expired":           keys.sign(t, withClaim(validClaims(), "exp", time.Now().Add(-time.Minute).Unix())),
+			"no expiry":         keys.sign(t, withClaim(validClaims(), "exp", nil)),
+			"not valid yet":     keys.sign(t, withClaim(validClaims(), "nbf", time.Now().Add(time.Hour).Unix())),
+			"wrong issuer":      keys.sign(t, withClaim(validClaims(), "iss", "https://other.test")),
+			"wrong audience":    keys.sign(t, withClaim(validClaims(), "aud", "orders")),
+			"no subject":        keys.sign(t, withClaim(validClaims(), "sub", nil)),
+			"symmetric signing": hmacSigned(t, validClaims()),
+		}
+		for name, token := range invalid {
+			_, aerr := au.Authenticate(t.Context(), Credentials{BearerToken: token})
+			requirer.Error(aerr, name)
+			asserter.Equal(errors.TypeUnauthenticated, errors.Type(aerr), name)
+		}
+	})
+
+	t.Run("invalid configurations", func(_ *testing.T) {
+		_, nerr := New(t.Context(), &Config{JWKSFile: keys.jwksFile(t), JWKSURL: server.URL})
+		asserter.Error(nerr)
+
+		_, nerr = New(t.Context(), &Config{JWKSFile: filepath.Join(t.TempDir(), "missing.json")})
+		asserter.Error(nerr)
+
+		_, nerr = New(t.Context(), &Config{})
+		asserter.Error(nerr)
+	})
+}
+
+func withClaim(claims jwt.MapClaims, name string, value any) jwt.MapClaims {
+	if value == nil {
+		delete(claims, name)
+		return claims
+	}
+	claims[name] = value
+	return claims
+}
+
+func hmacSigned(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = "key-1"
+	signed, err := token.SignedString([]byte("secret"))
+	require.NoError(t, err)
+	return signed
+}
+
+func TestAPIKeys(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+
+	hash := sha256.Sum256([]byte("billing-key"))
+	au, err := New(t.Context(), &Config{APIKeys: []APIKey{
+		{Key: "reports-key", Subject: "reports", Scopes: []string{"items:read"}},
+		{SHA256: hex.EncodeToString(hash[:]), Subject: "billing", Roles: []string{"editor"}, Tenants: []string{"*"}},
+	}})
+	requirer.NoError(err)
+
+	t.Run("configured keys", func(_ *testing.T) {
+		principal, aerr := au.Authenticate(t.Context(), Credentials{APIKey: "reports-key"})
+		requirer.NoError(aerr)
+		asserter.Equal(&Principal{Subject: "reports", Method: MethodAPIKey, Scopes: []string{"items:read"}}, principal)
+
+		principal, aerr = au.Authenticate(t.Context(), Credentials{APIKey: "billing-key"})
+		requirer.NoError(aerr)
+		asserter.Equal("billing", principal.Subject)
+		asserter.Equal([]string{"editor"}, principal.Roles)
+		asserter.Equal([]string{AnyTenant}, principal.Tenants)
+	})
+
+	t.Run("invalid credentials", func(_ *testing.T) {
+		_, aerr := au.Authenticate(t.Context(), Credentials{APIKey: "other-key"})
+		asserter.ErrorIs(aerr, ErrInvalidAPIKey)
+
+		_, aerr = au.Authenticate(t.Context(), Credentials{})
+		asserter.ErrorIs(aerr, ErrNoCredentials)
+
+		_, aerr = au.Authenticate(t.Context(), Credentials{BearerToken: "token"})
+		asserter.ErrorIs(aerr, ErrTokensNotAccepted)
+	})
+
+	t.Run("invalid keys", func(_ *testing.T) {
+		for _, key := range []APIKey{
+			{Key: "key"},
+			{Subject: "billing"},
+			{Subject: "billing", SHA256: "not-hex"},
+			{Subject: "billing", Key: "key", SHA256: hex.EncodeToString(hash[:])},
+		} {
+			_, nerr := New(t.Context(), &Config{APIKeys: []APIKey{key}})
+			asserter.Error(nerr, key)
+		}
+	})
+}
+
+func TestBearerToken(t *testing.T) {
+	asserter := assert.New(t)
+
+	for authorization, token := range map[string]string{
+		"Bearer abc.def.ghi": "abc.def.ghi",
+		"bearer abc":         "abc",
+		"  Bearer  abc  ":    "abc",
+		"Basic dXNlcjpwYXNz": "",
+		"Bearer":             "",
+		"":                   "",
+	} {
+		asserter.Equal(token, BearerToken(authorization), authorization)
+	}
+}
+
+func TestPrincipalOf(t *testing.T) {
+	asserter := assert.New(t)
+
+	asserter.Nil(PrincipalOf(t.Context()))
+	principal := &Principal{Subject: "alice", Method: MethodJWT}
+	asserter.Equal(principal, PrincipalOf(WithPrincipal(t.Context(), principal)))
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/MicahParks/keyfunc/v3"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/naughtygopher/errors"
+
+	"github.com/prashantkr001/template-go/internal/pkg/logger"
+)
+
+const (
+	defaultRolesClaim   = "roles"
+	defaultTenantsClaim = "tenants"
+)
+
+// validMethods are the accepted signing algorithms, i.e. only asymmetric ones since the keys are public
+var validMethods = []string{
+	jwt.SigningMethodRS256.Alg(), jwt.SigningMethodRS384.Alg(), jwt.SigningMethodRS512.Alg(),
+	jwt.SigningMethodPS256.Alg(), jwt.SigningMethodPS384.Alg(), jwt.SigningMethodPS512.Alg(),
+	jwt.SigningMethodES256.Alg(), jwt.SigningMethodES384.Alg(), jwt.SigningMethodES512.Alg(),
+	jwt.SigningMethodEdDSA.Alg(),
+}
+
+// jwtAuthenticator verifies the signature & the registered claims of JWTs, using the keys of a JWKS
+type jwtAuthenticator struct {
+	keyfunc      keyfunc.Keyfunc
+	parser       *jwt.Parser
+	rolesClaim   string
+	tenantsClaim string
+}
+
+func newJWTAuthenticator(ctx context.Context, cfg *Config) (*jwtAuthenticator, error) {
+	if cfg.JWKSURL != "" && cfg.JWKSFile != "" {
+		return nil, errors.Validation("only one of JWKS URL or file is expected")
+	}
+
+	kf, err := newKeyfunc(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(validMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+
+	rolesClaim := cfg.RolesClaim
+	if rolesClaim == "" {
+		rolesClaim = defaultRolesClaim
+	}
+	tenantsClaim := cfg.TenantsClaim
+	if tenantsClaim == "" {
+		tenantsClaim = defaultTenantsClaim
+	}
+
+	return &jwtAuthenticator{
+		keyfunc:      kf,
+		parser:       jwt.NewParser(options...),
+		rolesClaim:   rolesClaim,
+		tenantsClaim: tenantsClaim,
+	}, nil
+}
+
+func newKeyfunc(ctx context.Context, cfg *Config) (keyfunc.Keyfunc, error) { //nolint:ireturn // keyfunc has no exported implementation
+	if cfg.JWKSFile != "" {
+		raw, err := os.ReadFile(cfg.JWKSFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read JWKS file '%s'", cfg.JWKSFile)
+		}
+		kf, err := keyfunc.NewJWKSetJSON(json.RawMessage(raw))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid JWKS file '%s'", cfg.JWKSFile)
+		}
+		return kf, nil
+	}
+
+	// failing to fetch the JWKS does not fail the startup, it is retried on every refresh & unknown key
+	kf, err := keyfunc.NewDefaultOverrideCtx(ctx, []string{cfg.JWKSURL}, keyfunc.Override{
+		RefreshInterval: cfg.JWKSRefreshInterval,
+		RefreshErrorHandlerFunc: func(url string) func(ctx context.Context, err error) {
+			return func(ctx context.Context, err error) {
+				logger.ErrorCtx(ctx, errors.Wrapf(err, "failed to refresh JWKS from '%s'", url).Error())
+			}
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid JWKS URL '%s'", cfg.JWKSURL)
+	}
+	return kf, nil
+}
+
+func (ja *jwtAuthenticator) authenticate(ctx context.Context, token string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := ja.parser.ParseWithClaims(token, claims, ja.keyfunc.KeyfuncCtx(ctx))
+	if err != nil {
+		return nil, errors.UnauthenticatedErr(err, "invalid bearer token")
+	}
+
+	subject, _ := claims.GetSubject()
+	if strings.TrimSpace(subject) == "" {
+		return nil, errors.Unauthenticated("invalid bearer token, subject is required")
+	}
+
+	// "scope" is a space separated string (RFC 8693), while some providers use "scp" as a list
+	scopes := claimStrings(claims["scope"])
+	scopes = append(scopes, claimStrings(claims["scp"])...)
+
+	return &Principal{
+		Subject: subject,
+		Method:  MethodJWT,
+		Roles:   claimStrings(claims[ja.rolesClaim]),
+		Scopes:  scopes,
+		Tenants: claimStrings(claims[ja.tenantsClaim]),
+	}, nil
+}
+
+// claimStrings returns the values of a claim which is either a list of strings, or a space separated string
+func claimStrings(claim any) []string {
+	switch value := claim.(type) {
+	case string:
+		return strings.Fields(value)
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			if str, ok := v.(string); ok && str != "" {
+				values = append(values, str)
+			}
+		}
+		return values
+	}
+	return nil
+}
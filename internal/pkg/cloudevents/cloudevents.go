@@ -0,0 +1,304 @@
+// Package cloudevents implements the Kafka protocol binding of CloudEvents 1.0, for both binary
+// and structured content modes. ref: https://github.com/cloudevents/spec/blob/v1.0.2/cloudevents/bindings/kafka-protocol-binding.md
+package cloudevents
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/naughtygopher/errors"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+const (
+	SpecVersion = "1.0"
+
+	ContentTypeJSON       = "application/json"
+	ContentTypeStructured = "application/cloudevents+json"
+
+	headerContentType = "content-type"
+	headerPrefix      = "ce_"
+)
+
+type Mode string
+
+const (
+	// ModeBinary sets the attributes as Kafka headers, and the data as the record value
+	ModeBinary Mode = "binary"
+	// ModeStructured sets the whole event as a JSON document in the record value
+	ModeStructured Mode = "structured"
+)
+
+var (
+	ErrNotCloudEvent   = errors.New("record is not a cloudevent")
+	ErrInvalidEvent    = errors.InputBody("invalid cloudevent")
+	ErrUnsupportedMode = errors.New("unsupported cloudevents content mode")
+)
+
+// Event holds the context attributes & data of a CloudEvent
+type Event struct {
+	ID              string
+	Source          string
+	Type            string
+	Time            time.Time
+	Subject         string
+	DataContentType string
+	DataSchema      string
+	// Extensions are additional context attributes, e.g. traceparent. The names should only
+	// consist of lowercase letters & digits
+	Extensions map[string]string
+	Data       []byte
+}
+
+func (evt *Event) validate() error {
+	missing := []string{}
+	if evt.ID == "" {
+		missing = append(missing, "id")
+	}
+	if evt.Source == "" {
+		missing = append(missing, "source")
+	}
+	if evt.Type == "" {
+		missing = append(missing, "type")
+	}
+	if len(missing) > 0 {
+		return errors.Wrapf(ErrInvalidEvent, "missing attribute(s): %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// attributes returns all the context attributes with non-empty values, as strings
+func (evt *Event) attributes() map[string]string {
+	attrs := make(map[string]string, len(evt.Extensions)+7)
+	for key, value := range evt.Extensions {
+		attrs[key] = value
+	}
+	attrs["specversion"] = SpecVersion
+	attrs["id"] = evt.ID
+	attrs["source"] = evt.Source
+	attrs["type"] = evt.Type
+	if !evt.Time.IsZero() {
+		attrs["time"] = evt.Time.Format(time.RFC3339Nano)
+	}
+	if evt.Subject != "" {
+		attrs["subject"] = evt.Subject
+	}
+	if evt.DataSchema != "" {
+		attrs["dataschema"] = evt.DataSchema
+	}
+	return attrs
+}
+
+// setAttribute sets the context attribute, all unknown attributes are considered extensions
+func (evt *Event) setAttribute(key, value string) error {
+	switch key {
+	case "specversion":
+		if value != SpecVersion {
+			return errors.Wrapf(ErrInvalidEvent, "unsupported specversion %q", value)
+		}
+	case "id":
+		evt.ID = value
+	case "source":
+		evt.Source = value
+	case "type":
+		evt.Type = value
+	case "time":
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return errors.Wrap(ErrInvalidEvent, err.Error())
+		}
+		evt.Time = t
+	case "subject":
+		evt.Subject = value
+	case "datacontenttype":
+		evt.DataContentType = value
+	case "dataschema":
+		evt.DataSchema = value
+	default:
+		if evt.Extensions == nil {
+			evt.Extensions = map[string]string{}
+		}
+		evt.Extensions[key] = value
+	}
+	return nil
+}
+
+// ToRecord encodes the event into the Kafka record, based on the mode. The key, topic etc. of the
+// record are retained.
+func ToRecord(evt *Event, mode Mode, record *kgo.Record) error {
+	err := evt.validate()
+	if err != nil {
+		return err
+	}
+
+	switch mode {
+	case ModeBinary:
+		toBinary(evt, record)
+		return nil
+	case ModeStructured:
+		return toStructured(evt, record)
+	}
+
+	return errors.Wrapf(ErrUnsupportedMode, ": %s", mode)
+}
+
+func toBinary(evt *Event, record *kgo.Record) {
+	for key, value := range evt.attributes() {
+		record.Headers = append(record.Headers, kgo.RecordHeader{Key: headerPrefix + key, Value: []byte(value)})
+	}
+	if evt.DataContentType != "" {
+		record.Headers = append(record.Headers, kgo.RecordHeader{
+			Key:   headerContentType,
+			Value: []byte(evt.DataContentType),
+		})
+	}
+	record.Value = evt.Data
+}
+
+func toStructured(evt *Event, record *kgo.Record) error {
+	doc := make(map[string]any, len(evt.Extensions)+9)
+	for key, value := range evt.attributes() {
+		doc[key] = value
+	}
+	if evt.DataContentType != "" {
+		doc["datacontenttype"] = evt.DataContentType
+	}
+	if len(evt.Data) > 0 {
+		if isJSON(evt.DataContentType) && json.Valid(evt.Data) {
+			doc["data"] = json.RawMessage(evt.Data)
+		} else {
+			doc["data_base64"] = base64.StdEncoding.EncodeToString(evt.Data)
+		}
+	}
+
+	jbytes, err := json.Marshal(doc)
+	if err != nil {
+		return errors.Wrap(err, "json marshal failed")
+	}
+
+	record.Headers = append(record.Headers, kgo.RecordHeader{
+		Key:   headerContentType,
+		Value: []byte(ContentTypeStructured),
+	})
+	record.Value = jbytes
+	return nil
+}
+
+// FromRecord decodes the event from the Kafka record, and returns the mode it was encoded in.
+// It returns ErrNotCloudEvent if the record is neither in binary nor structured mode.
+func FromRecord(record *kgo.Record) (*Event, Mode, error) {
+	contentType := ""
+	binary := false
+	for _, header := range record.Headers {
+		switch {
+		case header.Key == headerContentType:
+			contentType = string(header.Value)
+		case strings.HasPrefix(header.Key, headerPrefix):
+			binary = true
+		}
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == ContentTypeStructured {
+		evt, err := fromStructured(record.Value)
+		if err != nil {
+			return nil, ModeStructured, err
+		}
+		return evt, ModeStructured, nil
+	}
+
+	if binary {
+		evt, err := fromBinary(record, contentType)
+		if err != nil {
+			return nil, ModeBinary, err
+		}
+		return evt, ModeBinary, nil
+	}
+
+	return nil, "", ErrNotCloudEvent
+}
+
+func fromBinary(record *kgo.Record, contentType string) (*Event, error) {
+	evt := &Event{DataContentType: contentType, Data: record.Value}
+	specVersion := ""
+	for _, header := range record.Headers {
+		key, ok := strings.CutPrefix(header.Key, headerPrefix)
+		if !ok {
+			continue
+		}
+		if key == "specversion" {
+			specVersion = string(header.Value)
+		}
+		err := evt.setAttribute(key, string(header.Value))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if specVersion == "" {
+		return nil, errors.Wrap(ErrInvalidEvent, "missing attribute(s): specversion")
+	}
+
+	err := evt.validate()
+	if err != nil {
+		return nil, err
+	}
+	return evt, nil
+}
+
+func fromStructured(payload []byte) (*Event, error) {
+	doc := map[string]json.RawMessage{}
+	err := json.Unmarshal(payload, &doc)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidEvent, err.Error())
+	}
+
+	evt := &Event{}
+	if _, ok := doc["specversion"]; !ok {
+		return nil, errors.Wrap(ErrInvalidEvent, "missing attribute(s): specversion")
+	}
+	for key, raw := range doc {
+		switch key {
+		case "data":
+			evt.Data = raw
+			continue
+		case "data_base64":
+			encoded := ""
+			err = json.Unmarshal(raw, &encoded)
+			if err == nil {
+				evt.Data, err = base64.StdEncoding.DecodeString(encoded)
+			}
+			if err != nil {
+				return nil, errors.Wrap(ErrInvalidEvent, err.Error())
+			}
+			continue
+		}
+
+		// extension values can be of other JSON types, e.g. numbers & booleans
+		value := ""
+		if json.Unmarshal(raw, &value) != nil {
+			value = string(raw)
+		}
+		err = evt.setAttribute(key, value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = evt.validate()
+	if err != nil {
+		return nil, err
+	}
+	return evt, nil
+}
+
+func isJSON(contentType string) bool {
+	if contentType == "" {
+		// JSON is implied for structured mode, if the content type is not specified
+		return true
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == ContentTypeJSON || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,92 @@
+package cloudevents
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func TestRoundTrip(t *testing.T) {
+	asserter := assert.New(t)
+	requirer := require.New(t)
+
+	evt := &Event{
+		ID:              "01J9Z3NDEKTSV4RRFFQ69G5FAV",
+		Source:          "template-go",
+		Type:            "item.created",
+		Time:            time.Date(2024, 10, 1, 10, 0, 0, 0, time.UTC),
+		Subject:         "123",
+		DataContentType: ContentTypeJSON,
+		Extensions:      map[string]string{"schemaversion": "1"},
+		Data:            []byte(`{"id":123,"name":"Bottle"}`),
+	}
+
+	for _, mode := range []Mode{ModeBinary, ModeStructured} {
+		t.Run(string(mode), func(_ *testing.T) {
+			record := &kgo.Record{Key: []byte("123")}
+			requirer.NoError(ToRecord(evt, mode, record))
+
+			decoded, decodedMode, err := FromRecord(record)
+			requirer.NoError(err)
+			asserter.Equal(mode, decodedMode)
+			asserter.Equal([]byte("123"), record.Key)
+			asserter.JSONEq(string(evt.Data), string(decoded.Data))
+			decoded.Data = evt.Data
+			asserter.Equal(evt, decoded)
+		})
+	}
+
+	t.Run("structured mode with non-JSON data", func(_ *testing.T) {
+		bevt := *evt
+		bevt.DataContentType = "application/octet-stream"
+		bevt.Data = []byte{0xde, 0xad}
+		record := &kgo.Record{}
+		requirer.NoError(ToRecord(&bevt, ModeStructured, record))
+		asserter.Contains(string(record.Value), `"data_base64":"3q0="`)
+
+		decoded, _, err := FromRecord(record)
+		requirer.NoError(err)
+		asserter.Equal(bevt.Data, decoded.Data)
+	})
+}
+
+func TestFromRecord(t *testing.T) {
+	asserter := assert.New(t)
+	requirer := require.New(t)
+
+	t.Run("legacy records are not cloudevents", func(_ *testing.T) {
+		_, _, err := FromRecord(&kgo.Record{Value: []byte(`{"id":1}`)})
+		requirer.ErrorIs(err, ErrNotCloudEvent)
+		asserter.True(errors.Is(err, ErrNotCloudEvent))
+	})
+
+	t.Run("missing attributes are rejected", func(_ *testing.T) {
+		_, mode, err := FromRecord(&kgo.Record{
+			Headers: []kgo.RecordHeader{
+				{Key: "ce_specversion", Value: []byte(SpecVersion)},
+				{Key: "ce_id", Value: []byte("1")},
+			},
+		})
+		requirer.ErrorIs(err, ErrInvalidEvent)
+		asserter.Equal(ModeBinary, mode)
+
+		_, mode, err = FromRecord(&kgo.Record{
+			Headers: []kgo.RecordHeader{{Key: "content-type", Value: []byte(ContentTypeStructured + "; charset=utf-8")}},
+			Value:   []byte(`{"id":"1","source":"s","type":"t"}`),
+		})
+		requirer.ErrorIs(err, ErrInvalidEvent)
+		asserter.Equal(ModeStructured, mode)
+	})
+
+	t.Run("unsupported spec version is rejected", func(_ *testing.T) {
+		_, _, err := FromRecord(&kgo.Record{
+			Headers: []kgo.RecordHeader{{Key: "content-type", Value: []byte(ContentTypeStructured)}},
+			Value:   []byte(`{"specversion":"0.3","id":"1","source":"s","type":"t"}`),
+		})
+		requirer.ErrorIs(err, ErrInvalidEvent)
+	})
+}
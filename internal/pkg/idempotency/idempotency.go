@@ -0,0 +1,211 @@
+// Package idempotency makes retries of non-idempotent requests (e.g. creates) safe. The response of
+// a request with an idempotency key is stored, and replayed for the retries with the same key,
+// instead of processing the request again.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/naughtygopher/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+
+	"github.com/prashantkr001/template-go/internal/pkg/apm"
+	"github.com/prashantkr001/template-go/internal/pkg/logger"
+)
+
+// MaxKeyLength is the maximum length of idempotency keys, long enough for UUIDs or ULIDs with a prefix
+const MaxKeyLength = 255
+
+var (
+	ErrInvalidKey = errors.InputBody("idempotency key should be non empty & at most 255 characters")
+	// ErrKeyReused is returned if the key was used for a different request, i.e. it's a client error
+	ErrKeyReused = errors.Validation("idempotency key was already used with a different request")
+	// ErrInProgress is returned for retries while the original request is still being processed
+	ErrInProgress = errors.Duplicate("a request with the same idempotency key is in progress")
+)
+
+// Record is stored for every idempotency key, Response is set once the request is complete
+type Record struct {
+	Fingerprint string          `json:"fingerprint"`
+	Response    json.RawMessage `json:"response,omitempty"`
+	Complete    bool            `json:"complete"`
+}
+
+// Store keeps the records of idempotency keys until they expire
+type Store interface {
+	// Claim stores the record if there's none for the key, and returns nil. If there is one already,
+	// it is returned instead. It should be atomic, so that only one of concurrent requests is processed.
+	Claim(ctx context.Context, key string, record *Record, ttl time.Duration) (*Record, error)
+	// Save replaces the record of the key, it is used to store the response of the request
+	Save(ctx context.Context, key string, record *Record, ttl time.Duration) error
+	// Release deletes the record of the key, so that the request can be retried
+	Release(ctx context.Context, key string) error
+}
+
+type Config struct {
+	// TTL is the duration for which the responses are replayed
+	TTL time.Duration
+	// LockTTL is the duration for which a key is locked while its request is in progress. It
+	// expires the lock of requests which never completed, e.g. if the app crashed.
+	LockTTL time.Duration
+}
+
+type Idempotency struct {
+	store Store
+	cfg   Config
+}
+
+func New(store Store, cfg *Config) (*Idempotency, error) {
+	const (
+		defaultTTL     = time.Hour * 24
+		defaultLockTTL = time.Minute
+	)
+
+	if store == nil {
+		return nil, errors.Validation("idempotency store is required")
+	}
+
+	idem := &Idempotency{store: store, cfg: *cfg}
+	if idem.cfg.TTL <= 0 {
+		idem.cfg.TTL = defaultTTL
+	}
+	if idem.cfg.LockTTL <= 0 {
+		idem.cfg.LockTTL = defaultLockTTL
+	}
+
+	return idem, nil
+}
+
+type keyCtx struct{}
+
+// WithKey returns a context with the idempotency key of the request
+func WithKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, keyCtx{}, key)
+}
+
+// Key returns the idempotency key of the context, or an empty string if there's none
+func Key(ctx context.Context) string {
+	key, _ := ctx.Value(keyCtx{}).(string)
+	return key
+}
+
+// Fingerprint is the SHA-256 of the operation & the request as JSON, it identifies the request
+// irrespective of the key used.
+func Fingerprint(operation string, request any) (string, error) {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return "", errors.Wrap(err, "json marshal failed")
+	}
+
+	hash := sha256.New()
+	_, _ = hash.Write([]byte(operation))
+	_, _ = hash.Write([]byte{0})
+	_, _ = hash.Write(payload)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Do calls fn only once per idempotency key of the context, and returns the same response for all
+// the calls with the key. Failed calls are not stored, so that they can be retried with the same key.
+// fn is always called if the context has no key, if idem is nil or if the store is unavailable. Once
+// the key is claimed, fn & the store are called with a context which is not cancelled with ctx.
+// The keys are isolated by scope (e.g. the tenant), so that different callers can use the same key.
+func Do[T any](
+	ctx context.Context,
+	idem *Idempotency,
+	scope, operation string,
+	request any,
+	fn func(context.Context) (T, error),
+) (T, error) {
+	key := strings.TrimSpace(Key(ctx))
+	if idem == nil || key == "" {
+		return fn(ctx)
+	}
+
+	return do(ctx, idem, scope, operation, key, request, fn)
+}
+
+func do[T any](
+	ctx context.Context,
+	idem *Idempotency,
+	scope, operation, key string,
+	request any,
+	fn func(context.Context) (T, error),
+) (T, error) {
+	var empty T
+	if len(key) > MaxKeyLength {
+		return empty, ErrInvalidKey
+	}
+
+	fingerprint, err := Fingerprint(operation, request)
+	if err != nil {
+		return empty, err
+	}
+
+	// the keys are scoped by the operation, so that the same key can be used for different operations
+	storeKey := scope + ":" + operation + ":" + key
+	existing, err := idem.store.Claim(ctx, storeKey, &Record{Fingerprint: fingerprint}, idem.cfg.LockTTL)
+	if err != nil {
+		// fails open, the request is served without idempotency while the store is unavailable. Retries
+		// of a create would then result in a conflict, instead of the original response.
+		logger.WarnCtx(ctx, "idempotency store unavailable, request is not idempotent", zap.Error(err))
+		return fn(ctx)
+	}
+	if existing != nil {
+		return replay[T](ctx, operation, fingerprint, existing)
+	}
+
+	// once claimed, the request is processed & recorded even if the client disconnects (e.g. on a
+	// timeout), so that its retry gets the response instead of a conflict
+	ctx = context.WithoutCancel(ctx)
+	response, err := fn(ctx)
+	if err != nil {
+		rerr := idem.store.Release(ctx, storeKey)
+		if rerr != nil {
+			// retries would be rejected as in progress, until the lock expires
+			logger.ErrWithStacktrace(errors.Wrap(rerr, "failed to release the idempotency key"))
+		}
+		return empty, err
+	}
+
+	err = idem.save(ctx, storeKey, fingerprint, response)
+	if err != nil {
+		// the request succeeded irrespective, but its retries would be rejected as in progress
+		// until the lock expires
+		logger.ErrWithStacktrace(errors.Wrap(err, "failed to store the idempotent response"))
+	}
+
+	return response, nil
+}
+
+func (idem *Idempotency) save(ctx context.Context, key, fingerprint string, response any) error {
+	payload, err := json.Marshal(response)
+	if err != nil {
+		return errors.Wrap(err, "json marshal failed")
+	}
+
+	return idem.store.Save(ctx, key, &Record{Fingerprint: fingerprint, Response: payload, Complete: true}, idem.cfg.TTL)
+}
+
+func replay[T any](ctx context.Context, operation, fingerprint string, existing *Record) (T, error) {
+	var response T
+	switch {
+	case existing.Fingerprint != fingerprint:
+		return response, ErrKeyReused
+	case !existing.Complete:
+		return response, ErrInProgress
+	}
+
+	err := json.Unmarshal(existing.Response, &response)
+	if err != nil {
+		return response, errors.Wrap(err, "invalid idempotent response")
+	}
+	apm.Global().AppMeter().CounterAdd(ctx, "idempotency.replays", 1, attribute.String("operation", operation))
+
+	return response, nil
+}
@@ -0,0 +1,177 @@
+package idempotency
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/naughtygopher/errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type createRequest struct {
+	Name string `json:"name"`
+}
+
+type createResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestDo(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+
+	stores := map[string]Store{
+		"memory": NewMemoryStore(),
+		"redis":  NewRedisStore(client, "idempotency:"),
+	}
+	for name, store := range stores {
+		t.Run(name, func(_ *testing.T) {
+			idem, err := New(store, &Config{TTL: time.Hour})
+			requirer.NoError(err)
+
+			calls := 0
+			create := func(_ context.Context) (*createResponse, error) {
+				calls++
+				return &createResponse{ID: calls, Name: "Box"}, nil
+			}
+			ctx := WithKey(t.Context(), "key-1")
+
+			first, derr := Do(ctx, idem, "acme", "create", createRequest{Name: "Box"}, create)
+			requirer.NoError(derr)
+			replayed, derr := Do(ctx, idem, "acme", "create", createRequest{Name: "Box"}, create)
+			requirer.NoError(derr)
+			asserter.Equal(first, replayed)
+			asserter.Equal(1, calls)
+
+			_, derr = Do(ctx, idem, "acme", "create", createRequest{Name: "Jar"}, create)
+			asserter.ErrorIs(derr, ErrKeyReused)
+
+			// the keys are scoped by operation
+			_, derr = Do(ctx, idem, "acme", "update", createRequest{Name: "Box"}, create)
+			requirer.NoError(derr)
+			asserter.Equal(2, calls)
+
+			// and by scope
+			_, derr = Do(ctx, idem, "globex", "create", createRequest{Name: "Jar"}, create)
+			requirer.NoError(derr)
+			asserter.Equal(3, calls)
+
+			// requests without a key are not idempotent
+			_, derr = Do(t.Context(), idem, "acme", "create", createRequest{Name: "Box"}, create)
+			requirer.NoError(derr)
+			asserter.Equal(4, calls)
+		})
+	}
+
+	t.Run("failed requests can be retried", func(_ *testing.T) {
+		idem, err := New(NewMemoryStore(), &Config{})
+		requirer.NoError(err)
+		ctx := WithKey(t.Context(), "key-2")
+
+		errFailed := errors.Internal("failed")
+		_, derr := Do(ctx, idem, "acme", "create", createRequest{}, func(context.Context) (int, error) {
+			return 0, errFailed
+		})
+		asserter.ErrorIs(derr, errFailed)
+
+		resp, derr := Do(ctx, idem, "acme", "create", createRequest{}, func(context.Context) (int, error) {
+			return 1, nil
+		})
+		requirer.NoError(derr)
+		asserter.Equal(1, resp)
+	})
+
+	t.Run("concurrent retries are rejected while in progress", func(_ *testing.T) {
+		idem, err := New(NewMemoryStore(), &Config{})
+		requirer.NoError(err)
+		ctx := WithKey(t.Context(), "key-3")
+
+		_, derr := Do(ctx, idem, "acme", "create", createRequest{}, func(ctx context.Context) (int, error) {
+			return Do(ctx, idem, "acme", "create", createRequest{}, func(context.Context) (int, error) {
+				return 1, nil
+			})
+		})
+		asserter.ErrorIs(derr, ErrInProgress)
+	})
+
+	t.Run("records expire after the TTL", func(_ *testing.T) {
+		idem, err := New(NewRedisStore(client, "ttl:"), &Config{TTL: time.Minute})
+		requirer.NoError(err)
+		ctx := WithKey(t.Context(), "key-4")
+
+		_, derr := Do(ctx, idem, "acme", "create", createRequest{}, func(context.Context) (int, error) {
+			return 1, nil
+		})
+		requirer.NoError(derr)
+		asserter.Equal(time.Minute, server.TTL("ttl:acme:create:key-4"))
+
+		server.FastForward(time.Minute)
+		_, derr = Do(ctx, idem, "acme", "create", createRequest{Name: "Jar"}, func(context.Context) (int, error) {
+			return 2, nil
+		})
+		asserter.NoError(derr)
+	})
+
+	t.Run("requests are served without idempotency if the store is unavailable", func(_ *testing.T) {
+		unavailable := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		requirer.NoError(unavailable.Close())
+		idem, err := New(NewRedisStore(unavailable, "idempotency:"), &Config{})
+		requirer.NoError(err)
+		ctx := WithKey(t.Context(), "key-5")
+
+		calls := 0
+		for range 2 {
+			resp, derr := Do(ctx, idem, "acme", "create", createRequest{}, func(context.Context) (int, error) {
+				calls++
+				return calls, nil
+			})
+			requirer.NoError(derr)
+			asserter.Equal(calls, resp)
+		}
+		asserter.Equal(2, calls)
+	})
+
+	t.Run("requests are recorded even if the client disconnects", func(_ *testing.T) {
+		idem, err := New(NewRedisStore(client, "disconnect:"), &Config{})
+		requirer.NoError(err)
+		ctx, cancel := context.WithCancel(WithKey(t.Context(), "key-6"))
+
+		calls := 0
+		create := func(ctx context.Context) (int, error) {
+			calls++
+			// the client disconnects after the item is created
+			cancel()
+			return calls, ctx.Err()
+		}
+		resp, derr := Do(ctx, idem, "acme", "create", createRequest{}, create)
+		requirer.NoError(derr)
+		asserter.Equal(1, resp)
+
+		replayed, derr := Do(WithKey(t.Context(), "key-6"), idem, "acme", "create", createRequest{}, create)
+		requirer.NoError(derr)
+		asserter.Equal(1, replayed)
+		asserter.Equal(1, calls)
+	})
+
+	t.Run("invalid key", func(_ *testing.T) {
+		idem, err := New(NewMemoryStore(), &Config{})
+		requirer.NoError(err)
+		ctx := WithKey(t.Context(), string(make([]byte, MaxKeyLength+1)))
+
+		_, derr := Do(ctx, idem, "acme", "create", createRequest{}, func(context.Context) (int, error) {
+			return 1, nil
+		})
+		asserter.ErrorIs(derr, ErrInvalidKey)
+	})
+}
@@ -0,0 +1,68 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	record    Record
+	expiresAt time.Time
+}
+
+// memoryStore keeps the records in memory, i.e. they're not shared by the instances of the app
+type memoryStore struct {
+	locker  *sync.Mutex
+	entries map[string]memoryEntry
+	now     func() time.Time
+}
+
+func NewMemoryStore() *memoryStore { //nolint:revive // it is ok to return unexported type in this case, ensures controlled access
+	return &memoryStore{
+		locker:  &sync.Mutex{},
+		entries: map[string]memoryEntry{},
+		now:     time.Now,
+	}
+}
+
+func (ms *memoryStore) Claim(_ context.Context, key string, record *Record, ttl time.Duration) (*Record, error) {
+	ms.locker.Lock()
+	defer ms.locker.Unlock()
+
+	now := ms.now()
+	ms.removeExpired(now)
+	if entry, ok := ms.entries[key]; ok {
+		existing := entry.record
+		return &existing, nil
+	}
+
+	ms.entries[key] = memoryEntry{record: *record, expiresAt: now.Add(ttl)}
+	return nil, nil //nolint:nilnil // nil record means the key is claimed
+}
+
+func (ms *memoryStore) Save(_ context.Context, key string, record *Record, ttl time.Duration) error {
+	ms.locker.Lock()
+	defer ms.locker.Unlock()
+
+	ms.entries[key] = memoryEntry{record: *record, expiresAt: ms.now().Add(ttl)}
+	return nil
+}
+
+func (ms *memoryStore) Release(_ context.Context, key string) error {
+	ms.locker.Lock()
+	defer ms.locker.Unlock()
+
+	delete(ms.entries, key)
+	return nil
+}
+
+// removeExpired is called on every claim, hence the store never has more than the records of the
+// last TTL
+func (ms *memoryStore) removeExpired(now time.Time) {
+	for key, entry := range ms.entries {
+		if now.After(entry.expiresAt) {
+			delete(ms.entries, key)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"github.com/naughtygopher/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// mongoRecord is the document of a record, identified by its key
+type mongoRecord struct {
+	Key         string    `bson:"_id"`
+	Fingerprint string    `bson:"fingerprint"`
+	Response    []byte    `bson:"response,omitempty"`
+	Complete    bool      `bson:"complete"`
+	ExpiresAt   time.Time `bson:"expiresAt"`
+}
+
+func newMongoRecord(key string, record *Record, expiresAt time.Time) *mongoRecord {
+	return &mongoRecord{
+		Key:         key,
+		Fingerprint: record.Fingerprint,
+		Response:    record.Response,
+		Complete:    record.Complete,
+		ExpiresAt:   expiresAt,
+	}
+}
+
+// mongoStore keeps the records in a collection, they're shared by all the instances of the app. The
+// expired records are removed by a TTL index, which is not immediate, hence they're ignored until removed.
+type mongoStore struct {
+	collection *mongo.Collection
+	now        func() time.Time
+}
+
+func NewMongoStore(collection *mongo.Collection) *mongoStore { //nolint:revive // it is ok to return unexported type in this case, ensures controlled access
+	return &mongoStore{
+		collection: collection,
+		now:        time.Now,
+	}
+}
+
+// EnsureIndexes creates the TTL index which removes the expired records
+func (ms *mongoStore) EnsureIndexes(ctx context.Context) error {
+	_, err := ms.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetName("expiresAt_ttl").SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "could not create %s indexes", ms.collection.Name())
+	}
+
+	return nil
+}
+
+// Claim upserts the record if there's none for the key or if it is expired. The upsert fails with a
+// duplicate key error if a live record exists, which is then returned.
+func (ms *mongoStore) Claim(ctx context.Context, key string, record *Record, ttl time.Duration) (*Record, error) {
+	now := ms.now().UTC()
+	_, err := ms.collection.ReplaceOne(
+		ctx,
+		bson.M{"_id": key, "expiresAt": bson.M{"$lte": now}},
+		newMongoRecord(key, record, now.Add(ttl)),
+		options.Replace().SetUpsert(true),
+	)
+	if err == nil {
+		return nil, nil //nolint:nilnil // nil record means the key is claimed
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, errors.Wrap(err, "could not claim the idempotency key")
+	}
+
+	existing := new(mongoRecord)
+	err = ms.collection.FindOne(ctx, bson.M{"_id": key, "expiresAt": bson.M{"$gt": now}}).Decode(existing)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// the existing record expired or was released meanwhile
+		return ms.Claim(ctx, key, record, ttl)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get the idempotency record")
+	}
+
+	return &Record{Fingerprint: existing.Fingerprint, Response: existing.Response, Complete: existing.Complete}, nil
+}
+
+func (ms *mongoStore) Save(ctx context.Context, key string, record *Record, ttl time.Duration) error {
+	_, err := ms.collection.ReplaceOne(
+		ctx,
+		bson.M{"_id": key},
+		newMongoRecord(key, record, ms.now().UTC().Add(ttl)),
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		return errors.Wrap(err, "could not store the idempotency record")
+	}
+
+	return nil
+}
+
+func (ms *mongoStore) Release(ctx context.Context, key string) error {
+	_, err := ms.collection.DeleteOne(ctx, bson.M{"_id": key})
+	if err != nil {
+		return errors.Wrap(err, "could not release the idempotency key")
+	}
+
+	return nil
+}
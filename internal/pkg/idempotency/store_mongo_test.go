@@ -0,0 +1,81 @@
+package idempotency
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestMongoStore(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("keys are claimed by upserting unless a live record exists", func(mt *mtest.T) {
+		requirer := require.New(mt)
+		asserter := assert.New(mt)
+		store := NewMongoStore(mt.Coll)
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+		existing, err := store.Claim(mt.Context(), "acme:create:key-1", &Record{Fingerprint: "f1"}, time.Hour)
+		requirer.NoError(err)
+		asserter.Nil(existing)
+
+		started := mt.GetStartedEvent()
+		requirer.NotNil(started)
+		asserter.Equal("acme:create:key-1", started.Command.Lookup("updates", "0", "q", "_id").StringValue())
+		asserter.True(started.Command.Lookup("updates", "0", "upsert").Boolean())
+	})
+
+	mt.Run("the live record is returned for a claimed key", func(mt *mtest.T) {
+		requirer := require.New(mt)
+		asserter := assert.New(mt)
+		store := NewMongoStore(mt.Coll)
+
+		namespace := mt.DB.Name() + "." + mt.Coll.Name()
+		mt.AddMockResponses(
+			mtest.CreateWriteErrorsResponse(mtest.WriteError{
+				Index:   0,
+				Code:    11000,
+				Message: "E11000 duplicate key error collection: item_idempotency index: _id_",
+			}),
+			mtest.CreateCursorResponse(0, namespace, mtest.FirstBatch, bson.D{
+				{Key: "_id", Value: "acme:create:key-1"},
+				{Key: "fingerprint", Value: "f1"},
+				{Key: "response", Value: []byte(`{"id":1}`)},
+				{Key: "complete", Value: true},
+				{Key: "expiresAt", Value: time.Now().Add(time.Hour)},
+			}),
+		)
+		existing, err := store.Claim(mt.Context(), "acme:create:key-1", &Record{Fingerprint: "f2"}, time.Hour)
+		requirer.NoError(err)
+		asserter.Equal(&Record{Fingerprint: "f1", Response: []byte(`{"id":1}`), Complete: true}, existing)
+	})
+
+	mt.Run("the key is claimed again if the live record is gone meanwhile", func(mt *mtest.T) {
+		requirer := require.New(mt)
+		asserter := assert.New(mt)
+		store := NewMongoStore(mt.Coll)
+
+		namespace := mt.DB.Name() + "." + mt.Coll.Name()
+		mt.AddMockResponses(
+			mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "E11000 duplicate key error"}),
+			mtest.CreateCursorResponse(0, namespace, mtest.FirstBatch),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+		)
+		existing, err := store.Claim(mt.Context(), "acme:create:key-1", &Record{Fingerprint: "f1"}, time.Hour)
+		requirer.NoError(err)
+		asserter.Nil(existing)
+	})
+
+	mt.Run("other errors are returned", func(mt *mtest.T) {
+		requirer := require.New(mt)
+		store := NewMongoStore(mt.Coll)
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 91, Message: "shutting down"}))
+		_, err := store.Claim(mt.Context(), "acme:create:key-1", &Record{Fingerprint: "f1"}, time.Hour)
+		requirer.Error(err)
+	})
+}
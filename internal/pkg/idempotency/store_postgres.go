@@ -0,0 +1,160 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/naughtygopher/errors"
+
+	"github.com/prashantkr001/template-go/internal/pkg/logger"
+)
+
+const (
+	// postgresExpiredBatch is the maximum number of expired records removed at once
+	postgresExpiredBatch = 500
+	// postgresClaimAttempts is the number of attempts to claim a key, if the live record is gone
+	// meanwhile (i.e. it expired or was released) on every attempt
+	postgresClaimAttempts = 3
+)
+
+// postgresStore keeps the records in a table, they're shared by all the instances of the app. The table
+// should have the columns key (primary key), fingerprint, response (JSONB), complete & expires_at.
+// The expired records are ignored, and removed by Sweep.
+type postgresStore struct {
+	db    *sql.DB
+	table string
+	now   func() time.Time
+}
+
+// NewPostgresStore expects the table to be created already, e.g. by the schema migrations of the app
+func NewPostgresStore(db *sql.DB, table string) *postgresStore { //nolint:revive // it is ok to return unexported type in this case, ensures controlled access
+	return &postgresStore{
+		db:    db,
+		table: table,
+		now:   time.Now,
+	}
+}
+
+// Claim inserts the record if there's none for the key, or replaces it if it is expired. If a live record
+// exists, nothing is inserted and the existing one is returned.
+func (ps *postgresStore) Claim(ctx context.Context, key string, record *Record, ttl time.Duration) (*Record, error) {
+	for range postgresClaimAttempts {
+		existing, err := ps.claim(ctx, key, record, ttl)
+		if !errors.Is(err, sql.ErrNoRows) {
+			return existing, err
+		}
+	}
+
+	return nil, errors.Internalf("could not claim the idempotency key in %d attempts", postgresClaimAttempts)
+}
+
+// claim returns sql.ErrNoRows if the key could not be claimed, but the live record is gone meanwhile
+func (ps *postgresStore) claim(ctx context.Context, key string, record *Record, ttl time.Duration) (*Record, error) {
+	now := ps.now().UTC()
+	claimed := ""
+	err := ps.db.QueryRowContext(
+		ctx,
+		`INSERT INTO `+ps.table+` (key, fingerprint, response, complete, expires_at) VALUES ($1, $2, $3, $4, $5) `+
+			`ON CONFLICT (key) DO UPDATE SET fingerprint = EXCLUDED.fingerprint, response = EXCLUDED.response, `+
+			`complete = EXCLUDED.complete, expires_at = EXCLUDED.expires_at WHERE `+ps.table+`.expires_at <= $6 `+
+			`RETURNING key`,
+		key, record.Fingerprint, nullableJSON(record.Response), record.Complete, now.Add(ttl), now,
+	).Scan(&claimed)
+	if err == nil {
+		return nil, nil //nolint:nilnil // nil record means the key is claimed
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Wrap(err, "could not claim the idempotency key")
+	}
+
+	existing := &Record{}
+	response := []byte(nil)
+	err = ps.db.QueryRowContext(
+		ctx,
+		`SELECT fingerprint, response, complete FROM `+ps.table+` WHERE key = $1 AND expires_at > $2`,
+		key, now,
+	).Scan(&existing.Fingerprint, &response, &existing.Complete)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, sql.ErrNoRows
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get the idempotency record")
+	}
+	existing.Response = response
+
+	return existing, nil
+}
+
+func (ps *postgresStore) Save(ctx context.Context, key string, record *Record, ttl time.Duration) error {
+	_, err := ps.db.ExecContext(
+		ctx,
+		`INSERT INTO `+ps.table+` (key, fingerprint, response, complete, expires_at) VALUES ($1, $2, $3, $4, $5) `+
+			`ON CONFLICT (key) DO UPDATE SET fingerprint = EXCLUDED.fingerprint, response = EXCLUDED.response, `+
+			`complete = EXCLUDED.complete, expires_at = EXCLUDED.expires_at`,
+		key, record.Fingerprint, nullableJSON(record.Response), record.Complete, ps.now().UTC().Add(ttl),
+	)
+	if err != nil {
+		return errors.Wrap(err, "could not store the idempotency record")
+	}
+
+	return nil
+}
+
+func (ps *postgresStore) Release(ctx context.Context, key string) error {
+	_, err := ps.db.ExecContext(ctx, `DELETE FROM `+ps.table+` WHERE key = $1`, key)
+	if err != nil {
+		return errors.Wrap(err, "could not release the idempotency key")
+	}
+
+	return nil
+}
+
+// RemoveExpired deletes the expired records batch by batch, and returns the number of records deleted.
+// The expired records are ignored anyway, they're removed only to keep the table from growing.
+func (ps *postgresStore) RemoveExpired(ctx context.Context) (int64, error) {
+	total := int64(0)
+	for {
+		result, err := ps.db.ExecContext(
+			ctx,
+			`DELETE FROM `+ps.table+` WHERE key IN (SELECT key FROM `+ps.table+` WHERE expires_at <= $1 LIMIT $2)`,
+			ps.now().UTC(), postgresExpiredBatch,
+		)
+		if err != nil {
+			return total, errors.Wrap(err, "could not remove the expired idempotency records")
+		}
+
+		removed, _ := result.RowsAffected()
+		total += removed
+		if removed < postgresExpiredBatch {
+			return total, nil
+		}
+	}
+}
+
+// Sweep removes the expired records on every interval, until the context is done. It is a blocking call.
+func (ps *postgresStore) Sweep(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		_, err := ps.RemoveExpired(ctx)
+		if err != nil {
+			logger.ErrWithStacktrace(err)
+		}
+	}
+}
+
+// nullableJSON returns nil for an empty response, since an empty string is not valid JSONB
+func nullableJSON(payload []byte) any {
+	if len(payload) == 0 {
+		return nil
+	}
+	return payload
+}
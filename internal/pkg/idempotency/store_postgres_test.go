@@ -0,0 +1,108 @@
+package idempotency
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresStore(t *testing.T) {
+	requirer := require.New(t)
+	asserter := assert.New(t)
+	ctx := t.Context()
+
+	db, mock, err := sqlmock.New()
+	requirer.NoError(err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	now := time.Date(2024, 10, 1, 10, 0, 0, 0, time.UTC)
+	store := NewPostgresStore(db, "item_idempotency")
+	store.now = func() time.Time {
+		return now
+	}
+
+	t.Run("keys are claimed unless a live record exists", func(_ *testing.T) {
+		mock.ExpectQuery("INSERT INTO item_idempotency").
+			WithArgs("acme:create:key-1", "f1", nil, false, now.Add(time.Hour), now).
+			WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("acme:create:key-1"))
+
+		existing, cerr := store.Claim(ctx, "acme:create:key-1", &Record{Fingerprint: "f1"}, time.Hour)
+		requirer.NoError(cerr)
+		asserter.Nil(existing)
+		requirer.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("the live record is returned for a claimed key", func(_ *testing.T) {
+		mock.ExpectQuery("INSERT INTO item_idempotency").WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery("SELECT fingerprint, response, complete FROM item_idempotency").
+			WithArgs("acme:create:key-1", now).
+			WillReturnRows(sqlmock.NewRows([]string{"fingerprint", "response", "complete"}).
+				AddRow("f1", []byte(`{"id":1}`), true))
+
+		existing, cerr := store.Claim(ctx, "acme:create:key-1", &Record{Fingerprint: "f2"}, time.Hour)
+		requirer.NoError(cerr)
+		asserter.Equal(&Record{Fingerprint: "f1", Response: []byte(`{"id":1}`), Complete: true}, existing)
+		requirer.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("the key is claimed again if the live record is gone meanwhile", func(_ *testing.T) {
+		mock.ExpectQuery("INSERT INTO item_idempotency").WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery("SELECT fingerprint").WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery("INSERT INTO item_idempotency").
+			WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("acme:create:key-1"))
+
+		existing, cerr := store.Claim(ctx, "acme:create:key-1", &Record{Fingerprint: "f1"}, time.Hour)
+		requirer.NoError(cerr)
+		asserter.Nil(existing)
+		requirer.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("claims are attempted a limited number of times", func(_ *testing.T) {
+		for range postgresClaimAttempts {
+			mock.ExpectQuery("INSERT INTO item_idempotency").WillReturnError(sql.ErrNoRows)
+			mock.ExpectQuery("SELECT fingerprint").WillReturnError(sql.ErrNoRows)
+		}
+
+		_, cerr := store.Claim(ctx, "acme:create:key-1", &Record{Fingerprint: "f1"}, time.Hour)
+		asserter.Error(cerr)
+		asserter.NotErrorIs(cerr, sql.ErrNoRows)
+		requirer.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("expired records are removed batch by batch", func(_ *testing.T) {
+		mock.ExpectExec("DELETE FROM item_idempotency WHERE key IN").
+			WithArgs(now, postgresExpiredBatch).
+			WillReturnResult(sqlmock.NewResult(0, postgresExpiredBatch))
+		mock.ExpectExec("DELETE FROM item_idempotency WHERE key IN").
+			WithArgs(now, postgresExpiredBatch).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+
+		removed, rerr := store.RemoveExpired(ctx)
+		requirer.NoError(rerr)
+		asserter.Equal(int64(postgresExpiredBatch+3), removed)
+		requirer.NoError(mock.ExpectationsWereMet())
+	})
+
+	t.Run("records are saved and released", func(_ *testing.T) {
+		mock.ExpectExec("INSERT INTO item_idempotency").
+			WithArgs("acme:create:key-1", "f1", []byte(`{"id":1}`), true, now.Add(time.Hour)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("DELETE FROM item_idempotency WHERE key = ").
+			WithArgs("acme:create:key-1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		requirer.NoError(store.Save(ctx, "acme:create:key-1", &Record{
+			Fingerprint: "f1",
+			Response:    []byte(`{"id":1}`),
+			Complete:    true,
+		}, time.Hour))
+		requirer.NoError(store.Release(ctx, "acme:create:key-1"))
+		requirer.NoError(mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,79 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/naughtygopher/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisStore keeps the records as JSON, they're shared by all the instances of the app
+type redisStore struct {
+	client redis.UniversalClient
+	// keyPrefix is prepended to the idempotency keys, to avoid conflicts with other keys of the Redis database
+	keyPrefix string
+}
+
+func NewRedisStore(client redis.UniversalClient, keyPrefix string) *redisStore { //nolint:revive // it is ok to return unexported type in this case, ensures controlled access
+	return &redisStore{
+		client:    client,
+		keyPrefix: keyPrefix,
+	}
+}
+
+func (rs *redisStore) Claim(ctx context.Context, key string, record *Record, ttl time.Duration) (*Record, error) {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return nil, errors.Wrap(err, "json marshal failed")
+	}
+
+	claimed, err := rs.client.SetNX(ctx, rs.keyPrefix+key, payload, ttl).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not claim the idempotency key")
+	}
+	if claimed {
+		return nil, nil //nolint:nilnil // nil record means the key is claimed
+	}
+
+	existing, err := rs.client.Get(ctx, rs.keyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// the existing record expired or was released meanwhile
+		return rs.Claim(ctx, key, record, ttl)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get the idempotency record")
+	}
+
+	stored := new(Record)
+	err = json.Unmarshal(existing, stored)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid idempotency record %s", key)
+	}
+
+	return stored, nil
+}
+
+func (rs *redisStore) Save(ctx context.Context, key string, record *Record, ttl time.Duration) error {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "json marshal failed")
+	}
+
+	err = rs.client.Set(ctx, rs.keyPrefix+key, payload, ttl).Err()
+	if err != nil {
+		return errors.Wrap(err, "could not store the idempotency record")
+	}
+
+	return nil
+}
+
+func (rs *redisStore) Release(ctx context.Context, key string) error {
+	err := rs.client.Del(ctx, rs.keyPrefix+key).Err()
+	if err != nil {
+		return errors.Wrap(err, "could not release the idempotency key")
+	}
+
+	return nil
+}
@@ -47,7 +47,8 @@ type Config struct {
 	EnableTLSDialer  bool
 }
 
-type kafkaHandler func(ctx context.Context, payload []byte) error
+// kafkaHandler receives the whole record, since the headers may be required to decode the value
+type kafkaHandler func(ctx context.Context, record *kgo.Record) error
 type Kafka struct {
 	cfg               *Config
 	client            *kgo.Client
@@ -83,6 +84,12 @@ func (kfk *Kafka) Shutdown(ctx context.Context) error {
 
 	return nil
 }
+
+// PauseFetching stops fetching records of all the consumed topics
+func (kfk *Kafka) PauseFetching() {
+	_ = kfk.client.PauseFetchTopics(kfk.cfg.Topics...)
+}
+
 func (kfk *Kafka) Close() {
 	kfk.client.Close()
 }
@@ -140,7 +147,7 @@ func (kfk *Kafka) HandleTopic(
 		span.End()
 	}(time.Now())
 
-	err := fn(childCtx, record.Value)
+	err := fn(childCtx, record)
 	if err != nil {
 		log.Println(err)
 		return
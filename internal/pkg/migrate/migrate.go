@@ -0,0 +1,139 @@
+// Package migrate applies versioned SQL schema migrations, which are usually embedded in the binary.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/naughtygopher/errors"
+)
+
+var ErrInvalidMigration = errors.New("invalid migration")
+
+type Migration struct {
+	Version     int
+	Description string
+	filename    string
+}
+
+// Load returns all the migrations in the directory, in the order of their versions. The files
+// should be named <version>_<description>.sql, e.g. 0001_create_items.sql
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read migrations")
+	}
+
+	migrations := make([]Migration, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || path.Ext(name) != ".sql" {
+			continue
+		}
+
+		versionStr, description, _ := strings.Cut(strings.TrimSuffix(name, ".sql"), "_")
+		version, cerr := strconv.Atoi(versionStr)
+		if cerr != nil || version <= 0 {
+			return nil, errors.Wrapf(ErrInvalidMigration, ": version of %s", name)
+		}
+
+		migrations = append(migrations, Migration{
+			Version:     version,
+			Description: description,
+			filename:    path.Join(dir, name),
+		})
+	}
+
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return a.Version - b.Version
+	})
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, errors.Wrapf(ErrInvalidMigration, ": duplicate version %d", migrations[i].Version)
+		}
+	}
+
+	return migrations, nil
+}
+
+// Up applies all the migrations not applied yet, and returns the ones applied. Every migration is
+// applied in its own transaction, along with recording its version in the table. If migrations are
+// run concurrently, all but one fail on recording the same version.
+func Up(ctx context.Context, db *sql.DB, fsys fs.FS, dir string, table string) ([]Migration, error) {
+	migrations, err := Load(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.ExecContext(ctx, fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS %s (version BIGINT PRIMARY KEY, description TEXT NOT NULL, applied_at TIMESTAMPTZ NOT NULL DEFAULT now())`,
+		table,
+	))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create the migrations table")
+	}
+
+	current := 0
+	err = db.QueryRowContext(ctx, fmt.Sprintf(`SELECT COALESCE(MAX(version), 0) FROM %s`, table)).Scan(&current)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch the current schema version")
+	}
+
+	applied := make([]Migration, 0, len(migrations))
+	for _, migration := range migrations {
+		if migration.Version <= current {
+			continue
+		}
+
+		err = apply(ctx, db, fsys, table, migration)
+		if err != nil {
+			return applied, err
+		}
+		applied = append(applied, migration)
+	}
+
+	return applied, nil
+}
+
+func apply(ctx context.Context, db *sql.DB, fsys fs.FS, table string, migration Migration) error {
+	query, err := fs.ReadFile(fsys, migration.filename)
+	if err != nil {
+		return errors.Wrapf(err, "could not read migration %d", migration.Version)
+	}
+
+	txn, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
+
+	_, err = txn.ExecContext(ctx, string(query))
+	if err != nil {
+		return errors.Wrapf(err, "migration %d failed", migration.Version)
+	}
+
+	_, err = txn.ExecContext(
+		ctx,
+		fmt.Sprintf(`INSERT INTO %s (version, description) VALUES ($1, $2)`, table),
+		migration.Version,
+		migration.Description,
+	)
+	if err != nil {
+		return errors.Wrapf(err, "could not record migration %d", migration.Version)
+	}
+
+	err = txn.Commit()
+	if err != nil {
+		return errors.Wrapf(err, "could not commit migration %d", migration.Version)
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+// Package resilience provides building blocks for calling unreliable dependencies, i.e. retries
+// with exponential backoff and a circuit breaker.
+package resilience
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/naughtygopher/errors"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Backoff computes exponentially increasing delays between retries, e.g. with Initial 100ms and
+// Multiplier 2, the delays are 100ms, 200ms, 400ms... capped at Max.
+type Backoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+	// Jitter is the fraction [0, 1] of the delay randomized, to avoid all the clients retrying at
+	// the same time. e.g. with Jitter 0.2, a delay of 100ms would be anywhere between 80ms & 120ms.
+	Jitter float64
+}
+
+// Delay returns the delay before the nth retry, starting at 1
+func (bo *Backoff) Delay(retry int) time.Duration {
+	delay := float64(bo.Initial)
+	multiplier := max(bo.Multiplier, 1)
+	for i := 1; i < retry && (bo.Max <= 0 || delay < float64(bo.Max)); i++ {
+		delay *= multiplier
+	}
+	if bo.Max > 0 {
+		delay = min(delay, float64(bo.Max))
+	}
+
+	if jitter := min(bo.Jitter, 1); jitter > 0 {
+		// rand.Float64 is [0, 1), so the spread is [-jitter, +jitter)
+		delay += delay * jitter * (rand.Float64()*2 - 1) //nolint:gosec // G404: Non-crypto usage, just for jitter
+	}
+
+	return time.Duration(delay)
+}
+
+// Wait blocks for the delay before the nth retry, or until the context is done.
+func (bo *Backoff) Wait(ctx context.Context, retry int) error {
+	timer := time.NewTimer(bo.Delay(retry))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "backoff interrupted")
+	}
+}
+
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerHalfOpen
+	BreakerOpen
+)
+
+func (bs BreakerState) String() string {
+	switch bs {
+	case BreakerClosed:
+		return "closed"
+	case BreakerHalfOpen:
+		return "half-open"
+	case BreakerOpen:
+		return "open"
+	}
+	return "unknown"
+}
+
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures after which the breaker opens
+	FailureThreshold int
+	// OpenTimeout is the duration the breaker stays open, before allowing a trial call (half-open)
+	OpenTimeout time.Duration
+	// OnStateChange if not nil, is called synchronously on every change of state
+	OnStateChange func(from, to BreakerState)
+}
+
+// Breaker is a circuit breaker, it rejects calls for a while after consecutive failures, so that
+// an unavailable dependency is not overwhelmed and the callers fail fast. After OpenTimeout,
+// a single trial call is allowed, and the breaker closes if it succeeds or opens again otherwise.
+type Breaker struct {
+	cfg BreakerConfig
+	now func() time.Time
+
+	mutex    *sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	// trialing is true while the trial call of the half-open state is in progress
+	trialing bool
+}
+
+func NewBreaker(cfg *BreakerConfig) *Breaker {
+	const (
+		defaultFailureThreshold = 5
+		defaultOpenTimeout      = time.Second * 30
+	)
+
+	brk := &Breaker{
+		cfg:   *cfg,
+		now:   time.Now,
+		mutex: &sync.Mutex{},
+		state: BreakerClosed,
+	}
+	if brk.cfg.FailureThreshold <= 0 {
+		brk.cfg.FailureThreshold = defaultFailureThreshold
+	}
+	if brk.cfg.OpenTimeout <= 0 {
+		brk.cfg.OpenTimeout = defaultOpenTimeout
+	}
+
+	return brk
+}
+
+// Allow returns ErrCircuitOpen if the call should not be made. Every allowed call should be
+// followed by either Success or Failure.
+func (brk *Breaker) Allow() error {
+	brk.mutex.Lock()
+	defer brk.mutex.Unlock()
+
+	switch brk.state {
+	case BreakerClosed:
+		return nil
+	case BreakerOpen:
+		if brk.now().Sub(brk.openedAt) < brk.cfg.OpenTimeout {
+			return ErrCircuitOpen
+		}
+		brk.setState(BreakerHalfOpen)
+	case BreakerHalfOpen:
+	}
+
+	if brk.trialing {
+		return ErrCircuitOpen
+	}
+	brk.trialing = true
+
+	return nil
+}
+
+func (brk *Breaker) Success() {
+	brk.mutex.Lock()
+	defer brk.mutex.Unlock()
+
+	brk.failures = 0
+	brk.trialing = false
+	brk.setState(BreakerClosed)
+}
+
+func (brk *Breaker) Failure() {
+	brk.mutex.Lock()
+	defer brk.mutex.Unlock()
+
+	brk.failures++
+	if brk.state == BreakerHalfOpen || brk.failures >= brk.cfg.FailureThreshold {
+		brk.trialing = false
+		brk.openedAt = brk.now()
+		brk.setState(BreakerOpen)
+	}
+}
+
+func (brk *Breaker) State() BreakerState {
+	brk.mutex.Lock()
+	defer brk.mutex.Unlock()
+	return brk.state
+}
+
+// setState should only be called while holding the lock
+func (brk *Breaker) setState(state BreakerState) {
+	if brk.state == state {
+		return
+	}
+
+	from := brk.state
+	brk.state = state
+	if brk.cfg.OnStateChange != nil {
+		brk.cfg.OnStateChange(from, state)
+	}
+}
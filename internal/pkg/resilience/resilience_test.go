@@ -0,0 +1,78 @@
+package resilience
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoff(t *testing.T) {
+	asserter := assert.New(t)
+
+	bo := Backoff{Initial: time.Millisecond * 100, Max: time.Second, Multiplier: 2}
+	asserter.Equal(time.Millisecond*100, bo.Delay(1))
+	asserter.Equal(time.Millisecond*200, bo.Delay(2))
+	asserter.Equal(time.Millisecond*800, bo.Delay(4))
+	asserter.Equal(time.Second, bo.Delay(5))
+	asserter.Equal(time.Second, bo.Delay(100))
+
+	bo.Jitter = 0.5
+	for range 100 {
+		delay := bo.Delay(2)
+		asserter.GreaterOrEqual(delay, time.Millisecond*100)
+		asserter.LessOrEqual(delay, time.Millisecond*300)
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	asserter := assert.New(t)
+	requirer := require.New(t)
+
+	now := time.Now()
+	transitions := []string{}
+	brk := NewBreaker(&BreakerConfig{
+		FailureThreshold: 2,
+		OpenTimeout:      time.Minute,
+		OnStateChange: func(from, to BreakerState) {
+			transitions = append(transitions, from.String()+"->"+to.String())
+		},
+	})
+	brk.now = func() time.Time { return now }
+
+	t.Run("opens after consecutive failures", func(_ *testing.T) {
+		requirer.NoError(brk.Allow())
+		brk.Failure()
+		asserter.Equal(BreakerClosed, brk.State())
+
+		requirer.NoError(brk.Allow())
+		brk.Failure()
+		asserter.Equal(BreakerOpen, brk.State())
+		requirer.ErrorIs(brk.Allow(), ErrCircuitOpen)
+	})
+
+	t.Run("allows a single trial after the open timeout", func(_ *testing.T) {
+		now = now.Add(time.Minute)
+		requirer.NoError(brk.Allow())
+		asserter.Equal(BreakerHalfOpen, brk.State())
+		requirer.ErrorIs(brk.Allow(), ErrCircuitOpen)
+
+		brk.Failure()
+		asserter.Equal(BreakerOpen, brk.State())
+		requirer.ErrorIs(brk.Allow(), ErrCircuitOpen)
+	})
+
+	t.Run("closes after a successful trial", func(_ *testing.T) {
+		now = now.Add(time.Minute)
+		requirer.NoError(brk.Allow())
+		brk.Success()
+		asserter.Equal(BreakerClosed, brk.State())
+		requirer.NoError(brk.Allow())
+	})
+
+	asserter.Equal(
+		[]string{"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed"},
+		transitions,
+	)
+}